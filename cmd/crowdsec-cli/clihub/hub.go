@@ -15,6 +15,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/hubcheck"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/reload"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
 	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
@@ -56,6 +57,10 @@ cscli hub upgrade`,
 	cmd.AddCommand(cli.newUpdateCmd())
 	cmd.AddCommand(cli.newUpgradeCmd())
 	cmd.AddCommand(cli.newTypesCmd())
+	cmd.AddCommand(cli.newApplyCmd())
+	cmd.AddCommand(cli.newDiffCmd())
+	cmd.AddCommand(cli.newExportCmd())
+	cmd.AddCommand(cli.newRollbackCmd())
 
 	return cmd
 }
@@ -209,7 +214,7 @@ Use --full to list every installed item individually, instead of summarizing col
 	return cmd
 }
 
-func (cli *cliHub) update(ctx context.Context, withContent bool) error {
+func (cli *cliHub) update(ctx context.Context, withContent bool, allowUnsigned bool) error {
 	local := cli.cfg().Hub
 	// don't use require.Hub because if there is no index file, it would fail
 	hub, err := cwhub.NewHub(local, log.StandardLogger())
@@ -222,7 +227,13 @@ func (cli *cliHub) update(ctx context.Context, withContent bool) error {
 		return err
 	}
 
+	indexProvider.SetAllowUnsigned(allowUnsigned)
+
 	updated, err := hub.Update(ctx, indexProvider, withContent)
+	if errors.Is(err, cwhub.ErrSignatureMissing) || errors.Is(err, cwhub.ErrSignatureInvalid) {
+		return fmt.Errorf("failed to update hub: %w. Use --allow-unsigned to accept it anyway", err)
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to update hub: %w", err)
 	}
@@ -243,31 +254,39 @@ func (cli *cliHub) update(ctx context.Context, withContent bool) error {
 }
 
 func (cli *cliHub) newUpdateCmd() *cobra.Command {
-	withContent := false
+	var (
+		withContent   bool
+		allowUnsigned bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Download the latest index (catalog of available configurations)",
 		Long: `
 Fetches the .index.json file from the hub, containing the list of available configs.
+If hub.public_keys is set in the configuration, the index must have a valid signature.
 `,
 		Example: `# Download the last version of the index file.
 cscli hub update
 
 # Download a 4x bigger version with all item contents (effectively pre-caching item downloads, but not data files).
-cscli hub update --with-content`,
+cscli hub update --with-content
+
+# Accept an index that is not signed, or is signed by an unknown key.
+cscli hub update --allow-unsigned`,
 		Args:              args.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if cmd.Flags().Changed("with-content") {
-				return cli.update(cmd.Context(), withContent)
+				return cli.update(cmd.Context(), withContent, allowUnsigned)
 			}
-			return cli.update(cmd.Context(), cli.cfg().Cscli.HubWithContent)
+			return cli.update(cmd.Context(), cli.cfg().Cscli.HubWithContent, allowUnsigned)
 		},
 	}
 
 	flags := cmd.Flags()
 	flags.BoolVar(&withContent, "with-content", false, "Download index with embedded item content")
+	flags.BoolVar(&allowUnsigned, "allow-unsigned", false, "Accept an index without a valid signature (insecure)")
 
 	return cmd
 }
@@ -302,6 +321,8 @@ func (cli *cliHub) upgrade(ctx context.Context, interactive bool, dryRun bool, f
 	showPlan := (log.StandardLogger().Level >= log.InfoLevel)
 	verbosePlan := (cfg.Cscli.Output == "raw")
 
+	plan.Validate = hubcheck.Validator(cfg)
+
 	err = plan.Execute(ctx, interactive, dryRun, showPlan, verbosePlan)
 	if err != nil {
 		if !errors.Is(err, hubops.ErrUserCanceled) {
@@ -356,6 +377,48 @@ cscli hub upgrade -i`,
 	return cmd
 }
 
+func (cli *cliHub) rollback() error {
+	hub, err := require.Hub(cli.cfg(), log.StandardLogger())
+	if err != nil {
+		return err
+	}
+
+	restored, err := hubops.Rollback(hub)
+
+	for _, path := range restored {
+		fmt.Fprintln(os.Stdout, "restored "+path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if msg := reload.UserMessage(); msg != "" && len(restored) > 0 {
+		fmt.Fprintln(os.Stdout, "\n"+msg)
+	}
+
+	return nil
+}
+
+func (cli *cliHub) newRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Undo the last hub operation",
+		Long: `
+Restore the items and the install links as they were before the last install, upgrade or removal.
+Data files are not restored. A rollback can't be undone, and only the last operation can be rolled back.
+`,
+		Example:           `cscli hub rollback`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cli.rollback()
+		},
+	}
+
+	return cmd
+}
+
 func (cli *cliHub) types() error {
 	switch cli.cfg().Cscli.Output {
 	case "human":
@@ -0,0 +1,254 @@
+package clihub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/cstable"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/hubcheck"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/reload"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
+	"github.com/crowdsecurity/crowdsec/pkg/hubops"
+)
+
+func (cli *cliHub) planManifest(ctx context.Context, manifestPath string, force bool) (*hubops.ActionPlan, []hubops.Change, error) {
+	cfg := cli.cfg()
+
+	manifest, err := hubops.LoadManifest(manifestPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hub, err := require.Hub(cfg, log.StandardLogger())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contentProvider, err := require.HubDownloader(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hubops.PlanManifest(hub, manifest, contentProvider, force)
+}
+
+func (cli *cliHub) apply(ctx context.Context, manifestPath string, interactive bool, dryRun bool, force bool) error {
+	cfg := cli.cfg()
+
+	plan, changes, err := cli.planManifest(ctx, manifestPath, force)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		if c.Op == hubops.ChangeTaint && !force {
+			log.Warnf("%s is tainted, use '--force' to overwrite or remove it", c.Item.FQName())
+		}
+	}
+
+	showPlan := (log.StandardLogger().Level >= log.InfoLevel)
+	verbosePlan := (cfg.Cscli.Output == "raw")
+
+	plan.Validate = hubcheck.Validator(cfg)
+
+	err = plan.Execute(ctx, interactive, dryRun, showPlan, verbosePlan)
+	if err != nil {
+		if !errors.Is(err, hubops.ErrUserCanceled) {
+			return err
+		}
+		// not a real error, and we'll want to print the reload message anyway
+		fmt.Fprintln(os.Stdout, err.Error())
+	}
+
+	if msg := reload.UserMessage(); msg != "" && plan.ReloadNeeded {
+		fmt.Fprintln(os.Stdout, "\n"+msg)
+	}
+
+	return nil
+}
+
+func (cli *cliHub) newApplyCmd() *cobra.Command {
+	var (
+		interactive bool
+		dryRun      bool
+		force       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply <manifest>",
+		Short: "Install, upgrade and remove items to match a manifest",
+		Long: `
+Bring the hub to the state described by a manifest file: the listed items and their dependencies
+are installed or upgraded, the other items are removed. Local items are never changed.
+
+A manifest lists the items per type, with an optional version (to prevent the upgrade of an installed item)
+and a local flag for the local items and the hub items with local changes:
+
+collections:
+  - name: crowdsecurity/linux
+  - name: crowdsecurity/nginx
+    version: "0.2"
+parsers:
+  - name: me/custom-logs
+    local: true
+`,
+		Example: `# Apply a manifest created with 'cscli hub export'.
+cscli hub apply /etc/crowdsec/hub-manifest.yaml
+
+# Show what would be done.
+cscli hub apply /etc/crowdsec/hub-manifest.yaml --dry-run
+
+# Overwrite or remove tainted items as well.
+cscli hub apply /etc/crowdsec/hub-manifest.yaml --force`,
+		Args:              args.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.apply(cmd.Context(), args[0], interactive, dryRun, force)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&interactive, "interactive", "i", false, "Ask for confirmation before proceeding")
+	flags.BoolVar(&dryRun, "dry-run", false, "Don't install or remove anything; print the execution plan")
+	flags.BoolVar(&force, "force", false, "Overwrite or remove tainted items")
+	cmd.MarkFlagsMutuallyExclusive("interactive", "dry-run")
+
+	return cmd
+}
+
+// changeJSON is the representation of a change for json output.
+type changeJSON struct {
+	Op   string `json:"op"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+func (cli *cliHub) diff(ctx context.Context, out io.Writer, manifestPath string) error {
+	cfg := cli.cfg()
+
+	// without force, the tainted items are reported but not planned
+	_, changes, err := cli.planManifest(ctx, manifestPath, false)
+	if err != nil {
+		return err
+	}
+
+	switch cfg.Cscli.Output {
+	case "human":
+		if len(changes) == 0 {
+			fmt.Fprintln(out, "The hub is in sync with the manifest.")
+			return nil
+		}
+
+		t := cstable.NewLight(out, cfg.Cscli.Color).Writer
+		t.AppendHeader(table.Row{"Change", "Item", "Current", "Target"})
+
+		for _, c := range changes {
+			t.AppendRow(table.Row{c.Op, c.Item.FQName(), c.From, c.To})
+		}
+
+		fmt.Fprintln(out, t.Render())
+	case "json":
+		ret := make([]changeJSON, 0, len(changes))
+
+		for _, c := range changes {
+			ret = append(ret, changeJSON{Op: c.Op, Type: c.Item.Type, Name: c.Item.Name, From: c.From, To: c.To})
+		}
+
+		x, err := json.MarshalIndent(ret, "", " ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize changes: %w", err)
+		}
+
+		fmt.Fprintln(out, string(x))
+	case "raw":
+		for _, c := range changes {
+			fmt.Fprintf(out, "%s %s\n", c.Op, c.Item.FQName())
+		}
+	}
+
+	return nil
+}
+
+func (cli *cliHub) newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <manifest>",
+		Short: "Show the changes required to match a manifest",
+		Long: `
+Compare the installed items with a manifest, and list what 'cscli hub apply' would install, upgrade or remove.
+Tainted items (hub items with local changes) are overwritten or removed only with 'cscli hub apply --force'.
+`,
+		Example: `cscli hub diff /etc/crowdsec/hub-manifest.yaml
+cscli hub diff /etc/crowdsec/hub-manifest.yaml -o json`,
+		Args:              args.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.diff(cmd.Context(), os.Stdout, args[0])
+		},
+	}
+
+	return cmd
+}
+
+func (cli *cliHub) export(out io.Writer, outFile string, pinVersions bool) error {
+	hub, err := require.Hub(cli.cfg(), log.StandardLogger())
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(hubops.ExportManifest(hub, pinVersions))
+	if err != nil {
+		return fmt.Errorf("failed to serialize manifest: %w", err)
+	}
+
+	if outFile == "" {
+		_, err = out.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(outFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+func (cli *cliHub) newExportCmd() *cobra.Command {
+	var (
+		outFile     string
+		pinVersions bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the installed items to a manifest",
+		Long: `
+Write a manifest with the installed items, to be used with 'cscli hub apply'.
+The items that are installed as dependencies of a collection are not listed, unless they have local changes.
+`,
+		Example: `cscli hub export > hub-manifest.yaml
+cscli hub export --pin-versions --file /etc/crowdsec/hub-manifest.yaml`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cli.export(os.Stdout, outFile, pinVersions)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&outFile, "file", "f", "", "Write the manifest to this file instead of stdout")
+	flags.BoolVar(&pinVersions, "pin-versions", false, "Keep the installed version of each item")
+
+	return cmd
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/hubcheck"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/reload"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
 	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
@@ -87,6 +88,8 @@ func (cli *cliItem) install(ctx context.Context, args []string, interactive bool
 	showPlan := (log.StandardLogger().Level >= log.InfoLevel)
 	verbosePlan := (cfg.Cscli.Output == "raw")
 
+	plan.Validate = hubcheck.Validator(cfg)
+
 	err = plan.Execute(ctx, interactive, dryRun, showPlan, verbosePlan)
 	switch {
 	case errors.Is(err, hubops.ErrUserCanceled) && err != nil:
@@ -10,6 +10,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/hubcheck"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/reload"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
 	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
@@ -68,6 +69,8 @@ func (cli *cliItem) upgrade(ctx context.Context, args []string, interactive bool
 	showPlan := (log.StandardLogger().Level >= log.InfoLevel)
 	verbosePlan := (cfg.Cscli.Output == "raw")
 
+	plan.Validate = hubcheck.Validator(cfg)
+
 	err = plan.Execute(ctx, interactive, dryRun, showPlan, verbosePlan)
 	switch {
 	case errors.Is(err, hubops.ErrUserCanceled) && err != nil:
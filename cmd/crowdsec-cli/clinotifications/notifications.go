@@ -79,6 +79,7 @@ func (cli *cliNotifications) NewCommand() *cobra.Command {
 	cmd.AddCommand(cli.newInspectCmd())
 	cmd.AddCommand(cli.newReinjectCmd())
 	cmd.AddCommand(cli.newTestCmd())
+	cmd.AddCommand(cli.newOutboxCmd())
 
 	return cmd
 }
@@ -305,7 +306,7 @@ func (cli *cliNotifications) newTestCmd() *cobra.Command {
 			}
 
 			// Create a single profile with plugin name as notification name
-			return pluginBroker.Init(ctx, cfg.PluginConfig, []*csconfig.ProfileCfg{
+			return pluginBroker.Init(ctx, withoutOutbox(cfg.PluginConfig), []*csconfig.ProfileCfg{
 				{
 					Notifications: []string{
 						pcfg.Name,
@@ -435,7 +436,7 @@ cscli notifications reinject <alert_id> -a '{"remediation": true,"scenario":"not
 				}
 			}
 
-			err := pluginBroker.Init(ctx, cfg.PluginConfig, cfg.API.Server.Profiles, cfg.ConfigPaths)
+			err := pluginBroker.Init(ctx, withoutOutbox(cfg.PluginConfig), cfg.API.Server.Profiles, cfg.ConfigPaths)
 			if err != nil {
 				return fmt.Errorf("can't initialize plugins: %w", err)
 			}
@@ -0,0 +1,280 @@
+package clinotifications
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/cstable"
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/csplugin"
+)
+
+// withoutOutbox returns the plugin configuration for the notifications sent by cscli:
+// they are not retried, and cscli must not deliver the ones queued by the LAPI.
+func withoutOutbox(cfg *csconfig.PluginCfg) *csconfig.PluginCfg {
+	if cfg == nil {
+		return nil
+	}
+
+	ret := *cfg
+	ret.Outbox = nil
+
+	return &ret
+}
+
+func (cli *cliNotifications) outbox() (*csplugin.Outbox, error) {
+	cfg := cli.cfg()
+
+	if !cfg.PluginConfig.OutboxEnabled() {
+		return nil, errors.New("the notification outbox is not enabled (plugin_config.outbox in config.yaml)")
+	}
+
+	return csplugin.NewOutbox(cfg.PluginConfig.Outbox)
+}
+
+func (cli *cliNotifications) newOutboxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "outbox [action]",
+		Short:             "Manage the notifications that have not been delivered",
+		Long:              "List, replay or delete the notifications waiting in the outbox for a retry, or that have been given up on",
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(cli.newOutboxListCmd())
+	cmd.AddCommand(cli.newOutboxReplayCmd())
+	cmd.AddCommand(cli.newOutboxDeleteCmd())
+
+	return cmd
+}
+
+func formatOutboxTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func (cli *cliNotifications) outboxListHuman(out io.Writer, entries []*csplugin.OutboxEntry) {
+	t := cstable.NewLight(out, cli.cfg().Cscli.Color).Writer
+	t.AppendHeader(table.Row{"ID", "Notification", "Status", "Alerts", "Attempts", "Created at", "Next attempt", "Last error"})
+
+	for _, e := range entries {
+		t.AppendRow(table.Row{e.ID, e.Notification, e.Status, len(e.Alerts), e.Attempts, formatOutboxTime(e.CreatedAt), formatOutboxTime(e.NextAttempt), e.LastError})
+	}
+
+	fmt.Fprintln(out, t.Render())
+}
+
+func (*cliNotifications) outboxListCSV(out io.Writer, entries []*csplugin.OutboxEntry) error {
+	csvwriter := csv.NewWriter(out)
+
+	if err := csvwriter.Write([]string{"id", "notification", "status", "alerts", "attempts", "created_at", "next_attempt", "last_error"}); err != nil {
+		return fmt.Errorf("failed to write raw header: %w", err)
+	}
+
+	for _, e := range entries {
+		row := []string{
+			e.ID, e.Notification, string(e.Status), strconv.Itoa(len(e.Alerts)), strconv.Itoa(e.Attempts),
+			formatOutboxTime(e.CreatedAt), formatOutboxTime(e.NextAttempt), e.LastError,
+		}
+
+		if err := csvwriter.Write(row); err != nil {
+			return fmt.Errorf("failed to write raw: %w", err)
+		}
+	}
+
+	csvwriter.Flush()
+
+	return nil
+}
+
+func (cli *cliNotifications) newOutboxListCmd() *cobra.Command {
+	var failedOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the notifications in the outbox",
+		Example: `cscli notifications outbox list
+cscli notifications outbox list --failed`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			outbox, err := cli.outbox()
+			if err != nil {
+				return err
+			}
+
+			entries, err := outbox.List()
+			if err != nil {
+				return fmt.Errorf("unable to list notifications: %w", err)
+			}
+
+			if failedOnly {
+				filtered := entries[:0]
+
+				for _, e := range entries {
+					if e.Status != csplugin.DeliveryPending {
+						filtered = append(filtered, e)
+					}
+				}
+
+				entries = filtered
+			}
+
+			out := color.Output
+
+			switch cli.cfg().Cscli.Output {
+			case "human":
+				cli.outboxListHuman(out, entries)
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+
+				if err := enc.Encode(entries); err != nil {
+					return errors.New("failed to serialize")
+				}
+			case "raw":
+				return cli.outboxListCSV(out, entries)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&failedOnly, "failed", false, "only list the failed and dead notifications")
+
+	return cmd
+}
+
+// selectOutboxEntries returns the entries with the given IDs, or all the failed and dead ones
+func selectOutboxEntries(outbox *csplugin.Outbox, ids []string, all bool) ([]*csplugin.OutboxEntry, error) {
+	if all == (len(ids) > 0) {
+		return nil, errors.New("provide notification IDs or --all")
+	}
+
+	if !all {
+		entries := make([]*csplugin.OutboxEntry, 0, len(ids))
+
+		for _, id := range ids {
+			entry, err := outbox.Get(id)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, entry)
+		}
+
+		return entries, nil
+	}
+
+	entries, err := outbox.List()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list notifications: %w", err)
+	}
+
+	ret := []*csplugin.OutboxEntry{}
+
+	for _, e := range entries {
+		if e.Status != csplugin.DeliveryPending {
+			ret = append(ret, e)
+		}
+	}
+
+	return ret, nil
+}
+
+func (cli *cliNotifications) newOutboxReplayCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "replay [id]...",
+		Short: "Deliver notifications again",
+		Long: `Queue failed or dead notifications for an immediate delivery.
+They are sent by the Local API, which must be running.`,
+		Example: `cscli notifications outbox replay 0f0d5c2e-5a43-4b8e-9a3c-3c1d4ac8e1f5
+cscli notifications outbox replay --all`,
+		DisableAutoGenTag: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			outbox, err := cli.outbox()
+			if err != nil {
+				return err
+			}
+
+			entries, err := selectOutboxEntries(outbox, args, all)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now().UTC()
+
+			for _, entry := range entries {
+				entry.Replay(now)
+
+				if err := outbox.Save(entry); err != nil {
+					return fmt.Errorf("unable to replay notification %s: %w", entry.ID, err)
+				}
+			}
+
+			fmt.Fprintf(color.Output, "%d notification(s) queued for delivery\n", len(entries))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "replay all the failed and dead notifications")
+
+	return cmd
+}
+
+func (cli *cliNotifications) newOutboxDeleteCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "delete [id]...",
+		Short: "Discard notifications",
+		Example: `cscli notifications outbox delete 0f0d5c2e-5a43-4b8e-9a3c-3c1d4ac8e1f5
+cscli notifications outbox delete --all`,
+		Aliases:           []string{"remove"},
+		DisableAutoGenTag: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			outbox, err := cli.outbox()
+			if err != nil {
+				return err
+			}
+
+			entries, err := selectOutboxEntries(outbox, args, all)
+			if err != nil {
+				return err
+			}
+
+			for _, entry := range entries {
+				if err := outbox.Delete(entry.ID); err != nil {
+					return fmt.Errorf("unable to delete notification %s: %w", entry.ID, err)
+				}
+			}
+
+			fmt.Fprintf(color.Output, "%d notification(s) deleted\n", len(entries))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "delete all the failed and dead notifications")
+
+	return cmd
+}
@@ -8,8 +8,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
-	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clisetup/setup"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/hubcheck"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/reload"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
 	"github.com/crowdsecurity/crowdsec/pkg/hubops"
@@ -115,6 +116,8 @@ func (cli *cliSetup) install(ctx context.Context, interactive bool, dryRun bool,
 		}
 	}
 
+	plan.Validate = hubcheck.Validator(cfg)
+
 	err = plan.Execute(ctx, interactive, dryRun, showPlan, verbosePlan)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,68 @@
+// Package hubcheck validates the hub configuration after it has been modified by cscli,
+// by loading the parsers and scenarios like the security engine does.
+package hubcheck
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/leakybucket"
+	"github.com/crowdsecurity/crowdsec/pkg/parser"
+)
+
+// Validator returns the function to validate the configuration after a hub plan,
+// or nil if cscli.hub_validate is not enabled.
+func Validator(cfg *csconfig.Config) func(context.Context) error {
+	if !cfg.Cscli.HubValidate {
+		return nil
+	}
+
+	return func(_ context.Context) error {
+		return Validate(cfg)
+	}
+}
+
+// Validate loads the installed parsers and scenarios from disk.
+func Validate(cfg *csconfig.Config) error {
+	// the loaders are quite verbose
+	level := log.GetLevel()
+	log.SetLevel(log.WarnLevel)
+
+	defer log.SetLevel(level)
+
+	// read the state from disk, not what the action plan has updated in memory
+	hub, err := cwhub.NewHub(cfg.Hub, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := hub.Load(); err != nil {
+		return err
+	}
+
+	if err := exprhelpers.Init(nil); err != nil {
+		return fmt.Errorf("failed to init expr helpers: %w", err)
+	}
+
+	if _, err := parser.LoadParsers(cfg, hub); err != nil {
+		return fmt.Errorf("unable to load parsers: %w", err)
+	}
+
+	crowdsecCfg := cfg.Crowdsec
+	if crowdsecCfg == nil {
+		crowdsecCfg = &csconfig.CrowdsecServiceCfg{}
+	}
+
+	scenarios := hub.GetInstalledByType(cwhub.SCENARIOS, false)
+
+	if _, _, err := leakybucket.LoadBuckets(crowdsecCfg, hub, scenarios, false); err != nil {
+		return fmt.Errorf("unable to load scenarios: %w", err)
+	}
+
+	return nil
+}
@@ -1,10 +1,12 @@
 package require
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -93,20 +95,105 @@ func DBClient(ctx context.Context, dbcfg *csconfig.DatabaseCfg) (*database.Clien
 	return db, nil
 }
 
-func HubDownloader(ctx context.Context, c *csconfig.Config) (*cwhub.Downloader, error) {
-	// set branch in config, and log if necessary
-	branch, err := HubBranch(ctx, c)
-	if err != nil {
-		return nil, err
+func HubDownloader(ctx context.Context, c *csconfig.Config) (*cwhub.Sources, error) {
+	var sourceCfgs []csconfig.HubSourceCfg
+
+	if c.Hub != nil {
+		sourceCfgs = c.Hub.Sources
+	}
+
+	if len(sourceCfgs) == 0 {
+		sourceCfgs = []csconfig.HubSourceCfg{{Name: "crowdsec", Type: cwhub.SourceHub}}
 	}
 
-	urlTemplate := HubURLTemplate(c)
-	remote := &cwhub.Downloader{
-		Branch:      branch,
-		URLTemplate: urlTemplate,
+	sources := make([]cwhub.HubSource, 0, len(sourceCfgs))
+
+	for _, sc := range sourceCfgs {
+		provider, err := hubSource(ctx, c, sc)
+		if err != nil {
+			return nil, fmt.Errorf("hub source '%s': %w", sc.Name, err)
+		}
+
+		sources = append(sources, cwhub.HubSource{Name: sc.Name, Provider: provider})
 	}
 
-	return remote, nil
+	return cwhub.NewSources(sources...)
+}
+
+// hubSource returns the provider for a hub source defined in the configuration.
+func hubSource(ctx context.Context, c *csconfig.Config, sc csconfig.HubSourceCfg) (cwhub.Provider, error) {
+	verifyItems := c.Hub != nil && c.Hub.VerifyItems
+
+	switch sc.Type {
+	case cwhub.SourceHub:
+		// set branch in config, and log if necessary
+		branch, err := HubBranch(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+
+		remote := &cwhub.Downloader{
+			Branch:      branch,
+			URLTemplate: HubURLTemplate(c),
+		}
+
+		if c.Hub != nil && len(c.Hub.PublicKeys) > 0 {
+			remote.Verifier, err = cwhub.NewVerifier(c.Hub.PublicKeys)
+			if err != nil {
+				return nil, fmt.Errorf("hub.public_keys: %w", err)
+			}
+
+			remote.VerifyItems = verifyItems
+		}
+
+		return remote, nil
+	case cwhub.SourceHTTP:
+		if sc.URL == "" {
+			return nil, errors.New("url is required")
+		}
+
+		remote := &cwhub.Downloader{
+			Branch:      cmp.Or(sc.Branch, "master"),
+			URLTemplate: sc.URL,
+		}
+
+		if len(sc.PublicKeys) > 0 {
+			var err error
+
+			remote.Verifier, err = cwhub.NewVerifier(sc.PublicKeys)
+			if err != nil {
+				return nil, fmt.Errorf("public_keys: %w", err)
+			}
+
+			remote.VerifyItems = verifyItems
+		}
+
+		return remote, nil
+	case cwhub.SourceDir:
+		if sc.Path == "" {
+			return nil, errors.New("path is required")
+		}
+
+		return &cwhub.DirSource{Dir: sc.Path}, nil
+	case cwhub.SourceGit:
+		if sc.Path == "" {
+			return nil, errors.New("path is required")
+		}
+
+		// they are passed to git, and must not look like options
+		if strings.HasPrefix(sc.URL, "-") {
+			return nil, fmt.Errorf("invalid url '%s'", sc.URL)
+		}
+
+		if strings.HasPrefix(sc.Branch, "-") {
+			return nil, fmt.Errorf("invalid branch '%s'", sc.Branch)
+		}
+
+		return &cwhub.GitSource{Dir: sc.Path, Repo: sc.URL, Branch: sc.Branch}, nil
+	default:
+		return nil, fmt.Errorf("unknown type '%s' (expected %s, %s, %s or %s)",
+			sc.Type, cwhub.SourceHub, cwhub.SourceHTTP, cwhub.SourceDir, cwhub.SourceGit)
+	}
 }
 
 // Hub initializes the hub. If a remote configuration is provided, it can be used to download the index and items.
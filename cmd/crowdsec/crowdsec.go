@@ -14,6 +14,7 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/acquisition"
 	acquisitionTypes "github.com/crowdsecurity/crowdsec/pkg/acquisition/types"
 	"github.com/crowdsecurity/crowdsec/pkg/alertcontext"
+	"github.com/crowdsecurity/crowdsec/pkg/alertspool"
 	"github.com/crowdsecurity/crowdsec/pkg/apiclient"
 	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
 	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
@@ -125,14 +126,31 @@ func startHeartBeat(ctx context.Context, _ *csconfig.Config, apiClient *apiclien
 	apiClient.HeartBeat.StartHeartBeat(ctx)
 }
 
-func startOutputRoutines(ctx context.Context, cConfig *csconfig.Config, parsers *parser.Parsers, apiClient *apiclient.ApiClient, sd *StateDumper, bucketStore *leakybucket.BucketStore) {
+func startOutputRoutines(ctx context.Context, cConfig *csconfig.Config, parsers *parser.Parsers, apiClient *apiclient.ApiClient, sd *StateDumper, bucketStore *leakybucket.BucketStore) error {
+	var spool *alertspool.Spool
+
+	if cConfig.Crowdsec.AlertSpool.IsEnabled() {
+		var err error
+
+		spool, err = alertspool.New(cConfig.Crowdsec.AlertSpool)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("alerts that can't be sent to the LAPI are spooled in %s", cConfig.Crowdsec.AlertSpool.Dir)
+	}
+
+	sender := newAlertSender(apiClient, spool)
+
 	for idx := range cConfig.Crowdsec.OutputRoutinesCount {
 		log.WithField("idx", idx).Info("Starting output routine")
 		outputsTomb.Go(func() error {
 			defer trace.ReportPanic()
-			return runOutput(ctx, inEvents, outEvents, bucketStore, *parsers.PovfwCtx, parsers.Povfwnodes, apiClient, sd)
+			return runOutput(ctx, inEvents, outEvents, bucketStore, *parsers.PovfwCtx, parsers.Povfwnodes, sender, sd)
 		})
 	}
+
+	return nil
 }
 
 func startLPMetrics(ctx context.Context, cConfig *csconfig.Config, apiClient *apiclient.ApiClient, hub *cwhub.Hub, datasources []acquisitionTypes.DataSource) error {
@@ -178,6 +196,13 @@ func runCrowdsec(
 	logLines = make(chan pipeline.Event)
 
 	startParserRoutines(ctx, g, cConfig, parsers, sd.StageParse)
+
+	if cConfig.Crowdsec.BucketStateFile != "" {
+		if err := leakybucket.LoadBucketsState(ctx, cConfig.Crowdsec.BucketStateFile, bucketStore, holders); err != nil {
+			return fmt.Errorf("unable to restore bucket state: %w", err)
+		}
+	}
+
 	startBucketRoutines(ctx, g, cConfig, sd.Pour, bucketStore)
 
 	apiClient, err := apiclient.GetLAPIClient()
@@ -187,7 +212,9 @@ func runCrowdsec(
 
 	startHeartBeat(ctx, cConfig, apiClient)
 
-	startOutputRoutines(ctx, cConfig, parsers, apiClient, sd, bucketStore)
+	if err := startOutputRoutines(ctx, cConfig, parsers, apiClient, sd, bucketStore); err != nil {
+		return err
+	}
 
 	if err := startLPMetrics(ctx, cConfig, apiClient, hub, datasources); err != nil {
 		return err
@@ -244,6 +271,14 @@ func serveCrowdsec(
 			return fmt.Errorf("unable to shutdown crowdsec routines: %w", err)
 		}
 
+		if cConfig.Crowdsec.BucketStateDumpDir != "" {
+			log.Infof("Dumping bucket state to %s", cConfig.Crowdsec.BucketStateDumpDir)
+
+			if _, err := leakybucket.DumpBucketsState(time.Now().UTC(), cConfig.Crowdsec.BucketStateDumpDir, bucketStore, holders); err != nil {
+				log.Errorf("unable to dump bucket state: %s", err)
+			}
+		}
+
 		log.Debugf("everything is dead, return crowdsecTomb")
 		log.Debugf("sd.DumpDir == %s", sd.DumpDir)
 
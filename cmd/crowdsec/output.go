@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/crowdsecurity/crowdsec/pkg/alertspool"
 	"github.com/crowdsecurity/crowdsec/pkg/apiclient"
 	leaky "github.com/crowdsecurity/crowdsec/pkg/leakybucket"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
@@ -80,6 +82,131 @@ func PushAlerts(ctx context.Context, alerts []pipeline.RuntimeAlert, client *api
 	return nil
 }
 
+// alertSender pushes the alerts to the LAPI. With a spool, the alerts that could not be sent are
+// kept on disk instead of memory, and sent in order once the LAPI is reachable again.
+// It's shared by the output routines.
+type alertSender struct {
+	client *apiclient.ApiClient
+	spool  *alertspool.Spool
+	// with a spool, set while a push or a replay is in flight: there is one at a time,
+	// so a batch can't overtake an older one
+	busy atomic.Bool
+}
+
+func newAlertSender(client *apiclient.ApiClient, spool *alertspool.Spool) *alertSender {
+	return &alertSender{client: client, spool: spool}
+}
+
+func (s *alertSender) spoolAlerts(batch []pipeline.RuntimeAlert) {
+	if err := s.spool.Add(batch); err != nil {
+		log.Errorf("while spooling alerts: %s", err)
+	}
+}
+
+func (s *alertSender) send(ctx context.Context, batch []pipeline.RuntimeAlert, pendingAlerts *alertBuffer) {
+	if s.spool == nil {
+		if len(batch) == 0 {
+			return
+		}
+
+		outputsTomb.Go(func() error {
+			if err := PushAlerts(ctx, batch, s.client); err != nil {
+				log.Errorf("while pushing to api : %s", err)
+				// just push back the events to the queue
+				pendingAlerts.requeue(batch)
+			}
+			return nil
+		})
+
+		return
+	}
+
+	if len(batch) == 0 && s.spool.Len() == 0 {
+		return
+	}
+
+	if !s.busy.CompareAndSwap(false, true) {
+		// a replay is in flight, the new alerts go after the ones it's sending
+		if s.spool.Len() > 0 {
+			if len(batch) > 0 {
+				s.spoolAlerts(batch)
+			}
+
+			return
+		}
+
+		// the new alerts wait for the next tick, if the push in flight fails it's spooled before them
+		pendingAlerts.requeue(batch)
+
+		return
+	}
+
+	// keep the order, the new alerts go after the ones waiting in the spool
+	if s.spool.Len() > 0 {
+		s.spoolAlerts(batch)
+		s.replay(ctx)
+
+		return
+	}
+
+	outputsTomb.Go(func() error {
+		defer s.busy.Store(false)
+
+		if err := PushAlerts(ctx, batch, s.client); err != nil {
+			log.Errorf("while pushing to api : %s", err)
+			s.spoolAlerts(batch)
+		}
+		return nil
+	})
+}
+
+// replay sends the spooled alerts, oldest first, until the spool is empty or the LAPI fails again.
+// The caller must have set busy.
+func (s *alertSender) replay(ctx context.Context) {
+	outputsTomb.Go(func() error {
+		defer s.busy.Store(false)
+
+		for ctx.Err() == nil {
+			batch := s.spool.Oldest()
+			if batch == nil {
+				return nil
+			}
+
+			if err := PushAlerts(ctx, batch.Alerts, s.client); err != nil {
+				log.Errorf("while pushing spooled alerts to api : %s", err)
+				return nil
+			}
+
+			if err := s.spool.Remove(batch.Seq); err != nil {
+				log.Errorf("while removing sent alerts from the spool: %s", err)
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+// flush sends the leftovers on shutdown. They are spooled if that fails.
+func (s *alertSender) flush(ctx context.Context, batch []pipeline.RuntimeAlert) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if s.spool != nil && (s.spool.Len() > 0 || s.busy.Load()) {
+		s.spoolAlerts(batch)
+		return
+	}
+
+	if err := PushAlerts(ctx, batch, s.client); err != nil {
+		log.Errorf("while pushing leftovers to api : %s", err)
+
+		if s.spool != nil {
+			s.spoolAlerts(batch)
+		}
+	}
+}
+
 func runOutput(
 	ctx context.Context,
 	input chan pipeline.Event,
@@ -87,7 +214,7 @@ func runOutput(
 	bucketStore *leaky.BucketStore,
 	postOverflowCTX parser.UnixParserCtx,
 	postOverflowNodes []parser.Node,
-	client *apiclient.ApiClient,
+	sender *alertSender,
 	sd *StateDumper,
 ) error {
 	var pendingAlerts alertBuffer
@@ -98,30 +225,14 @@ func runOutput(
 	for {
 		select {
 		case <-ticker.C:
-			batch := pendingAlerts.takeAll()
-			if len(batch) == 0 {
-				break
-			}
 			/*
 				This loop needs to block as little as possible as scenarios directly write to the input chan
 				Under high load, LAPI may take between 1 and 2 seconds to process ~100 alerts, which slows down everything including the WAF.
-				Send the alerts from a goroutine to avoid staying too long in this case.
+				The alerts are sent from a goroutine to avoid staying too long in this case.
 			*/
-			outputsTomb.Go(func() error {
-				if err := PushAlerts(ctx, batch, client); err != nil {
-					log.Errorf("while pushing to api : %s", err)
-					// just push back the events to the queue
-					pendingAlerts.requeue(batch)
-				}
-				return nil
-			})
+			sender.send(ctx, pendingAlerts.takeAll(), &pendingAlerts)
 		case <-outputsTomb.Dying():
-			batch := pendingAlerts.takeAll()
-			if len(batch) > 0 {
-				if err := PushAlerts(ctx, batch, client); err != nil {
-					log.Errorf("while pushing leftovers to api : %s", err)
-				}
-			}
+			sender.flush(ctx, pendingAlerts.takeAll())
 			return nil
 		case event := <-overflow:
 			// if alert is empty and mapKey is present, the overflow is just to cleanup bucket
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -17,6 +18,7 @@ type PluginConfig struct {
 	Name       string `yaml:"name"`
 	LogLevel   string `yaml:"log_level"`
 	OutputFile string `yaml:"output_file"`
+	AlertsFile string `yaml:"alerts_file"`
 }
 
 type DummyPlugin struct {
@@ -66,11 +68,42 @@ func (s *DummyPlugin) Notify(_ context.Context, notification *protobufs.Notifica
 		}
 	}
 
+	if cfg.AlertsFile != "" {
+		if err := writeAlerts(cfg.AlertsFile, notification); err != nil {
+			logger.Error(fmt.Sprintf("Cannot write alerts to file: %s", err))
+		}
+	}
+
 	fmt.Fprintln(os.Stdout, text)
 
 	return &protobufs.Empty{}, nil
 }
 
+// writeAlerts appends the structured alerts of the notification to a file, one notification per line
+func writeAlerts(path string, notification *protobufs.Notification) error {
+	alerts, err := csplugin.NotificationAlerts(notification)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
 func (s *DummyPlugin) Configure(_ context.Context, config *protobufs.Config) (*protobufs.Empty, error) {
 	d := PluginConfig{}
 	err := yaml.Unmarshal(config.GetConfig(), &d)
@@ -88,13 +121,9 @@ func main() {
 
 	sp := &DummyPlugin{PluginConfigByName: make(map[string]PluginConfig)}
 	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"dummy": &csplugin.NotifierPlugin{
-				Impl: sp,
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-		Logger:     logger,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: csplugin.VersionedNotifierPlugins("dummy", sp),
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger:           logger,
 	})
 }
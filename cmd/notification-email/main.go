@@ -170,14 +170,11 @@ func main() {
 		MagicCookieValue: os.Getenv("CROWDSEC_PLUGIN_KEY"),
 	}
 
+	sp := &EmailPlugin{ConfigByName: make(map[string]PluginConfig)}
 	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"email": &csplugin.NotifierPlugin{
-				Impl: &EmailPlugin{ConfigByName: make(map[string]PluginConfig)},
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-		Logger:     baseLogger,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: csplugin.VersionedNotifierPlugins("email", sp),
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger:           baseLogger,
 	})
 }
@@ -259,13 +259,9 @@ func main() {
 
 	sp := &FilePlugin{PluginConfigByName: make(map[string]PluginConfig)}
 	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"file": &csplugin.NotifierPlugin{
-				Impl: sp,
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-		Logger:     logger,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: csplugin.VersionedNotifierPlugins("file", sp),
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger:           logger,
 	})
 }
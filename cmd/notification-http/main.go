@@ -197,13 +197,9 @@ func main() {
 
 	sp := &HTTPPlugin{PluginConfigByName: make(map[string]PluginConfig)}
 	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"http": &csplugin.NotifierPlugin{
-				Impl: sp,
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-		Logger:     logger,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: csplugin.VersionedNotifierPlugins("http", sp),
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger:           logger,
 	})
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/crowdsecurity/crowdsec/pkg/csplugin"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
 	"github.com/crowdsecurity/crowdsec/pkg/protobufs"
 )
 
@@ -25,6 +27,90 @@ type PluginConfig struct {
 	SharedKey  string `yaml:"shared_key"`
 	LogType    string `yaml:"log_type"`
 	LogLevel   string `yaml:"log_level"`
+	Structured bool   `yaml:"structured"`
+}
+
+// Record is a row of the custom log table, with the structured protocol
+type Record struct {
+	Scenario      string   `json:"scenario"`
+	Message       string   `json:"message"`
+	MachineID     string   `json:"machine_id"`
+	SourceScope   string   `json:"source_scope"`
+	SourceValue   string   `json:"source_value"`
+	SourceCountry string   `json:"source_country,omitempty"`
+	SourceAS      string   `json:"source_as,omitempty"`
+	EventsCount   int32    `json:"events_count"`
+	StartAt       string   `json:"start_at"`
+	StopAt        string   `json:"stop_at"`
+	Decisions     []string `json:"decisions"`
+}
+
+func newRecord(alert *models.Alert) Record {
+	r := Record{
+		Scenario:    alert.GetScenario(),
+		MachineID:   alert.MachineID,
+		EventsCount: alert.GetEventsCount(),
+		Decisions:   []string{},
+	}
+
+	if alert.Message != nil {
+		r.Message = *alert.Message
+	}
+
+	if alert.StartAt != nil {
+		r.StartAt = *alert.StartAt
+	}
+
+	if alert.StopAt != nil {
+		r.StopAt = *alert.StopAt
+	}
+
+	if alert.Source != nil {
+		r.SourceScope = alert.Source.GetScope()
+		r.SourceValue = alert.Source.GetValue()
+		r.SourceCountry = alert.Source.Cn
+		r.SourceAS = strings.TrimSpace(alert.Source.GetAsNumberName())
+	}
+
+	for _, decision := range alert.Decisions {
+		if decision.Type == nil || decision.Duration == nil {
+			continue
+		}
+
+		r.Decisions = append(r.Decisions, *decision.Type+" for "+*decision.Duration)
+	}
+
+	return r
+}
+
+// logData returns the formatted text, or a record per alert with the structured protocol
+func logData(cfg PluginConfig, notification *protobufs.Notification) (string, error) {
+	if !cfg.Structured {
+		return notification.GetText(), nil
+	}
+
+	alerts, err := csplugin.NotificationAlerts(notification)
+	if err != nil {
+		return "", err
+	}
+
+	if len(alerts) == 0 {
+		logger.Debug("no structured alerts in the notification, sending the text")
+		return notification.GetText(), nil
+	}
+
+	records := make([]Record, 0, len(alerts))
+
+	for _, alert := range alerts {
+		records = append(records, newRecord(alert))
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
 }
 
 type SentinelPlugin struct {
@@ -73,7 +159,10 @@ func (s *SentinelPlugin) Notify(ctx context.Context, notification *protobufs.Not
 
 	logger.Info("received notification for sentinel config", "name", name)
 
-	text := notification.GetText()
+	text, err := logData(cfg, notification)
+	if err != nil {
+		return &protobufs.Empty{}, err
+	}
 
 	url := fmt.Sprintf("https://%s.ods.opinsights.azure.com/api/logs?api-version=2016-04-01", cfg.CustomerID)
 	body := strings.NewReader(text)
@@ -134,13 +223,9 @@ func main() {
 
 	sp := &SentinelPlugin{PluginConfigByName: make(map[string]PluginConfig)}
 	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"sentinel": &csplugin.NotifierPlugin{
-				Impl: sp,
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-		Logger:     logger,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: csplugin.VersionedNotifierPlugins("sentinel", sp),
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger:           logger,
 	})
 }
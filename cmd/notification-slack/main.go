@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/go-hclog"
 	plugin "github.com/hashicorp/go-plugin"
@@ -11,6 +13,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/crowdsecurity/crowdsec/pkg/csplugin"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
 	"github.com/crowdsecurity/crowdsec/pkg/protobufs"
 )
 
@@ -22,6 +25,7 @@ type PluginConfig struct {
 	IconEmoji string `yaml:"icon_emoji"`
 	IconURL   string `yaml:"icon_url"`
 	LogLevel  string `yaml:"log_level"`
+	Blocks    bool   `yaml:"blocks"`
 }
 type Notify struct {
 	protobufs.UnimplementedNotifierServer
@@ -53,13 +57,28 @@ func (n *Notify) Notify(ctx context.Context, notification *protobufs.Notificatio
 	logger.Info(fmt.Sprintf("found notify signal for %s config", name))
 	logger.Debug(fmt.Sprintf("posting to %s webhook, message %s", cfg.Webhook, text))
 
-	err := slack.PostWebhookContext(ctx, cfg.Webhook, &slack.WebhookMessage{
+	msg := &slack.WebhookMessage{
 		Text:      text,
 		Channel:   cfg.Channel,
 		Username:  cfg.Username,
 		IconEmoji: cfg.IconEmoji,
 		IconURL:   cfg.IconURL,
-	})
+	}
+
+	if cfg.Blocks {
+		alerts, err := csplugin.NotificationAlerts(notification)
+		if err != nil {
+			return nil, err
+		}
+
+		// with an older crowdsec, there are no alerts and the text is sent as is
+		if len(alerts) > 0 {
+			// the text is still displayed in the notifications
+			msg.Blocks = alertBlocks(alerts)
+		}
+	}
+
+	err := slack.PostWebhookContext(ctx, cfg.Webhook, msg)
 	if err != nil {
 		logger.Error(err.Error())
 	}
@@ -67,6 +86,67 @@ func (n *Notify) Notify(ctx context.Context, notification *protobufs.Notificatio
 	return &protobufs.Empty{}, err
 }
 
+// slack rejects messages with more blocks
+const maxBlocks = 50
+
+// alertBlocks builds a section for each alert
+func alertBlocks(alerts []*models.Alert) *slack.Blocks {
+	blocks := &slack.Blocks{}
+
+	for i, alert := range alerts {
+		if i == maxBlocks-1 && len(alerts) > maxBlocks {
+			more := fmt.Sprintf("_and %d more alerts_", len(alerts)-i)
+			blocks.BlockSet = append(blocks.BlockSet, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, more, false, false)))
+
+			break
+		}
+
+		title := fmt.Sprintf("*%s*", alert.GetScenario())
+		if alert.Message != nil && *alert.Message != "" {
+			title += "\n" + *alert.Message
+		}
+
+		fields := []*slack.TextBlockObject{}
+
+		addField := func(label string, value string) {
+			if value == "" {
+				return
+			}
+
+			fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*\n%s", label, value), false, false))
+		}
+
+		if alert.Source != nil {
+			addField("Source", fmt.Sprintf("%s:%s", alert.Source.GetScope(), alert.Source.GetValue()))
+
+			if alert.Source.Cn != "" {
+				addField("Country", fmt.Sprintf(":flag-%s: %s", strings.ToLower(alert.Source.Cn), alert.Source.Cn))
+			}
+
+			addField("AS", strings.TrimSpace(alert.Source.GetAsNumberName()))
+		}
+
+		addField("Machine", alert.MachineID)
+		addField("Events", strconv.Itoa(int(alert.GetEventsCount())))
+
+		decisions := []string{}
+
+		for _, decision := range alert.Decisions {
+			if decision.Type == nil || decision.Duration == nil {
+				continue
+			}
+
+			decisions = append(decisions, fmt.Sprintf("%s for %s", *decision.Type, *decision.Duration))
+		}
+
+		addField("Decisions", strings.Join(decisions, ", "))
+
+		blocks.BlockSet = append(blocks.BlockSet, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, title, false, false), fields, nil))
+	}
+
+	return blocks
+}
+
 func (n *Notify) Configure(_ context.Context, config *protobufs.Config) (*protobufs.Empty, error) {
 	d := PluginConfig{}
 
@@ -88,13 +168,9 @@ func main() {
 	}
 
 	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"slack": &csplugin.NotifierPlugin{
-				Impl: &Notify{ConfigByName: make(map[string]PluginConfig)},
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-		Logger:     logger,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: csplugin.VersionedNotifierPlugins("slack", &Notify{ConfigByName: make(map[string]PluginConfig)}),
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger:           logger,
 	})
 }
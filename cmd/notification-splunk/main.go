@@ -26,10 +26,11 @@ var logger hclog.Logger = hclog.New(&hclog.LoggerOptions{
 })
 
 type PluginConfig struct {
-	Name     string `yaml:"name"`
-	URL      string `yaml:"url"`
-	Token    string `yaml:"token"`
-	LogLevel string `yaml:"log_level"`
+	Name       string `yaml:"name"`
+	URL        string `yaml:"url"`
+	Token      string `yaml:"token"`
+	LogLevel   string `yaml:"log_level"`
+	Structured bool   `yaml:"structured"`
 }
 
 type Splunk struct {
@@ -39,7 +40,38 @@ type Splunk struct {
 }
 
 type Payload struct {
-	Event string `json:"event"`
+	Event any `json:"event"`
+}
+
+// payload returns the body of the request: the formatted text as a single event,
+// or an event per alert with the structured protocol
+func payload(cfg PluginConfig, notification *protobufs.Notification) ([]byte, error) {
+	if cfg.Structured {
+		alerts, err := csplugin.NotificationAlerts(notification)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(alerts) > 0 {
+			// HEC accepts several events in the same request
+			var body []byte
+
+			for _, alert := range alerts {
+				data, err := json.Marshal(Payload{Event: alert})
+				if err != nil {
+					return nil, err
+				}
+
+				body = append(body, data...)
+			}
+
+			return body, nil
+		}
+
+		logger.Debug("no structured alerts in the notification, sending the text")
+	}
+
+	return json.Marshal(Payload{Event: notification.GetText()})
 }
 
 func (s *Splunk) Notify(ctx context.Context, notification *protobufs.Notification) (*protobufs.Empty, error) {
@@ -56,9 +88,7 @@ func (s *Splunk) Notify(ctx context.Context, notification *protobufs.Notificatio
 
 	logger.Info(fmt.Sprintf("received notify signal for %s config", name))
 
-	p := Payload{Event: notification.GetText()}
-
-	data, err := json.Marshal(p)
+	data, err := payload(cfg, notification)
 	if err != nil {
 		return &protobufs.Empty{}, err
 	}
@@ -119,13 +149,9 @@ func main() {
 
 	sp := &Splunk{PluginConfigByName: make(map[string]PluginConfig), Client: *client}
 	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: handshake,
-		Plugins: map[string]plugin.Plugin{
-			"splunk": &csplugin.NotifierPlugin{
-				Impl: sp,
-			},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-		Logger:     logger,
+		HandshakeConfig:  handshake,
+		VersionedPlugins: csplugin.VersionedNotifierPlugins("splunk", sp),
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger:           logger,
 	})
 }
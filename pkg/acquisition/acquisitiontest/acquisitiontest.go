@@ -0,0 +1,37 @@
+// Package acquisitiontest has the helpers shared by the tests of the datasources.
+package acquisitiontest
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/types"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// Configure configures a datasource without metrics, and fails the test on error.
+func Configure(t testing.TB, s types.DataSource, config string) {
+	t.Helper()
+
+	err := s.Configure(t.Context(), []byte(config), log.WithField("type", s.GetName()), metrics.AcquisitionMetricsLevelNone)
+	require.NoError(t, err)
+}
+
+// StartStream runs a datasource in the background. The returned channel receives the
+// result of Stream() once it is canceled, or fails.
+func StartStream(t testing.TB, s types.RestartableStreamer, out chan pipeline.Event) (context.CancelFunc, chan error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.Stream(ctx, out)
+	}()
+
+	return cancel, done
+}
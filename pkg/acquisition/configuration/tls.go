@@ -0,0 +1,113 @@
+package configuration
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ServerTLSConfig is the tls section of the datasources that accept connections.
+// With a CA, the clients must present a certificate signed by it.
+type ServerTLSConfig struct {
+	ServerCert string `yaml:"server_cert"`
+	ServerKey  string `yaml:"server_key"`
+	CaCert     string `yaml:"ca_cert"`
+}
+
+func (c *ServerTLSConfig) Validate() error {
+	if c.ServerCert == "" {
+		return errors.New("server_cert is required")
+	}
+
+	if c.ServerKey == "" {
+		return errors.New("server_key is required")
+	}
+
+	return nil
+}
+
+func (c *ServerTLSConfig) NewTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.ServerCert, c.ServerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server cert/key: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if c.CaCert != "" {
+		caCert, err := os.ReadFile(c.CaCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca cert: %w", err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", c.CaCert)
+		}
+
+		tlsConfig.ClientCAs = caCertPool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientTLSConfig is the tls section of the datasources that connect to a server.
+// The CA is added to the system ones to verify the server.
+type ClientTLSConfig struct {
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	ClientCert         string `yaml:"client_cert"`
+	ClientKey          string `yaml:"client_key"`
+	CaCert             string `yaml:"ca_cert"`
+}
+
+func (c *ClientTLSConfig) Validate() error {
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return errors.New("tls.client_cert and tls.client_key must be provided together")
+	}
+
+	return nil
+}
+
+func (c *ClientTLSConfig) NewTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec // explicitly enabled by the user
+	}
+
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert/key: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CaCert == "" {
+		return tlsConfig, nil
+	}
+
+	caCert, err := os.ReadFile(c.CaCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca cert: %w", err)
+	}
+
+	caCertPool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load system CA certificates: %w", err)
+	}
+
+	if caCertPool == nil {
+		caCertPool = x509.NewCertPool()
+	}
+
+	caCertPool.AppendCertsFromPEM(caCert)
+	tlsConfig.RootCAs = caCertPool
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,178 @@
+package appsecacquisition
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/appsec"
+	"github.com/crowdsecurity/crowdsec/pkg/appsec/appsec_rule"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func stackTraceResponse(contentType string) appsec.ParsedRequest {
+	return appsec.ParsedRequest{
+		ClientIP:    "1.2.3.4",
+		RemoteAddr:  "127.0.0.1",
+		Method:      "GET",
+		URI:         "/api/users",
+		Proto:       "HTTP/1.1",
+		HTTPRequest: &http.Request{Host: "example.com", URL: &url.URL{Path: "/api/users"}},
+		IsResponse:  true,
+		Response: &appsec.ParsedResponse{
+			StatusCode: 500,
+			Headers:    http.Header{"Content-Type": []string{contentType}},
+			Body:       []byte("Traceback (most recent call last):\n  File \"app.py\", line 42"),
+		},
+	}
+}
+
+var stackTraceRule = appsec_rule.CustomRule{
+	Name:  "stacktrace",
+	Zones: []string{"RESPONSE_BODY"},
+	Match: appsec_rule.Match{Type: "contains", Value: "Traceback (most recent call last)"},
+}
+
+func TestAppsecResponsePhase(t *testing.T) {
+	tests := []appsecRuleTest{
+		{
+			name:             "inband response body rule",
+			expected_load_ok: true,
+			inband_rules:     []appsec_rule.CustomRule{stackTraceRule},
+			input_request:    stackTraceResponse("text/plain"),
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Len(t, events, 2)
+				require.Equal(t, pipeline.APPSEC, events[0].Type)
+				require.Equal(t, pipeline.LOG, events[1].Type)
+				require.True(t, events[1].Appsec.HasInBandMatches)
+				require.Equal(t, "500", events[1].Parsed["response_status"])
+
+				require.Len(t, responses, 1)
+				require.True(t, responses[0].InBandInterrupt)
+				require.Equal(t, appsec.BanRemediation, appsecResponse.Action)
+				require.Equal(t, http.StatusForbidden, statusCode)
+			},
+		},
+		{
+			name:             "response status and headers rule",
+			expected_load_ok: true,
+			inband_rules: []appsec_rule.CustomRule{
+				{
+					Name: "listing",
+					And: []appsec_rule.CustomRule{
+						{
+							Zones: []string{"RESPONSE_STATUS"},
+							Match: appsec_rule.Match{Type: "equals", Value: "500"},
+						},
+						{
+							Zones:     []string{"RESPONSE_HEADERS"},
+							Variables: []string{"content-type"},
+							Match:     appsec_rule.Match{Type: "equals", Value: "image/png"},
+						},
+					},
+				},
+			},
+			input_request: stackTraceResponse("image/png"),
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Len(t, responses, 1)
+				require.True(t, responses[0].InBandInterrupt)
+			},
+		},
+		{
+			name:             "body of a not inspected content type",
+			expected_load_ok: true,
+			inband_rules:     []appsec_rule.CustomRule{stackTraceRule},
+			input_request:    stackTraceResponse("image/png"),
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Empty(t, events)
+				require.Len(t, responses, 1)
+				require.False(t, responses[0].InBandInterrupt)
+			},
+		},
+		{
+			name:             "outofband response body rule",
+			expected_load_ok: true,
+			outofband_rules:  []appsec_rule.CustomRule{stackTraceRule},
+			input_request:    stackTraceResponse("text/html"),
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Len(t, events, 1)
+				require.Equal(t, pipeline.LOG, events[0].Type)
+				require.True(t, events[0].Appsec.HasOutBandMatches)
+
+				require.Len(t, responses, 1)
+				require.False(t, responses[0].InBandInterrupt)
+				require.Equal(t, appsec.AllowRemediation, appsecResponse.Action)
+			},
+		},
+		{
+			name:             "response rules are not evaluated on requests",
+			expected_load_ok: true,
+			inband_rules: []appsec_rule.CustomRule{
+				{
+					// the request zone makes the rule match if it was evaluated
+					Name:  "status",
+					Zones: []string{"URI", "RESPONSE_STATUS"},
+					Match: appsec_rule.Match{Type: "contains", Value: "/api/"},
+				},
+			},
+			input_request: appsec.ParsedRequest{
+				ClientIP:    "1.2.3.4",
+				RemoteAddr:  "127.0.0.1",
+				Method:      "GET",
+				URI:         "/api/users",
+				HTTPRequest: &http.Request{Host: "example.com"},
+			},
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Empty(t, events)
+				require.Len(t, responses, 1)
+				require.False(t, responses[0].InBandInterrupt)
+			},
+		},
+		{
+			name:             "on_response hook removes a rule",
+			expected_load_ok: true,
+			inband_rules:     []appsec_rule.CustomRule{stackTraceRule},
+			on_response: []appsec.Hook{
+				{Filter: "resp.StatusCode == 500 && req.URL.Path startsWith '/api/'", Apply: []string{"RemoveInBandRuleByID(1522216472)"}}, //rule ID is generated at runtime. If you change rule, it will break the test (:
+			},
+			input_request: stackTraceResponse("text/plain"),
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Empty(t, events)
+				require.Len(t, responses, 1)
+				require.False(t, responses[0].InBandInterrupt)
+			},
+		},
+		{
+			name:             "inband on_response hook sets the remediation",
+			expected_load_ok: true,
+			inband_rules:     []appsec_rule.CustomRule{stackTraceRule},
+			inband_on_response: []appsec.Hook{
+				{Filter: "IsInBand", Apply: []string{"SetRemediation('captcha')"}},
+			},
+			input_request: stackTraceResponse("text/plain"),
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Len(t, responses, 1)
+				require.True(t, responses[0].InBandInterrupt)
+				require.Equal(t, appsec.CaptchaRemediation, appsecResponse.Action)
+			},
+		},
+		{
+			name:             "on_match knows about the response phase",
+			expected_load_ok: true,
+			inband_rules:     []appsec_rule.CustomRule{stackTraceRule},
+			on_match: []appsec.Hook{
+				{Filter: "IsResponse && resp.StatusCode >= 500", Apply: []string{"CancelAlert()"}},
+			},
+			input_request: stackTraceResponse("text/plain"),
+			output_asserts: func(events []pipeline.Event, responses []appsec.AppsecTempResponse, appsecResponse appsec.BodyResponse, statusCode int) {
+				require.Len(t, events, 1)
+				require.Equal(t, pipeline.LOG, events[0].Type)
+				require.True(t, responses[0].InBandInterrupt)
+			},
+		},
+	}
+
+	runTests(t, tests)
+}
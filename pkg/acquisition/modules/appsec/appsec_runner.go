@@ -69,6 +69,16 @@ func (*AppsecRunner) MergeDedupRules(collections []appsec.AppsecCollection, logg
 	return strings.Join(rulesArr, "\n")
 }
 
+// withResponseBody enables the inspection of the response bodies forwarded for the response phase.
+func withResponseBody(cfg coraza.WAFConfig, opts appsec.AppsecSubEngineOpts) coraza.WAFConfig {
+	mimeTypes := opts.ResponseBodyMimeTypes
+	if len(mimeTypes) == 0 {
+		mimeTypes = appsec.DefaultResponseBodyMimeTypes
+	}
+
+	return cfg.WithResponseBodyAccess().WithResponseBodyMimeTypes(mimeTypes)
+}
+
 func (r *AppsecRunner) Init(datadir string) error {
 	var err error
 	fs := os.DirFS(datadir)
@@ -90,6 +100,7 @@ func (r *AppsecRunner) Init(datadir string) error {
 	if r.AppsecRuntime.Config.InbandOptions.RequestBodyInMemoryLimit != nil {
 		inbandCfg = inbandCfg.WithRequestBodyInMemoryLimit(*r.AppsecRuntime.Config.InbandOptions.RequestBodyInMemoryLimit)
 	}
+	inbandCfg = withResponseBody(inbandCfg, r.AppsecRuntime.Config.InbandOptions)
 	r.AppsecInbandEngine, err = coraza.NewWAF(inbandCfg)
 	if err != nil {
 		return fmt.Errorf("unable to initialize inband engine : %w", err)
@@ -105,6 +116,7 @@ func (r *AppsecRunner) Init(datadir string) error {
 	if r.AppsecRuntime.Config.OutOfBandOptions.RequestBodyInMemoryLimit != nil {
 		outbandCfg = outbandCfg.WithRequestBodyInMemoryLimit(*r.AppsecRuntime.Config.OutOfBandOptions.RequestBodyInMemoryLimit)
 	}
+	outbandCfg = withResponseBody(outbandCfg, r.AppsecRuntime.Config.OutOfBandOptions)
 	r.AppsecOutbandEngine, err = coraza.NewWAF(outbandCfg)
 	if err != nil {
 		return fmt.Errorf("unable to initialize outband engine : %w", err)
@@ -265,6 +277,75 @@ func (r *AppsecRunner) processRequest(ctx context.Context, state *appsec.AppsecR
 	return nil
 }
 
+// processResponse evaluates the response phase rules on the upstream response.
+// Only the request line of the original request is known at this point.
+func (r *AppsecRunner) processResponse(state *appsec.AppsecRequestState, request *appsec.ParsedRequest) {
+	if state.Tx.IsRuleEngineOff() {
+		r.logger.Debugf("rule engine is off, skipping")
+		return
+	}
+
+	if err := r.AppsecRuntime.ProcessOnResponseRules(state, request); err != nil {
+		r.logger.Errorf("unable to process OnResponse rules: %s", err)
+	}
+
+	if state.DropInfo(request) != nil {
+		r.logger.Debug("drop helper triggered during on_response, skipping WAF evaluation")
+		return
+	}
+
+	defer state.Tx.ProcessLogging()
+
+	state.Tx.ProcessConnection(request.ClientIP, 0, "", 0)
+
+	for k, v := range request.Args {
+		for _, vv := range v {
+			state.Tx.AddGetRequestArgument(k, vv)
+		}
+	}
+
+	state.Tx.ProcessURI(request.URI, request.Method, request.Proto)
+
+	if request.ClientHost != "" {
+		state.Tx.SetServerName(request.ClientHost)
+	}
+
+	response := request.Response
+
+	for k, vr := range response.Headers {
+		for _, v := range vr {
+			state.Tx.AddResponseHeader(k, v)
+		}
+	}
+
+	if in := state.Tx.ProcessResponseHeaders(response.StatusCode, request.Proto); in != nil {
+		r.logger.Debugf("rules matched for response headers : %d", in.RuleID)
+		return
+	}
+
+	if response.BodyTruncated {
+		r.logger.Warnf("response body was truncated to %d bytes", len(response.Body))
+	}
+
+	if len(response.Body) > 0 {
+		in, _, err := state.Tx.WriteResponseBody(response.Body)
+		if err != nil {
+			r.logger.Warnf("unable to write response body: %s", err)
+		} else if in != nil {
+			return
+		}
+	}
+
+	in, err := state.Tx.ProcessResponseBody()
+	if err != nil {
+		r.logger.Warnf("unable to process response body: %s", err)
+	}
+
+	if in != nil {
+		r.logger.Debugf("rules matched for response body : %d", in.RuleID)
+	}
+}
+
 func (r *AppsecRunner) ProcessInBandRules(ctx context.Context, state *appsec.AppsecRequestState, request *appsec.ParsedRequest) error {
 	tx := appsec.NewExtendedTransaction(r.AppsecInbandEngine, request.UUID)
 	state.Tx = tx
@@ -485,6 +566,11 @@ func (r *AppsecRunner) handleRequest(ctx context.Context, request *appsec.Parsed
 		}
 	}
 
+	if request.IsResponse {
+		r.handleResponse(ctx, &state, request)
+		return
+	}
+
 	request.IsInBand = true
 	request.IsOutBand = false
 
@@ -567,6 +653,45 @@ func (r *AppsecRunner) handleRequest(ctx context.Context, request *appsec.Parsed
 	metrics.AppsecGlobalParsingHistogram.With(prometheus.Labels{"source": request.RemoteAddrNormalized, "appsec_engine": request.AppsecEngine}).Observe(globalParsingElapsed.Seconds())
 }
 
+// handleResponse runs the response phase: the in-band rules decide of the remediation
+// sent back to the bouncer, the out-of-band rules are evaluated afterwards.
+func (r *AppsecRunner) handleResponse(ctx context.Context, state *appsec.AppsecRequestState, request *appsec.ParsedRequest) {
+	request.IsInBand = true
+	request.IsOutBand = false
+	state.CurrentPhase = appsec.PhaseInBand
+	state.Tx = appsec.NewExtendedTransaction(r.AppsecInbandEngine, request.UUID)
+
+	r.processResponse(state, request)
+
+	if state.Tx.IsInterrupted() || state.InBandDrop != nil {
+		r.handleInBandInterrupt(ctx, state, request)
+	}
+
+	if err := state.Tx.Close(); err != nil {
+		r.logger.Errorf("unable to close inband transaction: %s", err)
+	}
+
+	// Clone as the out-of-band phase might mutate the response
+	request.ResponseChannel <- state.Response.Clone()
+
+	request.IsInBand = false
+	request.IsOutBand = true
+	state.Response.SendAlert = false
+	state.Response.SendEvent = true
+	state.CurrentPhase = appsec.PhaseOutOfBand
+	state.Tx = appsec.NewExtendedTransaction(r.AppsecOutbandEngine, request.UUID)
+
+	r.processResponse(state, request)
+
+	if state.Tx.IsInterrupted() || state.OutOfBandDrop != nil {
+		r.handleOutBandInterrupt(ctx, state, request)
+	}
+
+	if err := state.Tx.Close(); err != nil {
+		r.logger.Errorf("unable to close outband transaction: %s", err)
+	}
+}
+
 // closeEngine releases the resources cached by a coraza engine. Compiled
 // regexes and operators are memoized process-wide and only freed on Close, so
 // skipping it leaks them for every engine we build across reloads.
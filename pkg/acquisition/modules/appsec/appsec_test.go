@@ -36,6 +36,7 @@ type appsecRuleTest struct {
 	post_eval    []appsec.Hook
 	on_match     []appsec.Hook
 	on_challenge []appsec.Hook
+	on_response  []appsec.Hook
 	// Phase-scoped hooks (dispatched only during the matching phase)
 	inband_on_match        []appsec.Hook
 	inband_pre_eval        []appsec.Hook
@@ -44,6 +45,7 @@ type appsecRuleTest struct {
 	outofband_on_match     []appsec.Hook
 	outofband_pre_eval     []appsec.Hook
 	outofband_post_eval    []appsec.Hook
+	inband_on_response     []appsec.Hook
 	BouncerBlockedHTTPCode int
 	UserBlockedHTTPCode    int
 	UserPassedHTTPCode     int
@@ -122,6 +124,7 @@ func testAppSecEngine(t *testing.T, test appsecRuleTest) {
 		PostEval:               test.post_eval,
 		OnMatch:                test.on_match,
 		OnChallenge:            test.on_challenge,
+		OnResponse:             test.on_response,
 		BouncerBlockedHTTPCode: test.BouncerBlockedHTTPCode,
 		UserBlockedHTTPCode:    test.UserBlockedHTTPCode,
 		UserPassedHTTPCode:     test.UserPassedHTTPCode,
@@ -130,12 +133,13 @@ func testAppSecEngine(t *testing.T, test appsecRuleTest) {
 	}
 
 	// Set phase-scoped hooks if any are provided
-	if len(test.inband_on_match) > 0 || len(test.inband_pre_eval) > 0 || len(test.inband_post_eval) > 0 || len(test.inband_on_challenge) > 0 {
+	if len(test.inband_on_match) > 0 || len(test.inband_pre_eval) > 0 || len(test.inband_post_eval) > 0 || len(test.inband_on_challenge) > 0 || len(test.inband_on_response) > 0 {
 		appsecCfg.InBand = &appsec.AppsecPhaseConfig{
 			OnMatch:     test.inband_on_match,
 			PreEval:     test.inband_pre_eval,
 			PostEval:    test.inband_post_eval,
 			OnChallenge: test.inband_on_challenge,
+			OnResponse:  test.inband_on_response,
 		}
 	}
 
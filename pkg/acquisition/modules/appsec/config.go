@@ -40,11 +40,14 @@ var (
 
 // configuration structure of the acquis for the application security engine
 type Configuration struct {
-	ListenAddr        string         `yaml:"listen_addr"`
-	ListenSocket      string         `yaml:"listen_socket"`
-	CertFilePath      string         `yaml:"cert_file"`
-	KeyFilePath       string         `yaml:"key_file"`
-	Path              string         `yaml:"path"`
+	ListenAddr   string `yaml:"listen_addr"`
+	ListenSocket string `yaml:"listen_socket"`
+	CertFilePath string `yaml:"cert_file"`
+	KeyFilePath  string `yaml:"key_file"`
+	Path         string `yaml:"path"`
+	// ResponsePath is the endpoint where the remediation component forwards the upstream
+	// responses to be inspected by the response phase rules. The response phase is disabled if empty.
+	ResponsePath      string         `yaml:"response_path"`
 	Routines          int            `yaml:"routines"`
 	AppsecConfig      string         `yaml:"appsec_config"`
 	AppsecConfigs     []string       `yaml:"appsec_configs"`
@@ -77,6 +80,16 @@ func (w *Source) UnmarshalConfig(yamlConfig []byte) error {
 		w.config.Path = "/" + w.config.Path
 	}
 
+	if w.config.ResponsePath != "" {
+		if w.config.ResponsePath[0] != '/' {
+			w.config.ResponsePath = "/" + w.config.ResponsePath
+		}
+
+		if w.config.ResponsePath == w.config.Path {
+			return errors.New("response_path must be different from path")
+		}
+	}
+
 	if w.config.Mode == "" {
 		w.config.Mode = configuration.TAIL_MODE
 	}
@@ -130,7 +143,7 @@ func loadCertPool(caCertPath string, logger log.FieldLogger) (*x509.CertPool, er
 }
 
 // expandAppsecConfigEntry resolves a single appsec_config(s) entry into the list
-// of appsec-config item names to load. A literal entry is returned untouched. An entry containing a glob meta-character 
+// of appsec-config item names to load. A literal entry is returned untouched. An entry containing a glob meta-character
 // is matched against the installed appsec-configs with the same matcher used
 // to expand appsec-rule patterns; it errors when no installed config matches.
 func expandAppsecConfigEntry(entry string, hub *cwhub.Hub) ([]string, error) {
@@ -324,6 +337,11 @@ func (w *Source) Configure(ctx context.Context, yamlConfig []byte, logger *log.E
 	// We don´t use the wrapper provided by coraza because we want to fully control what happens when a rule match to send the information in crowdsec
 	w.mux.HandleFunc(w.config.Path, w.appsecHandler)
 
+	if w.config.ResponsePath != "" {
+		w.logger.Infof("response phase enabled on %s", w.config.ResponsePath)
+		w.mux.HandleFunc(w.config.ResponsePath, w.appsecResponseHandler)
+	}
+
 	caCertPath := ""
 
 	if w.lapiClientConfig != nil && w.lapiClientConfig.Credentials != nil {
@@ -422,8 +440,20 @@ func (w *Source) checkAuth(ctx context.Context, apiKey string) error {
 	return nil
 }
 
+// requestParser builds the request sent to the runners from the one of the remediation component.
+type requestParser func(r *http.Request, logger *log.Entry, bodySettings appsec.BodySettings) (appsec.ParsedRequest, error)
+
 // should this be in the runner ?
 func (w *Source) appsecHandler(rw http.ResponseWriter, r *http.Request) {
+	w.serveAppsec(rw, r, appsec.NewParsedRequestFromRequest)
+}
+
+// appsecResponseHandler receives the upstream responses for the response phase.
+func (w *Source) appsecResponseHandler(rw http.ResponseWriter, r *http.Request) {
+	w.serveAppsec(rw, r, appsec.NewParsedResponseFromRequest)
+}
+
+func (w *Source) serveAppsec(rw http.ResponseWriter, r *http.Request, parse requestParser) {
 	ctx := r.Context()
 	w.logger.Debugf("Received request from '%s' on %s", r.RemoteAddr, r.URL.Path)
 
@@ -448,7 +478,7 @@ func (w *Source) appsecHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	// parse the request only once
-	parsedRequest, err := appsec.NewParsedRequestFromRequest(r, w.logger, w.AppsecRuntime.BodySettings)
+	parsedRequest, err := parse(r, w.logger, w.AppsecRuntime.BodySettings)
 	if err != nil {
 		w.logger.Errorf("%s", err)
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -29,9 +29,20 @@ type Configuration struct {
 	PollWithoutInotify                *bool         `yaml:"poll_without_inotify"`
 	DiscoveryPollEnable               bool          `yaml:"discovery_poll_enable"`
 	DiscoveryPollInterval             time.Duration `yaml:"discovery_poll_interval"`
+	StartPosition                     string        `yaml:"start_position"`          // where to start reading the files present at startup: end, beginning or resume
+	PositionDB                        string        `yaml:"position_db"`             // file where the read offsets are saved, required with start_position: resume
+	PositionFlushInterval             time.Duration `yaml:"position_flush_interval"` // how often the read offsets are saved
 	configuration.DataSourceCommonCfg `yaml:",inline"`
 }
 
+const (
+	StartPositionEnd       = "end"
+	StartPositionBeginning = "beginning"
+	StartPositionResume    = "resume"
+
+	defaultPositionFlushInterval = 5 * time.Second
+)
+
 func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
 	s.config = Configuration{}
 
@@ -60,6 +71,22 @@ func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
 		return fmt.Errorf("unsupported mode %s for file source", s.config.Mode)
 	}
 
+	switch s.config.StartPosition {
+	case "":
+		s.config.StartPosition = StartPositionEnd
+	case StartPositionEnd, StartPositionBeginning:
+	case StartPositionResume:
+		if s.config.PositionDB == "" {
+			return errors.New("position_db is required with start_position: resume")
+		}
+	default:
+		return fmt.Errorf("invalid start_position '%s': must be one of end, beginning, resume", s.config.StartPosition)
+	}
+
+	if s.config.PositionFlushInterval == 0 {
+		s.config.PositionFlushInterval = defaultPositionFlushInterval
+	}
+
 	for _, exclude := range s.config.ExcludeRegexps {
 		re, err := regexp.Compile(exclude)
 		if err != nil {
@@ -81,6 +108,13 @@ func (s *Source) Configure(_ context.Context, yamlConfig []byte, logger *log.Ent
 		return err
 	}
 
+	if s.config.StartPosition == StartPositionResume && s.config.Mode == configuration.TAIL_MODE {
+		s.positions, err = getPositionDB(s.config.PositionDB)
+		if err != nil {
+			return err
+		}
+	}
+
 	s.watchedDirectories = make(map[string]bool)
 	s.tailMapMutex = &sync.RWMutex{}
 	s.tails = make(map[string]bool)
@@ -0,0 +1,11 @@
+//go:build windows
+
+package fileacquisition
+
+import "os"
+
+// FileInfo doesn't carry a file index on windows: positions are tracked by path only,
+// rotations are detected by the file being truncated.
+func fileInode(_ os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,189 @@
+package fileacquisition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nxadm/tail"
+	log "github.com/sirupsen/logrus"
+)
+
+// positionEntry is the last read offset of a tailed file.
+// The inode is used to detect files that have been rotated while crowdsec was not running.
+type positionEntry struct {
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+// positionDB keeps track of the read offsets of the tailed files, and persists them to disk.
+type positionDB struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]positionEntry // by file path
+	dirty   bool
+}
+
+var (
+	positionDBs     = make(map[string]*positionDB)
+	positionDBsLock sync.Mutex
+)
+
+// getPositionDB returns the position database stored at path, loading it on first use.
+// Datasources configured with the same position_db share the same instance.
+func getPositionDB(path string) (*positionDB, error) {
+	positionDBsLock.Lock()
+	defer positionDBsLock.Unlock()
+
+	if db, ok := positionDBs[path]; ok {
+		return db, nil
+	}
+
+	db := &positionDB{
+		path:    path,
+		entries: make(map[string]positionEntry),
+	}
+
+	body, err := os.ReadFile(path)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// first run
+	case err != nil:
+		return nil, fmt.Errorf("could not read position_db %s: %w", path, err)
+	default:
+		if err := json.Unmarshal(body, &db.entries); err != nil {
+			return nil, fmt.Errorf("could not parse position_db %s: %w", path, err)
+		}
+	}
+
+	positionDBs[path] = db
+
+	return db, nil
+}
+
+// resumeLocation returns where to start tailing a file, according to the saved positions.
+// The second return value is false if the file is unknown.
+func (db *positionDB) resumeLocation(file string, fi os.FileInfo, logger *log.Entry) (*tail.SeekInfo, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	inode := fileInode(fi)
+
+	entry, ok := db.entries[file]
+
+	if !ok || (inode != 0 && entry.Inode != inode) {
+		// the file may have been renamed by a rotation, look for its inode elsewhere
+		for path, other := range db.entries {
+			if inode == 0 || other.Inode != inode || path == file {
+				continue
+			}
+
+			logger.Infof("%s was previously read as %s", file, path)
+
+			delete(db.entries, path)
+
+			return db.seekInfo(fi, other.Offset, logger), true
+		}
+
+		if ok {
+			// same name but another file: it has been created by a rotation, nothing has been read from it yet
+			logger.Infof("%s has been rotated since last run, reading from the beginning", file)
+			return &tail.SeekInfo{Offset: 0, Whence: io.SeekStart}, true
+		}
+
+		return nil, false
+	}
+
+	return db.seekInfo(fi, entry.Offset, logger), true
+}
+
+func (*positionDB) seekInfo(fi os.FileInfo, offset int64, logger *log.Entry) *tail.SeekInfo {
+	if offset > fi.Size() {
+		logger.Infof("%s has been truncated since last run (size %d, offset %d), reading from the beginning", fi.Name(), fi.Size(), offset)
+		offset = 0
+	}
+
+	return &tail.SeekInfo{Offset: offset, Whence: io.SeekStart}
+}
+
+func (db *positionDB) update(file string, inode uint64, offset int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if entry, ok := db.entries[file]; inode != 0 && (!ok || entry.Inode != inode) {
+		// the inode now belongs to this file: the entries of other paths with
+		// the same inode are left from deleted files, and would be resumed by mistake
+		for path, other := range db.entries {
+			if path != file && other.Inode == inode {
+				delete(db.entries, path)
+			}
+		}
+	}
+
+	db.entries[file] = positionEntry{Inode: inode, Offset: offset}
+	db.dirty = true
+}
+
+// remove forgets the position of a file that is not tailed anymore.
+func (db *positionDB) remove(file string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.entries[file]; !ok {
+		return
+	}
+
+	delete(db.entries, file)
+	db.dirty = true
+}
+
+// flush writes the positions to disk, if they changed since the last call.
+func (db *positionDB) flush() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !db.dirty {
+		return nil
+	}
+
+	body, err := json.Marshal(db.entries)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(db.path)
+
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(db.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), db.path); err != nil {
+		return err
+	}
+
+	db.dirty = false
+
+	return nil
+}
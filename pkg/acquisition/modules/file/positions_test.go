@@ -0,0 +1,182 @@
+//go:build !windows
+
+package fileacquisition
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/tomb.v2"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func TestPositionDB(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger := log.WithField("test", "positions")
+
+	logFile := filepath.Join(tmpDir, "test.log")
+	rotatedFile := filepath.Join(tmpDir, "test.log.1")
+	dbFile := filepath.Join(tmpDir, "positions.json")
+
+	require.NoError(t, os.WriteFile(logFile, []byte("line1\nline2\n"), 0o644))
+
+	fi, err := os.Stat(logFile)
+	require.NoError(t, err)
+
+	db, err := getPositionDB(dbFile)
+	require.NoError(t, err)
+
+	_, ok := db.resumeLocation(logFile, fi, logger)
+	assert.False(t, ok, "unknown file")
+
+	db.update(logFile, fileInode(fi), 6)
+	require.NoError(t, db.flush())
+
+	// reload from disk
+	positionDBsLock.Lock()
+	delete(positionDBs, dbFile)
+	positionDBsLock.Unlock()
+
+	db, err = getPositionDB(dbFile)
+	require.NoError(t, err)
+
+	loc, ok := db.resumeLocation(logFile, fi, logger)
+	require.True(t, ok)
+	assert.Equal(t, int64(6), loc.Offset)
+	assert.Equal(t, io.SeekStart, loc.Whence)
+
+	// rotation while stopped: the old file is renamed, a new one is created
+	require.NoError(t, os.Rename(logFile, rotatedFile))
+	require.NoError(t, os.WriteFile(logFile, []byte("line3\n"), 0o644))
+
+	newFi, err := os.Stat(logFile)
+	require.NoError(t, err)
+
+	loc, ok = db.resumeLocation(logFile, newFi, logger)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), loc.Offset, "new file is read from the beginning")
+
+	rotatedFi, err := os.Stat(rotatedFile)
+	require.NoError(t, err)
+
+	loc, ok = db.resumeLocation(rotatedFile, rotatedFi, logger)
+	require.True(t, ok)
+	assert.Equal(t, int64(6), loc.Offset, "rotated file is found by inode")
+
+	// truncated file
+	db.update(logFile, fileInode(newFi), 100)
+
+	loc, ok = db.resumeLocation(logFile, newFi, logger)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), loc.Offset)
+
+	// the file is not tailed anymore
+	db.remove(logFile)
+
+	_, ok = db.resumeLocation(logFile, newFi, logger)
+	assert.False(t, ok)
+}
+
+func TestPositionDBReusedInode(t *testing.T) {
+	db, err := getPositionDB(filepath.Join(t.TempDir(), "positions.json"))
+	require.NoError(t, err)
+
+	// the inode of a deleted file is given to a new one
+	db.update("/var/log/deleted.log", 42, 100)
+	db.update("/var/log/new.log", 42, 10)
+
+	assert.Equal(t, map[string]positionEntry{"/var/log/new.log": {Inode: 42, Offset: 10}}, db.entries)
+
+	// the tailer of a file reopened it after a rotation
+	db.update("/var/log/other.log", 43, 100)
+	db.update("/var/log/new.log", 43, 0)
+
+	assert.Equal(t, map[string]positionEntry{"/var/log/new.log": {Inode: 43, Offset: 0}}, db.entries)
+}
+
+func TestStreamingResume(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logFile := filepath.Join(tmpDir, "test.log")
+	dbFile := filepath.Join(tmpDir, "positions.json")
+
+	require.NoError(t, os.WriteFile(logFile, []byte("old1\nold2\n"), 0o644))
+
+	config := fmt.Sprintf(`
+mode: tail
+filenames:
+  - %s
+start_position: resume
+position_db: %s
+position_flush_interval: 100ms
+`, logFile, dbFile)
+
+	appendLines := func(lines ...string) {
+		fd, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+
+		for _, line := range lines {
+			_, err = fd.WriteString(line + "\n")
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, fd.Close())
+	}
+
+	run := func(expected int, write func()) []string {
+		// start from the positions on disk, as after a restart
+		positionDBsLock.Lock()
+		delete(positionDBs, dbFile)
+		positionDBsLock.Unlock()
+
+		s := &Source{}
+		require.NoError(t, s.Configure(t.Context(), []byte(config), log.WithField("type", ModuleName), metrics.AcquisitionMetricsLevelNone))
+
+		out := make(chan pipeline.Event)
+		tb := tomb.Tomb{}
+
+		require.NoError(t, s.StreamingAcquisition(t.Context(), out, &tb))
+
+		require.True(t, s.IsTailing(logFile))
+
+		// the tailer opens the file in the background
+		time.Sleep(500 * time.Millisecond)
+
+		write()
+
+		var lines []string
+
+		for range expected {
+			select {
+			case evt := <-out:
+				lines = append(lines, evt.Line.Raw)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timeout waiting for lines, got %v", lines)
+			}
+		}
+
+		tb.Kill(nil)
+		require.NoError(t, tb.Wait())
+
+		return lines
+	}
+
+	// first run: the file is unknown, it is read from the end
+	lines := run(1, func() { appendLines("live1") })
+	assert.Equal(t, []string{"live1"}, lines)
+
+	// written while crowdsec is stopped
+	appendLines("missed1", "missed2")
+
+	lines = run(3, func() { appendLines("live2") })
+	assert.Equal(t, []string{"missed1", "missed2", "live2"}, lines)
+}
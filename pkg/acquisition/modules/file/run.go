@@ -59,6 +59,14 @@ func (s *Source) StreamingAcquisition(_ context.Context, out chan pipeline.Event
 		return s.monitorNewFiles(out, t)
 	})
 
+	if s.positions != nil {
+		t.Go(func() error {
+			defer trace.ReportPanic()
+			s.flushPositions(t)
+			return nil
+		})
+	}
+
 	for _, file := range s.files {
 		if err := s.setupTailForFile(file, out, true, t); err != nil {
 			s.logger.Errorf("Error setting up tail for %s: %s", file, err)
@@ -264,6 +272,25 @@ func (s *Source) setupTailForFile(file string, out chan pipeline.Event, seekEnd
 
 	if seekEnd {
 		seekInfo.Whence = io.SeekEnd
+		if s.config.StartPosition == StartPositionBeginning {
+			seekInfo.Whence = io.SeekStart
+		}
+	}
+
+	inode := fileInode(fi)
+	startOffset := fi.Size()
+
+	if s.positions != nil {
+		if saved, ok := s.positions.resumeLocation(file, fi, logger); ok {
+			seekInfo = saved
+		}
+
+		if seekInfo.Whence == io.SeekStart {
+			startOffset = seekInfo.Offset
+		}
+
+		// record the position right away, so that lines written before the next restart are not lost
+		s.positions.update(file, inode, startOffset)
 	}
 
 	logger.Infof("Starting tail (offset: %d, whence: %d)", seekInfo.Offset, seekInfo.Whence)
@@ -285,13 +312,52 @@ func (s *Source) setupTailForFile(file string, out chan pipeline.Event, seekEnd
 
 	t.Go(func() error {
 		defer trace.ReportPanic()
-		return s.tailFile(out, t, tail)
+		return s.tailFile(out, t, tail, inode, startOffset)
 	})
 
 	return nil
 }
 
-func (s *Source) tailFile(out chan pipeline.Event, t *tomb.Tomb, tail *tail.Tail) error {
+// flushPositions periodically saves the read offsets, and one last time when the datasource stops.
+func (s *Source) flushPositions(t *tomb.Tomb) {
+	ticker := time.NewTicker(s.config.PositionFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.positions.flush(); err != nil {
+				s.logger.Errorf("could not save file positions to %s: %s", s.config.PositionDB, err)
+			}
+		case <-t.Dying():
+			if err := s.positions.flush(); err != nil {
+				s.logger.Errorf("could not save file positions to %s: %s", s.config.PositionDB, err)
+			}
+
+			return
+		}
+	}
+}
+
+// trackPosition records the offset of the last line sent from the file.
+// When the offset goes backward the tailer has reopened the file after a rotation,
+// so the inode is looked up again.
+func (s *Source) trackPosition(filename string, inode uint64, lastOffset int64, offset int64, logger *log.Entry) uint64 {
+	if offset < lastOffset {
+		fi, err := os.Stat(filename)
+		if err != nil {
+			logger.Warningf("could not stat %s after reopen: %s", filename, err)
+		} else {
+			inode = fileInode(fi)
+		}
+	}
+
+	s.positions.update(filename, inode, offset)
+
+	return inode
+}
+
+func (s *Source) tailFile(out chan pipeline.Event, t *tomb.Tomb, tail *tail.Tail, inode uint64, lastOffset int64) error {
 	logger := s.logger.WithField("tail", tail.Filename)
 	logger.Debug("-> start tailing")
 
@@ -322,6 +388,10 @@ func (s *Source) tailFile(out chan pipeline.Event, t *tomb.Tomb, tail *tail.Tail
 			delete(s.tails, tail.Filename)
 			s.tailMapMutex.Unlock()
 
+			if s.positions != nil {
+				s.positions.remove(tail.Filename)
+			}
+
 			return nil
 		case line := <-tail.Lines:
 			if line == nil {
@@ -362,6 +432,11 @@ func (s *Source) tailFile(out chan pipeline.Event, t *tomb.Tomb, tail *tail.Tail
 			evt.Line = l
 
 			out <- evt
+
+			if s.positions != nil {
+				inode = s.trackPosition(tail.Filename, inode, lastOffset, line.SeekInfo.Offset, logger)
+				lastOffset = line.SeekInfo.Offset
+			}
 		}
 	}
 }
@@ -20,6 +20,7 @@ type Source struct {
 	files              []string
 	exclude_regexps    []*regexp.Regexp
 	tailMapMutex       *sync.RWMutex
+	positions          *positionDB // read offsets, only with start_position: resume
 }
 
 func (s *Source) GetUuid() string {
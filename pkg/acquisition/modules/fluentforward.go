@@ -0,0 +1,5 @@
+//go:build !no_datasource_fluentforward
+
+package modules
+
+import _ "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/fluentforward" // register the datasource
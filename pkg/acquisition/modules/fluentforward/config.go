@@ -0,0 +1,231 @@
+package fluentforwardacquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	yaml "github.com/goccy/go-yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+const (
+	RawFormatMessage = "message"
+	RawFormatJSON    = "json"
+
+	defaultMessageKey   = "log"
+	defaultMaxChunkSize = 8 * 1024 * 1024
+)
+
+type Configuration struct {
+	ListenAddr                        string                         `yaml:"listen_addr"`
+	TLS                               *configuration.ServerTLSConfig `yaml:"tls"` // with a CA, the forwarders must present a client certificate
+	SharedKey                         string                         `yaml:"shared_key"`
+	SelfHostname                      string                         `yaml:"self_hostname"`
+	MaxChunkSize                      int64                          `yaml:"max_chunk_size"`
+	MessageKey                        string                         `yaml:"message_key"`
+	RawFormat                         string                         `yaml:"raw_format"`
+	TagLabel                          string                         `yaml:"tag_label"`
+	RecordLabels                      map[string]string              `yaml:"record_labels"`
+	Routes                            []RouteConfig                  `yaml:"routes"`
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+}
+
+// RouteConfig sets the labels of the events whose tag matches the pattern. The first matching route wins.
+type RouteConfig struct {
+	Tag    string            `yaml:"tag"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type route struct {
+	tag    *regexp.Regexp
+	labels map[string]string
+}
+
+func ConfigurationFromYAML(y []byte) (Configuration, error) {
+	var cfg Configuration
+
+	if err := yaml.UnmarshalWithOptions(y, &cfg, yaml.Strict()); err != nil {
+		return cfg, fmt.Errorf("cannot parse: %s", yaml.FormatError(err, false, false))
+	}
+
+	cfg.SetDefaults()
+
+	err := cfg.Validate()
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Configuration) SetDefaults() {
+	if c.Mode == "" {
+		c.Mode = configuration.TAIL_MODE
+	}
+
+	if c.MaxChunkSize == 0 {
+		c.MaxChunkSize = defaultMaxChunkSize
+	}
+
+	if c.MessageKey == "" {
+		c.MessageKey = defaultMessageKey
+	}
+
+	if c.RawFormat == "" {
+		c.RawFormat = RawFormatMessage
+	}
+
+	// the hostname is sent back to the forwarders during the handshake
+	if c.SharedKey != "" && c.SelfHostname == "" {
+		c.SelfHostname, _ = os.Hostname()
+	}
+}
+
+func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
+	cfg, err := ConfigurationFromYAML(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	s.config = cfg
+
+	return nil
+}
+
+func (c *Configuration) Validate() error {
+	if c.ListenAddr == "" {
+		return errors.New("listen_addr is required")
+	}
+
+	if c.Mode != configuration.TAIL_MODE {
+		return fmt.Errorf("unsupported mode %s for %s datasource", c.Mode, ModuleName)
+	}
+
+	if c.MaxChunkSize < 0 {
+		return errors.New("max_chunk_size must be positive")
+	}
+
+	switch c.RawFormat {
+	case RawFormatMessage, RawFormatJSON:
+	default:
+		return fmt.Errorf("invalid raw_format '%s': must be one of %s, %s", c.RawFormat, RawFormatMessage, RawFormatJSON)
+	}
+
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for idx, r := range c.Routes {
+		if r.Tag == "" {
+			return fmt.Errorf("route %d: tag is required", idx+1)
+		}
+
+		if len(r.Labels) == 0 {
+			return fmt.Errorf("route %d: labels are required", idx+1)
+		}
+	}
+
+	return nil
+}
+
+// compileTagPattern converts a fluentd tag pattern to a regexp: "*" matches a part of
+// the tag, "**" zero or more parts. For example, "app.**" matches "app" and "app.nginx.access".
+func compileTagPattern(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	parts := strings.Split(pattern, ".")
+
+	// whether the next part must be preceded by a dot
+	sep := false
+
+	for idx, part := range parts {
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("invalid tag pattern '%s': empty part", pattern)
+		case part == "**" && idx == len(parts)-1 && sep:
+			sb.WriteString(`(?:\..*)?`)
+			continue
+		case part == "**" && idx == len(parts)-1:
+			sb.WriteString(`.*`)
+			continue
+		case part == "**" && sep:
+			sb.WriteString(`\.(?:.*\.)?`)
+			sep = false
+
+			continue
+		case part == "**":
+			sb.WriteString(`(?:.*\.)?`)
+			continue
+		case strings.Contains(part, "**"):
+			return nil, fmt.Errorf("invalid tag pattern '%s': ** must be a whole part", pattern)
+		}
+
+		if sep {
+			sb.WriteString(`\.`)
+		}
+
+		for i, lit := range strings.Split(part, "*") {
+			if i > 0 {
+				sb.WriteString(`[^.]*`)
+			}
+
+			sb.WriteString(regexp.QuoteMeta(lit))
+		}
+
+		sep = true
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+func (c *Configuration) compileRoutes() ([]route, error) {
+	routes := make([]route, 0, len(c.Routes))
+
+	for idx, r := range c.Routes {
+		re, err := compileTagPattern(r.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("route %d: %w", idx+1, err)
+		}
+
+		routes = append(routes, route{tag: re, labels: r.Labels})
+	}
+
+	return routes, nil
+}
+
+func (s *Source) Configure(_ context.Context, yamlConfig []byte, logger *log.Entry, metricsLevel metrics.AcquisitionMetricsLevel) error {
+	s.logger = logger
+	s.metricsLevel = metricsLevel
+
+	err := s.UnmarshalConfig(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	s.routes, err = s.config.compileRoutes()
+	if err != nil {
+		return err
+	}
+
+	if s.config.TLS != nil {
+		s.tlsConfig, err = s.config.TLS.NewTLSConfig()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
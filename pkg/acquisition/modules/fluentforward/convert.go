@@ -0,0 +1,83 @@
+package fluentforwardacquisition
+
+import (
+	"context"
+	"maps"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/structured"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// raw is the content of Line.Raw: the message field of the record or, with raw_format: json
+// or if there is no such field, the whole record.
+func (s *Source) raw(record map[string]any) (string, error) {
+	if s.config.RawFormat == RawFormatMessage {
+		if v, ok := record[s.config.MessageKey]; ok {
+			return structured.String(v), nil
+		}
+	}
+
+	return structured.JSON(record)
+}
+
+// labels computes the labels of an event: the ones of the configuration, then record_labels
+// and the tag, then the first route matching the tag.
+func (s *Source) labels(tag string, record map[string]any) map[string]string {
+	if s.config.TagLabel == "" && len(s.config.RecordLabels) == 0 && len(s.routes) == 0 {
+		return s.config.Labels
+	}
+
+	labels := structured.Labels(s.config.Labels, s.config.RecordLabels, record)
+
+	if s.config.TagLabel != "" {
+		labels[s.config.TagLabel] = tag
+	}
+
+	for _, rt := range s.routes {
+		if rt.tag.MatchString(tag) {
+			maps.Copy(labels, rt.labels)
+			break
+		}
+	}
+
+	return labels
+}
+
+// sendEvents sends the events of a message to the pipeline. src is the address of the forwarder.
+// It returns false if the datasource is stopping.
+func (s *Source) sendEvents(ctx context.Context, msg *message, src string) bool {
+	for _, e := range msg.entries {
+		raw, err := s.raw(e.record)
+		if err != nil {
+			s.logger.Errorf("unable to serialize record with tag %s: %s", msg.tag, err)
+			continue
+		}
+
+		labels := s.labels(msg.tag, e.record)
+
+		if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+			metrics.FluentForwardDataSourceLinesRead.With(prometheus.Labels{"source": s.config.ListenAddr, "datasource_type": ModuleName, "acquis_type": labels["type"]}).Inc()
+		}
+
+		evt := pipeline.MakeEvent(s.config.UseTimeMachine, pipeline.LOG, true)
+		evt.Line = pipeline.Line{
+			Raw:     raw,
+			Labels:  labels,
+			Time:    e.time,
+			Src:     src,
+			Process: true,
+			Module:  s.GetName(),
+		}
+
+		select {
+		case s.outChan <- evt:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
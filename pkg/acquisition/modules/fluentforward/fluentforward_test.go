@@ -0,0 +1,538 @@
+package fluentforwardacquisition
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/acquisitiontest"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 600, time.UTC)
+
+// eventTime encodes a timestamp as the EventTime extension.
+func eventTime(ts time.Time) msgpack.RawMessage {
+	buf := []byte{0xd7, eventTimeExt}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(ts.Unix()))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(ts.Nanosecond()))
+
+	return buf
+}
+
+func encode(t *testing.T, values ...any) []byte {
+	t.Helper()
+
+	buf := bytes.Buffer{}
+	enc := msgpack.NewEncoder(&buf)
+
+	for _, v := range values {
+		require.NoError(t, enc.Encode(v))
+	}
+
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+
+	_, err := gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func configureSource(t *testing.T, config string) (*Source, chan pipeline.Event) {
+	t.Helper()
+
+	s := &Source{}
+	acquisitiontest.Configure(t, s, config)
+
+	out := make(chan pipeline.Event, 10)
+	s.outChan = out
+
+	return s, out
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		config      string
+		expectedErr string
+	}{
+		{
+			config: `
+source: fluentforward`,
+			expectedErr: "listen_addr is required",
+		},
+		{
+			config: `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+mode: cat`,
+			expectedErr: "unsupported mode cat for fluentforward datasource",
+		},
+		{
+			config: `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+raw_format: xml`,
+			expectedErr: "invalid raw_format 'xml': must be one of message, json",
+		},
+		{
+			config: `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+routes:
+  - tag: nginx.*`,
+			expectedErr: "route 1: labels are required",
+		},
+		{
+			config: `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+routes:
+  - tag: nginx..access
+    labels:
+      type: nginx`,
+			expectedErr: "route 1: invalid tag pattern 'nginx..access': empty part",
+		},
+		{
+			config: `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+tls:
+  server_cert: /does/not/exist
+  server_key: /does/not/exist`,
+			expectedErr: "failed to load server cert/key: open /does/not/exist: " + cstest.FileNotFoundMessage,
+		},
+		{
+			config: `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+shared_key: secret
+tag_label: tag
+record_labels:
+  container_name: container
+routes:
+  - tag: "**.nginx"
+    labels:
+      type: nginx`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expectedErr, func(t *testing.T) {
+			s := Source{}
+			err := s.Configure(t.Context(), []byte(test.config), log.WithField("type", ModuleName), metrics.AcquisitionMetricsLevelNone)
+			cstest.RequireErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestCompileTagPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		match    []string
+		mismatch []string
+	}{
+		{pattern: "nginx", match: []string{"nginx"}, mismatch: []string{"nginx.access", "apache"}},
+		{pattern: "nginx.*", match: []string{"nginx.access", "nginx.error"}, mismatch: []string{"nginx", "nginx.access.log"}},
+		{pattern: "*.access", match: []string{"nginx.access"}, mismatch: []string{"access", "kube.nginx.access"}},
+		{pattern: "kube.nginx-*", match: []string{"kube.nginx-1"}, mismatch: []string{"kube.apache-1", "kube.nginx-1.x"}},
+		{pattern: "app.**", match: []string{"app", "app.nginx", "app.nginx.access"}, mismatch: []string{"application", "web.app"}},
+		{pattern: "**.access", match: []string{"access", "nginx.access", "kube.nginx.access"}, mismatch: []string{"nginx.access.log", "noaccess"}},
+		{pattern: "kube.**.access", match: []string{"kube.access", "kube.nginx.access", "kube.ns.nginx.access"}, mismatch: []string{"kube.nginx", "kubeaccess"}},
+		{pattern: "**", match: []string{"nginx", "nginx.access"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			re, err := compileTagPattern(test.pattern)
+			require.NoError(t, err)
+
+			for _, tag := range test.match {
+				assert.True(t, re.MatchString(tag), "%s should match %s", test.pattern, tag)
+			}
+
+			for _, tag := range test.mismatch {
+				assert.False(t, re.MatchString(tag), "%s should not match %s", test.pattern, tag)
+			}
+		})
+	}
+}
+
+func TestDecodeMessage(t *testing.T) {
+	record := map[string]any{"log": "line 1"}
+	entries := encode(t, []any{testTime.Unix(), record}, []any{eventTime(testTime), map[string]any{"log": "line 2"}})
+	large := encode(t, []any{testTime.Unix(), map[string]any{"log": strings.Repeat("a", 512)}})
+
+	tests := []struct {
+		name        string
+		data        []byte
+		expected    []string
+		chunk       string
+		expectedErr string
+	}{
+		{
+			name:     "message",
+			data:     encode(t, []any{"nginx", eventTime(testTime), record}),
+			expected: []string{"line 1"},
+		},
+		{
+			name:     "message with option",
+			data:     encode(t, []any{"nginx", testTime.Unix(), record, map[string]any{"chunk": "abc"}}),
+			expected: []string{"line 1"},
+			chunk:    "abc",
+		},
+		{
+			name:     "forward",
+			data:     encode(t, []any{"nginx", []any{[]any{testTime.Unix(), record}, []any{eventTime(testTime), map[string]any{"log": "line 2"}}}, map[string]any{"chunk": "abc"}}),
+			expected: []string{"line 1", "line 2"},
+			chunk:    "abc",
+		},
+		{
+			name:     "forward with metadata",
+			data:     encode(t, []any{"nginx", []any{[]any{[]any{eventTime(testTime), map[string]any{"k": "v"}}, record}}}),
+			expected: []string{"line 1"},
+		},
+		{
+			name:     "packed forward",
+			data:     encode(t, []any{"nginx", entries}),
+			expected: []string{"line 1", "line 2"},
+		},
+		{
+			name:     "compressed packed forward",
+			data:     encode(t, []any{"nginx", gzipped(t, entries), map[string]any{"compressed": "gzip", "chunk": "abc"}}),
+			expected: []string{"line 1", "line 2"},
+			chunk:    "abc",
+		},
+		{
+			name:        "unknown compression",
+			data:        encode(t, []any{"nginx", entries, map[string]any{"compressed": "zstd"}}),
+			expectedErr: "unsupported compression 'zstd'",
+		},
+		{
+			name:        "too many elements",
+			data:        encode(t, []any{"nginx", testTime.Unix(), record, map[string]any{}, "extra"}),
+			expectedErr: "invalid message: unexpected 5 elements",
+		},
+		{
+			name:        "missing record",
+			data:        encode(t, []any{"nginx", testTime.Unix()}),
+			expectedErr: "invalid message: missing record",
+		},
+		{
+			name:        "invalid entry",
+			data:        encode(t, []any{"nginx", []any{[]any{testTime.Unix()}}}),
+			expectedErr: "invalid entry: expected 2 elements, got 1",
+		},
+		{
+			name:        "invalid time",
+			data:        encode(t, []any{"nginx", true, record}),
+			expectedErr: "invalid time: msgpack: invalid code=c3 decoding int64",
+		},
+		{
+			name:        "packed forward too large",
+			data:        encode(t, []any{"nginx", large}),
+			expectedErr: "entries exceed max_chunk_size (256 bytes)",
+		},
+		{
+			name:        "compressed packed forward too large",
+			data:        encode(t, []any{"nginx", gzipped(t, large), map[string]any{"compressed": "gzip"}}),
+			expectedErr: "uncompressed entries exceed max_chunk_size (256 bytes)",
+		},
+	}
+
+	s, _ := configureSource(t, `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+max_chunk_size: 256`)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg, err := s.decodeMessage(newDecoder(bytes.NewReader(test.data)))
+			cstest.RequireErrorContains(t, err, test.expectedErr)
+
+			if test.expectedErr != "" {
+				return
+			}
+
+			assert.Equal(t, "nginx", msg.tag)
+			assert.Equal(t, test.chunk, msg.chunk())
+
+			raw := []string{}
+
+			for _, e := range msg.entries {
+				raw = append(raw, e.record["log"].(string))
+			}
+
+			assert.Equal(t, test.expected, raw)
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	s, _ := configureSource(t, `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+labels:
+  type: syslog
+tag_label: tag
+record_labels:
+  container_name: container
+routes:
+  - tag: kube.**.nginx
+    labels:
+      type: nginx
+  - tag: kube.**
+    labels:
+      type: kube`)
+
+	tests := []struct {
+		tag      string
+		record   map[string]any
+		expected map[string]string
+	}{
+		{
+			tag:      "kube.ingress.nginx",
+			record:   map[string]any{"log": "line", "container_name": "ingress"},
+			expected: map[string]string{"type": "nginx", "tag": "kube.ingress.nginx", "container": "ingress"},
+		},
+		{
+			tag:      "kube.sshd",
+			record:   map[string]any{"log": "line"},
+			expected: map[string]string{"type": "kube", "tag": "kube.sshd"},
+		},
+		{
+			tag:      "host.syslog",
+			record:   map[string]any{"log": "line", "container_name": int64(42)},
+			expected: map[string]string{"type": "syslog", "tag": "host.syslog", "container": "42"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			assert.Equal(t, test.expected, s.labels(test.tag, test.record))
+		})
+	}
+
+	// the configuration is not modified
+	assert.Equal(t, map[string]string{"type": "syslog"}, s.config.Labels)
+}
+
+func TestRaw(t *testing.T) {
+	record := map[string]any{"log": "line 1", "stream": "stdout", "kubernetes": map[string]any{"pod_name": "nginx-1"}}
+
+	tests := []struct {
+		config   string
+		record   map[string]any
+		expected string
+	}{
+		{
+			config:   "raw_format: message",
+			record:   record,
+			expected: "line 1",
+		},
+		{
+			config:   "message_key: stream",
+			record:   record,
+			expected: "stdout",
+		},
+		{
+			config:   "raw_format: message",
+			record:   map[string]any{"message": "line 1"},
+			expected: `{"message":"line 1"}`,
+		},
+		{
+			config:   "raw_format: json",
+			record:   record,
+			expected: `{"kubernetes":{"pod_name":"nginx-1"},"log":"line 1","stream":"stdout"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.config, func(t *testing.T) {
+			s, _ := configureSource(t, `
+source: fluentforward
+listen_addr: 127.0.0.1:24224
+labels:
+  type: syslog
+`+test.config)
+
+			raw, err := s.raw(test.record)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, raw)
+		})
+	}
+}
+
+// forwarder is a minimal fluent forward client.
+type forwarder struct {
+	conn net.Conn
+	dec  *msgpack.Decoder
+}
+
+func dialForwarder(t *testing.T, addr string) *forwarder {
+	t.Helper()
+
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", addr)
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &forwarder{conn: conn, dec: msgpack.NewDecoder(bufio.NewReader(conn))}
+}
+
+func (f *forwarder) send(t *testing.T, v any) {
+	t.Helper()
+
+	_, err := f.conn.Write(encode(t, v))
+	require.NoError(t, err)
+}
+
+func (f *forwarder) receive(t *testing.T) any {
+	t.Helper()
+
+	require.NoError(t, f.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	v, err := f.dec.DecodeInterface()
+	require.NoError(t, err)
+
+	return v
+}
+
+func startSource(t *testing.T, config string) chan pipeline.Event {
+	t.Helper()
+
+	s, out := configureSource(t, config)
+	cancel, done := acquisitiontest.StartStream(t, s, out)
+
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return out
+}
+
+func TestStreamingAcquisition(t *testing.T) {
+	out := startSource(t, `
+source: fluentforward
+listen_addr: 127.0.0.1:49324
+labels:
+  type: syslog
+tag_label: tag`)
+
+	f := dialForwarder(t, "127.0.0.1:49324")
+
+	f.send(t, []any{"nginx.access", []any{
+		[]any{eventTime(testTime), map[string]any{"log": "line 1"}},
+		[]any{eventTime(testTime), map[string]any{"log": "line 2"}},
+	}, map[string]any{"chunk": "chunk-1"}})
+
+	evt := <-out
+	assert.Equal(t, "line 1", evt.Line.Raw)
+	assert.Equal(t, testTime, evt.Line.Time)
+	assert.Equal(t, "127.0.0.1", evt.Line.Src)
+	assert.Equal(t, map[string]string{"type": "syslog", "tag": "nginx.access"}, evt.Line.Labels)
+	assert.Equal(t, ModuleName, evt.Line.Module)
+
+	evt = <-out
+	assert.Equal(t, "line 2", evt.Line.Raw)
+
+	// the ack is sent once the events are in the pipeline
+	assert.Equal(t, map[string]any{"ack": "chunk-1"}, f.receive(t))
+
+	// the connection is kept open for the next messages
+	f.send(t, []any{"nginx.error", testTime.Unix(), map[string]any{"log": "line 3"}})
+
+	evt = <-out
+	assert.Equal(t, "line 3", evt.Line.Raw)
+	assert.Equal(t, "nginx.error", evt.Line.Labels["tag"])
+}
+
+func TestSharedKey(t *testing.T) {
+	startSource(t, `
+source: fluentforward
+listen_addr: 127.0.0.1:49325
+shared_key: secret
+self_hostname: crowdsec
+labels:
+  type: syslog`)
+
+	// ping answers the HELO of the server, and returns the nonce
+	ping := func(t *testing.T, f *forwarder, key string) string {
+		helo, ok := f.receive(t).([]any)
+		require.True(t, ok)
+		require.Equal(t, "HELO", helo[0])
+
+		nonce := string(helo[1].(map[string]any)["nonce"].([]byte))
+
+		f.send(t, []any{"PING", "fluent-bit", "salt", sha512Hex("salt", "fluent-bit", nonce, key), "", ""})
+
+		return nonce
+	}
+
+	t.Run("valid key", func(t *testing.T) {
+		f := dialForwarder(t, "127.0.0.1:49325")
+		nonce := ping(t, f, "secret")
+
+		assert.Equal(t, []any{"PONG", true, "", "crowdsec", sha512Hex("salt", "crowdsec", nonce, "secret")}, f.receive(t))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		f := dialForwarder(t, "127.0.0.1:49325")
+		ping(t, f, "wrong")
+
+		assert.Equal(t, []any{"PONG", false, "shared_key mismatch", "", ""}, f.receive(t))
+
+		// the server closes the connection
+		_, err := f.dec.DecodeInterface()
+		require.Error(t, err)
+	})
+}
+
+func TestStreamListenError(t *testing.T) {
+	ctx := t.Context()
+
+	listenConfig := &net.ListenConfig{}
+
+	// the address is already in use, the error is returned so the caller can restart the datasource
+	listener, err := listenConfig.Listen(ctx, "tcp", "127.0.0.1:49326")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	s, out := configureSource(t, `
+source: fluentforward
+listen_addr: 127.0.0.1:49326
+labels:
+  type: syslog`)
+
+	err = s.Stream(ctx, out)
+	require.ErrorContains(t, err, "cannot listen on 127.0.0.1:49326")
+}
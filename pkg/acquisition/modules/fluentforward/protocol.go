@@ -0,0 +1,407 @@
+package fluentforwardacquisition
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// eventTimeExt is the msgpack extension used by the forwarders for timestamps with nanoseconds.
+const eventTimeExt = 0
+
+// entry is an event of a forward message.
+type entry struct {
+	time   time.Time
+	record map[string]any
+}
+
+// message is a forward message, in any of the three modes:
+//
+//	Message:       [tag, time, record, option?]
+//	Forward:       [tag, [[time, record], ...], option?]
+//	PackedForward: [tag, bin(msgpack stream of [time, record]), option?]
+type message struct {
+	tag     string
+	entries []entry
+	options map[string]any
+}
+
+// chunk is the id to send back in the ack, if the forwarder requested one.
+func (m *message) chunk() string {
+	chunk, _ := m.options["chunk"].(string)
+	return chunk
+}
+
+// limitedReader limits the size of a message. Unlike io.LimitedReader, it returns an error
+// instead of io.EOF, so a truncated message is not mistaken for a closed connection.
+type limitedReader struct {
+	r   io.Reader
+	max int64
+	n   int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, fmt.Errorf("message exceeds max_chunk_size (%d bytes)", l.max)
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
+
+// reset gives a full budget for the next message.
+func (l *limitedReader) reset() {
+	l.n = l.max
+}
+
+func newDecoder(r io.Reader) *msgpack.Decoder {
+	dec := msgpack.NewDecoder(r)
+	// integers as int64/uint64, and bin values as strings
+	dec.UseLooseInterfaceDecoding(true)
+
+	return dec
+}
+
+func isArray(c byte) bool {
+	return msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32
+}
+
+func isBytes(c byte) bool {
+	return msgpcode.IsString(c) || msgpcode.IsBin(c)
+}
+
+// decodeTime decodes an EventTime, or a timestamp in seconds.
+func decodeTime(dec *msgpack.Decoder) (time.Time, error) {
+	c, err := dec.PeekCode()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch {
+	case msgpcode.IsExt(c):
+		extID, extLen, err := dec.DecodeExtHeader()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if extID != eventTimeExt || extLen != 8 {
+			return time.Time{}, fmt.Errorf("unsupported time extension %d of length %d", extID, extLen)
+		}
+
+		buf := make([]byte, extLen)
+		if err := dec.ReadFull(buf); err != nil {
+			return time.Time{}, err
+		}
+
+		sec := binary.BigEndian.Uint32(buf[:4])
+		nsec := binary.BigEndian.Uint32(buf[4:])
+
+		return time.Unix(int64(sec), int64(nsec)).UTC(), nil
+	case c == msgpcode.Float || c == msgpcode.Double:
+		ts, err := dec.DecodeFloat64()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return time.Unix(0, int64(ts*float64(time.Second))).UTC(), nil
+	default:
+		ts, err := dec.DecodeInt64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time: %w", err)
+		}
+
+		return time.Unix(ts, 0).UTC(), nil
+	}
+}
+
+// decodeEntryFields decodes the time and the record of an event.
+func decodeEntryFields(dec *msgpack.Decoder) (entry, error) {
+	ts, err := decodeTime(dec)
+	if err != nil {
+		return entry{}, err
+	}
+
+	record, err := dec.DecodeMap()
+	if err != nil {
+		return entry{}, fmt.Errorf("invalid record: %w", err)
+	}
+
+	return entry{time: ts, record: record}, nil
+}
+
+// decodeEntry decodes a [time, record] pair. Fluent Bit can send the time as [time, metadata].
+func decodeEntry(dec *msgpack.Decoder) (entry, error) {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return entry{}, err
+	}
+
+	if n != 2 {
+		return entry{}, fmt.Errorf("invalid entry: expected 2 elements, got %d", n)
+	}
+
+	c, err := dec.PeekCode()
+	if err != nil {
+		return entry{}, err
+	}
+
+	if !isArray(c) {
+		return decodeEntryFields(dec)
+	}
+
+	header, err := dec.DecodeArrayLen()
+	if err != nil {
+		return entry{}, err
+	}
+
+	if header < 1 {
+		return entry{}, errors.New("invalid entry: empty header")
+	}
+
+	ts, err := decodeTime(dec)
+	if err != nil {
+		return entry{}, err
+	}
+
+	// the metadata is not used
+	for range header - 1 {
+		if err := dec.Skip(); err != nil {
+			return entry{}, err
+		}
+	}
+
+	record, err := dec.DecodeMap()
+	if err != nil {
+		return entry{}, fmt.Errorf("invalid record: %w", err)
+	}
+
+	return entry{time: ts, record: record}, nil
+}
+
+// decodePacked decodes the entries of a PackedForward message, compressed or not.
+func (s *Source) decodePacked(payload []byte, compressed string) ([]entry, error) {
+	switch compressed {
+	case "", "text":
+	case "gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gz.Close()
+
+		// the limit applies to the uncompressed entries too
+		payload, err = io.ReadAll(io.LimitReader(gz, s.config.MaxChunkSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress: %w", err)
+		}
+
+		if int64(len(payload)) > s.config.MaxChunkSize {
+			return nil, fmt.Errorf("uncompressed entries exceed max_chunk_size (%d bytes)", s.config.MaxChunkSize)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression '%s'", compressed)
+	}
+
+	reader := bytes.NewReader(payload)
+	dec := newDecoder(reader)
+	entries := []entry{}
+
+	for reader.Len() > 0 {
+		e, err := decodeEntry(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// decodeMessage reads the next message of a connection. It returns io.EOF if the forwarder
+// closed the connection between two messages.
+func (s *Source) decodeMessage(dec *msgpack.Decoder) (*message, error) {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 2 || n > 4 {
+		return nil, fmt.Errorf("invalid message: unexpected %d elements", n)
+	}
+
+	tag, err := dec.DecodeString()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag: %w", err)
+	}
+
+	c, err := dec.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &message{tag: tag}
+
+	var (
+		packed []byte
+		// number of elements left for the options
+		rest = n - 2
+	)
+
+	switch {
+	case isArray(c):
+		count, err := dec.DecodeArrayLen()
+		if err != nil {
+			return nil, err
+		}
+
+		for range count {
+			e, err := decodeEntry(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			msg.entries = append(msg.entries, e)
+		}
+	case isBytes(c):
+		size, err := dec.DecodeBytesLen()
+		if err != nil {
+			return nil, err
+		}
+
+		if int64(size) > s.config.MaxChunkSize {
+			return nil, fmt.Errorf("entries exceed max_chunk_size (%d bytes)", s.config.MaxChunkSize)
+		}
+
+		packed = make([]byte, max(size, 0))
+		if err := dec.ReadFull(packed); err != nil {
+			return nil, err
+		}
+	default:
+		if n < 3 {
+			return nil, errors.New("invalid message: missing record")
+		}
+
+		e, err := decodeEntryFields(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		msg.entries = append(msg.entries, e)
+		rest--
+	}
+
+	if rest == 1 {
+		if msg.options, err = dec.DecodeMap(); err != nil {
+			return nil, fmt.Errorf("invalid options: %w", err)
+		}
+	} else if rest > 1 {
+		return nil, fmt.Errorf("invalid message: unexpected %d elements", n)
+	}
+
+	if packed != nil {
+		compressed, _ := msg.options["compressed"].(string)
+
+		if msg.entries, err = s.decodePacked(packed, compressed); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+func sha512Hex(values ...string) string {
+	h := sha512.New()
+
+	for _, v := range values {
+		h.Write([]byte(v))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// reply sends a message to the forwarder.
+func reply(enc *msgpack.Encoder, w *bufio.Writer, v any) error {
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// handshake authenticates the forwarder with the shared key: the server sends HELO with a nonce,
+// the forwarder answers PING with a digest of the key and the nonce, then the server sends PONG
+// with its own digest, so the forwarder can authenticate the server too.
+func (s *Source) handshake(dec *msgpack.Decoder, enc *msgpack.Encoder, w *bufio.Writer) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("cannot generate nonce: %w", err)
+	}
+
+	// user authentication is not supported, so there is no auth salt
+	helo := []any{"HELO", map[string]any{"nonce": nonce, "auth": "", "keepalive": true}}
+
+	if err := reply(enc, w, helo); err != nil {
+		return fmt.Errorf("cannot send HELO: %w", err)
+	}
+
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return fmt.Errorf("cannot read PING: %w", err)
+	}
+
+	if n != 6 {
+		return fmt.Errorf("invalid PING: expected 6 elements, got %d", n)
+	}
+
+	// PING, hostname, shared key salt, shared key digest, username, password digest
+	ping := make([]string, n)
+
+	for idx := range ping {
+		if ping[idx], err = dec.DecodeString(); err != nil {
+			return fmt.Errorf("invalid PING: %w", err)
+		}
+	}
+
+	if ping[0] != "PING" {
+		return fmt.Errorf("invalid PING: unexpected '%s'", ping[0])
+	}
+
+	hostname, salt, digest := ping[1], ping[2], ping[3]
+
+	expected := sha512Hex(salt, hostname, string(nonce), s.config.SharedKey)
+
+	if subtle.ConstantTimeCompare([]byte(digest), []byte(expected)) != 1 {
+		if err := reply(enc, w, []any{"PONG", false, "shared_key mismatch", "", ""}); err != nil {
+			return fmt.Errorf("cannot send PONG: %w", err)
+		}
+
+		return fmt.Errorf("shared_key mismatch for %s", hostname)
+	}
+
+	pong := []any{"PONG", true, "", s.config.SelfHostname, sha512Hex(salt, s.config.SelfHostname, string(nonce), s.config.SharedKey)}
+
+	if err := reply(enc, w, pong); err != nil {
+		return fmt.Errorf("cannot send PONG: %w", err)
+	}
+
+	return nil
+}
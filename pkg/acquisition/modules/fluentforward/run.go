@@ -0,0 +1,119 @@
+package fluentforwardacquisition
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/crowdsecurity/go-cs-lib/trace"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// handleConn reads the messages of a forwarder until it closes the connection. The acks,
+// if requested, are sent once the events of a message are in the pipeline.
+func (s *Source) handleConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	// unblock the reads when the datasource stops
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	logger := s.logger.WithField("remote", conn.RemoteAddr().String())
+	src, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+
+	reader := &limitedReader{r: conn, max: s.config.MaxChunkSize}
+	dec := newDecoder(bufio.NewReader(reader))
+	writer := bufio.NewWriter(conn)
+	enc := msgpack.NewEncoder(writer)
+
+	if s.config.SharedKey != "" {
+		reader.reset()
+
+		if err := s.handshake(dec, enc, writer); err != nil {
+			logger.Errorf("handshake failed: %s", err)
+			return
+		}
+	}
+
+	for {
+		reader.reset()
+
+		msg, err := s.decodeMessage(dec)
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+				logger.Errorf("cannot read message: %s", err)
+			}
+
+			return
+		}
+
+		logger.Tracef("received %d events with tag %s", len(msg.entries), msg.tag)
+
+		if !s.sendEvents(ctx, msg, src) {
+			return
+		}
+
+		if chunk := msg.chunk(); chunk != "" {
+			if err := reply(enc, writer, map[string]any{"ack": chunk}); err != nil {
+				logger.Errorf("cannot send ack: %s", err)
+				return
+			}
+		}
+	}
+}
+
+func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
+	s.outChan = out
+
+	listenConfig := &net.ListenConfig{}
+
+	listener, err := listenConfig.Listen(ctx, "tcp", s.config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", s.config.ListenAddr, err)
+	}
+
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	// the connections are tracked to wait for them when stopping
+	conns := sync.WaitGroup{}
+
+	defer func() {
+		cancel()
+		conns.Wait()
+	}()
+
+	// unblock Accept when the datasource stops, or when the server fails
+	context.AfterFunc(ctx, func() { _ = listener.Close() })
+
+	s.logger.Infof("start fluent forward server on %s", s.config.ListenAddr)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				s.logger.Infof("%s datasource stopping", s.GetName())
+				return nil
+			}
+
+			return fmt.Errorf("fluent forward server failed: %w", err)
+		}
+
+		conns.Go(func() {
+			defer trace.ReportPanic()
+
+			s.handleConn(ctx, conn)
+		})
+	}
+}
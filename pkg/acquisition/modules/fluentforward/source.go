@@ -0,0 +1,39 @@
+package fluentforwardacquisition
+
+import (
+	"crypto/tls"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+type Source struct {
+	metricsLevel metrics.AcquisitionMetricsLevel
+	config       Configuration
+	logger       *log.Entry
+	routes       []route
+	tlsConfig    *tls.Config
+	outChan      chan pipeline.Event
+}
+
+func (s *Source) GetUuid() string {
+	return s.config.UniqueId
+}
+
+func (s *Source) GetMode() string {
+	return s.config.Mode
+}
+
+func (*Source) GetName() string {
+	return ModuleName
+}
+
+func (*Source) CanRun() error {
+	return nil
+}
+
+func (s *Source) Dump() any {
+	return s
+}
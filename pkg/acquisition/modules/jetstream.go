@@ -0,0 +1,5 @@
+//go:build !no_datasource_jetstream
+
+package modules
+
+import _ "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/jetstream" // register the datasource
@@ -0,0 +1,218 @@
+package jetstreamacquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+const (
+	DeliverNew = "new"
+	DeliverAll = "all"
+
+	defaultTimeout = 10 * time.Second
+	defaultAckWait = 30 * time.Second
+)
+
+type Configuration struct {
+	Servers                           []string                       `yaml:"servers"`
+	Stream                            string                         `yaml:"stream"`
+	Consumer                          string                         `yaml:"consumer"`
+	Subjects                          []string                       `yaml:"subjects"`
+	DeliverPolicy                     string                         `yaml:"deliver_policy"`
+	AckWait                           time.Duration                  `yaml:"ack_wait"`
+	MaxAckPending                     int                            `yaml:"max_ack_pending"`
+	Timeout                           time.Duration                  `yaml:"timeout"`
+	Auth                              *AuthConfig                    `yaml:"auth"`
+	TLS                               *configuration.ClientTLSConfig `yaml:"tls"`
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+}
+
+type AuthConfig struct {
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+	Token     string `yaml:"token"`
+	CredsFile string `yaml:"creds_file"`
+}
+
+func ConfigurationFromYAML(y []byte) (Configuration, error) {
+	var cfg Configuration
+
+	if err := yaml.UnmarshalWithOptions(y, &cfg, yaml.Strict()); err != nil {
+		return cfg, fmt.Errorf("cannot parse: %s", yaml.FormatError(err, false, false))
+	}
+
+	cfg.SetDefaults()
+
+	err := cfg.Validate()
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Configuration) SetDefaults() {
+	if c.Mode == "" {
+		c.Mode = configuration.TAIL_MODE
+	}
+
+	if c.DeliverPolicy == "" {
+		c.DeliverPolicy = DeliverNew
+	}
+
+	if c.AckWait == 0 {
+		c.AckWait = defaultAckWait
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+}
+
+func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
+	cfg, err := ConfigurationFromYAML(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	s.config = cfg
+
+	return nil
+}
+
+func (c *Configuration) Validate() error {
+	if len(c.Servers) == 0 {
+		return errors.New("servers is required")
+	}
+
+	if c.Stream == "" {
+		return errors.New("stream is required")
+	}
+
+	// the consumer is durable, so the messages that have not been acked are delivered again after a restart
+	if c.Consumer == "" {
+		return errors.New("consumer is required")
+	}
+
+	if c.Mode != configuration.TAIL_MODE {
+		return fmt.Errorf("unsupported mode %s for %s datasource", c.Mode, ModuleName)
+	}
+
+	switch c.DeliverPolicy {
+	case DeliverNew, DeliverAll:
+	default:
+		return fmt.Errorf("invalid deliver_policy '%s': must be one of %s, %s", c.DeliverPolicy, DeliverNew, DeliverAll)
+	}
+
+	if c.AckWait < 0 {
+		return errors.New("ack_wait must be positive")
+	}
+
+	if c.MaxAckPending < 0 {
+		return errors.New("max_ack_pending must be positive")
+	}
+
+	if c.Auth != nil {
+		methods := 0
+
+		if c.Auth.Username != "" || c.Auth.Password != "" {
+			methods++
+		}
+
+		if c.Auth.Token != "" {
+			methods++
+		}
+
+		if c.Auth.CredsFile != "" {
+			methods++
+		}
+
+		if methods > 1 {
+			return errors.New("auth: only one of username/password, token or creds_file can be used")
+		}
+	}
+
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// connectOptions returns the options of the nats connection. Reconnections are
+// disabled: Stream() fails, and is restarted by the caller.
+func (c *Configuration) connectOptions() ([]nats.Option, error) {
+	opts := []nats.Option{
+		nats.Name("crowdsec"),
+		nats.Timeout(c.Timeout),
+		nats.NoReconnect(),
+	}
+
+	if c.Auth != nil {
+		switch {
+		case c.Auth.CredsFile != "":
+			opts = append(opts, nats.UserCredentials(c.Auth.CredsFile))
+		case c.Auth.Token != "":
+			opts = append(opts, nats.Token(c.Auth.Token))
+		case c.Auth.Username != "":
+			opts = append(opts, nats.UserInfo(c.Auth.Username, c.Auth.Password))
+		}
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.NewTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+
+	return opts, nil
+}
+
+func (c *Configuration) consumerConfig() jetstream.ConsumerConfig {
+	cfg := jetstream.ConsumerConfig{
+		Durable:        c.Consumer,
+		FilterSubjects: c.Subjects,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        c.AckWait,
+		MaxAckPending:  c.MaxAckPending,
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+	}
+
+	if c.DeliverPolicy == DeliverAll {
+		cfg.DeliverPolicy = jetstream.DeliverAllPolicy
+	}
+
+	return cfg
+}
+
+func (s *Source) Configure(_ context.Context, yamlConfig []byte, logger *log.Entry, metricsLevel metrics.AcquisitionMetricsLevel) error {
+	s.logger = logger
+	s.metricsLevel = metricsLevel
+
+	err := s.UnmarshalConfig(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	// load the certificates now, to report errors early
+	if _, err := s.config.connectOptions(); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,333 @@
+package jetstreamacquisition
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/acquisitiontest"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func TestConfigure(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		config      string
+		expectedErr string
+	}{
+		{
+			config: `
+source: jetstream
+stream: LOGS
+consumer: crowdsec`,
+			expectedErr: "servers is required",
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+consumer: crowdsec`,
+			expectedErr: "stream is required",
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+stream: LOGS`,
+			expectedErr: "consumer is required",
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+stream: LOGS
+consumer: crowdsec
+mode: cat`,
+			expectedErr: "unsupported mode cat for jetstream datasource",
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+stream: LOGS
+consumer: crowdsec
+deliver_policy: last`,
+			expectedErr: "invalid deliver_policy 'last': must be one of new, all",
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+stream: LOGS
+consumer: crowdsec
+auth:
+  token: secret
+  username: user`,
+			expectedErr: "auth: only one of username/password, token or creds_file can be used",
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+stream: LOGS
+consumer: crowdsec
+tls:
+  client_cert: cert.pem`,
+			expectedErr: "tls.client_cert and tls.client_key must be provided together",
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+stream: LOGS
+consumer: crowdsec
+tls:
+  ca_cert: /does/not/exist`,
+			expectedErr: "failed to read ca cert: open /does/not/exist: " + cstest.FileNotFoundMessage,
+		},
+		{
+			config: `
+source: jetstream
+servers: [nats://127.0.0.1:4222]
+stream: LOGS
+consumer: crowdsec
+subjects: [logs.>]
+deliver_policy: all
+ack_wait: 1m
+max_ack_pending: 100`,
+		},
+	}
+
+	subLogger := log.WithField("type", ModuleName)
+
+	for _, test := range tests {
+		t.Run(test.expectedErr, func(t *testing.T) {
+			s := Source{}
+			err := s.Configure(ctx, []byte(test.config), subLogger, metrics.AcquisitionMetricsLevelNone)
+			cstest.RequireErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
+
+// runServer starts an embedded nats server with jetstream, and creates a stream.
+func runServer(t *testing.T, stream string, subjects ...string) *server.Server {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	ns.Start()
+	t.Cleanup(ns.Shutdown)
+
+	require.True(t, ns.ReadyForConnections(5*time.Second), "nats server not ready")
+
+	nc, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	_, err = js.CreateStream(t.Context(), jetstream.StreamConfig{Name: stream, Subjects: subjects})
+	require.NoError(t, err)
+
+	return ns
+}
+
+func publish(t *testing.T, ns *server.Server, subject string, messages ...string) {
+	t.Helper()
+
+	nc, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	for _, msg := range messages {
+		_, err = js.Publish(t.Context(), subject, []byte(msg))
+		require.NoError(t, err)
+	}
+}
+
+func consumerInfo(t *testing.T, ns *server.Server, stream string, consumer string) *jetstream.ConsumerInfo {
+	t.Helper()
+
+	nc, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	cons, err := js.Consumer(t.Context(), stream, consumer)
+	require.NoError(t, err)
+
+	info, err := cons.Info(t.Context())
+	require.NoError(t, err)
+
+	return info
+}
+
+func waitConsumer(t *testing.T, ns *server.Server, stream string, consumer string) {
+	t.Helper()
+
+	nc, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := js.Consumer(t.Context(), stream, consumer)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestStream(t *testing.T) {
+	ns := runServer(t, "LOGS", "logs.>")
+
+	// published before the consumer exists
+	publish(t, ns, "logs.nginx", "old line")
+
+	s := Source{}
+	acquisitiontest.Configure(t, &s, fmt.Sprintf(`
+source: jetstream
+servers: [%s]
+stream: LOGS
+consumer: crowdsec
+subjects: [logs.nginx]
+labels:
+  type: nginx`, ns.ClientURL()))
+
+	out := make(chan pipeline.Event)
+	cancel, done := acquisitiontest.StartStream(t, &s, out)
+
+	// wait for the consumer, which only receives the new messages
+	waitConsumer(t, ns, "LOGS", "crowdsec")
+
+	publish(t, ns, "logs.sshd", "filtered out")
+	publish(t, ns, "logs.nginx", "line 1", "line 2")
+
+	for _, expected := range []string{"line 1", "line 2"} {
+		select {
+		case evt := <-out:
+			assert.Equal(t, expected, evt.Line.Raw)
+			assert.Equal(t, "logs.nginx", evt.Line.Src)
+			assert.Equal(t, "nginx", evt.Line.Labels["type"])
+			assert.Equal(t, ModuleName, evt.Line.Module)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %q", expected)
+		}
+	}
+
+	// both messages have been acked
+	require.Eventually(t, func() bool {
+		info := consumerInfo(t, ns, "LOGS", "crowdsec")
+		return info.AckFloor.Stream == 4 && info.NumAckPending == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestStreamRedelivery(t *testing.T) {
+	ns := runServer(t, "LOGS", "logs.>")
+
+	config := fmt.Sprintf(`
+source: jetstream
+servers: [%s]
+stream: LOGS
+consumer: crowdsec
+deliver_policy: all
+ack_wait: 1s
+labels:
+  type: nginx`, ns.ClientURL())
+
+	publish(t, ns, "logs.nginx", "line 1", "line 2")
+
+	s := Source{}
+	acquisitiontest.Configure(t, &s, config)
+
+	// nobody reads the output: the first message is not acked when the stream is stopped
+	cancel, done := acquisitiontest.StartStream(t, &s, make(chan pipeline.Event))
+
+	waitConsumer(t, ns, "LOGS", "crowdsec")
+
+	require.Eventually(t, func() bool {
+		return consumerInfo(t, ns, "LOGS", "crowdsec").NumAckPending > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// the durable consumer delivers the messages again, in any order
+	out := make(chan pipeline.Event)
+	cancel, done = acquisitiontest.StartStream(t, &s, out)
+
+	received := []string{}
+
+	for range 2 {
+		select {
+		case evt := <-out:
+			received = append(received, evt.Line.Raw)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout, received %q", received)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"line 1", "line 2"}, received)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestStreamServerShutdown(t *testing.T) {
+	ns := runServer(t, "LOGS", "logs.>")
+
+	s := Source{}
+	acquisitiontest.Configure(t, &s, fmt.Sprintf(`
+source: jetstream
+servers: [%s]
+stream: LOGS
+consumer: crowdsec
+labels:
+  type: nginx`, ns.ClientURL()))
+
+	_, done := acquisitiontest.StartStream(t, &s, make(chan pipeline.Event))
+
+	waitConsumer(t, ns, "LOGS", "crowdsec")
+
+	ns.Shutdown()
+
+	// the caller restarts the stream
+	select {
+	case err := <-done:
+		cstest.RequireErrorContains(t, err, "connection closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not return")
+	}
+}
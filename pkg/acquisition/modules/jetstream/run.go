@@ -0,0 +1,130 @@
+package jetstreamacquisition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
+	opts, err := s.config.connectOptions()
+	if err != nil {
+		return err
+	}
+
+	// the connection can be lost while waiting for messages
+	closed := make(chan struct{})
+
+	opts = append(opts, nats.ClosedHandler(func(_ *nats.Conn) {
+		close(closed)
+	}))
+
+	nc, err := nats.Connect(strings.Join(s.config.Servers, ","), opts...)
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", strings.Join(s.config.Servers, ","), err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return fmt.Errorf("cannot create jetstream context: %w", err)
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, s.config.Stream, s.config.consumerConfig())
+	if err != nil {
+		return fmt.Errorf("cannot create consumer %s on stream %s: %w", s.config.Consumer, s.config.Stream, err)
+	}
+
+	iter, err := consumer.Messages()
+	if err != nil {
+		return fmt.Errorf("cannot consume from stream %s: %w", s.config.Stream, err)
+	}
+
+	s.logger.Infof("consuming stream %s with consumer %s", s.config.Stream, s.config.Consumer)
+
+	// Next() blocks until a message is received or the iterator is stopped
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-closed:
+		case <-stopped:
+		}
+
+		iter.Stop()
+	}()
+
+	for {
+		msg, err := iter.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if nc.IsClosed() {
+				return fmt.Errorf("connection closed: %w", nc.LastError())
+			}
+
+			return fmt.Errorf("while reading from stream %s: %w", s.config.Stream, err)
+		}
+
+		if err := s.handleMessage(ctx, msg, out); err != nil {
+			return err
+		}
+	}
+}
+
+// handleMessage sends a message to the pipeline. It is acked once it's in the output channel,
+// so the messages lost by a crash are delivered again after ack_wait.
+func (s *Source) handleMessage(ctx context.Context, msg jetstream.Msg, out chan pipeline.Event) error {
+	ts := time.Now().UTC()
+
+	if meta, err := msg.Metadata(); err == nil {
+		ts = meta.Timestamp.UTC()
+	}
+
+	l := pipeline.Line{
+		Raw:     string(msg.Data()),
+		Labels:  s.config.Labels,
+		Time:    ts,
+		Src:     msg.Subject(),
+		Process: true,
+		Module:  s.GetName(),
+	}
+
+	s.logger.Tracef("line with message read from subject '%s': %+v", msg.Subject(), l)
+
+	evt := pipeline.MakeEvent(s.config.UseTimeMachine, pipeline.LOG, true)
+	evt.Line = l
+
+	select {
+	case out <- evt:
+	case <-ctx.Done():
+		// let the server deliver it again right away
+		if err := msg.Nak(); err != nil {
+			s.logger.Debugf("cannot nak message: %s", err)
+		}
+
+		return nil
+	}
+
+	if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+		metrics.JetStreamDataSourceLinesRead.With(prometheus.Labels{"stream": s.config.Stream, "datasource_type": ModuleName, "acquis_type": l.Labels["type"]}).Inc()
+	}
+
+	if err := msg.Ack(); err != nil {
+		return fmt.Errorf("cannot ack message: %w", err)
+	}
+
+	return nil
+}
@@ -25,6 +25,7 @@ type Configuration struct {
 	Partition                         int                     `yaml:"partition"`
 	Timeout                           string                  `yaml:"timeout"`
 	TLS                               *TLSConfig              `yaml:"tls"`
+	SASL                              *SASLConfig             `yaml:"sasl"`
 	BatchConfiguration                KafkaBatchConfiguration `yaml:"batch"`
 	configuration.DataSourceCommonCfg `yaml:",inline"`
 }
@@ -64,6 +65,20 @@ func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
 		s.Config.Mode = configuration.TAIL_MODE
 	}
 
+	if s.Config.TLS != nil && (s.Config.TLS.ClientCert == "") != (s.Config.TLS.ClientKey == "") {
+		return errors.New("tls.client_cert and tls.client_key must be provided together")
+	}
+
+	if s.Config.SASL != nil {
+		if err := s.Config.SASL.validate(); err != nil {
+			return err
+		}
+
+		if s.Config.TLS == nil && s.Config.SASL.Mechanism == SASLPlain {
+			s.logger.Warnf("sasl mechanism %s without tls: the password will be sent in clear text", SASLPlain)
+		}
+	}
+
 	s.logger.Debugf("successfully parsed kafka configuration : %+v", s.Config)
 
 	return err
@@ -104,12 +119,19 @@ func (c *Configuration) NewTLSConfig() (*tls.Config, error) {
 		InsecureSkipVerify: c.TLS.InsecureSkipVerify,
 	}
 
-	cert, err := tls.LoadX509KeyPair(c.TLS.ClientCert, c.TLS.ClientKey)
-	if err != nil {
-		return &tlsConfig, err
+	// client certificates are not needed when authenticating with SASL
+	if c.TLS.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLS.ClientCert, c.TLS.ClientKey)
+		if err != nil {
+			return &tlsConfig, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	tlsConfig.Certificates = []tls.Certificate{cert}
+	if c.TLS.CaCert == "" {
+		return &tlsConfig, nil
+	}
 
 	caCert, err := os.ReadFile(c.TLS.CaCert)
 	if err != nil {
@@ -158,6 +180,15 @@ func (c *Configuration) NewDialer() (*kafka.Dialer, error) {
 		dialer.TLS = tlsConfig
 	}
 
+	if c.SASL != nil {
+		mechanism, err := c.SASL.NewMechanism()
+		if err != nil {
+			return dialer, err
+		}
+
+		dialer.SASLMechanism = mechanism
+	}
+
 	return dialer, nil
 }
 
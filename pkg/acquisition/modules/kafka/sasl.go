@@ -0,0 +1,89 @@
+package kafkaacquisition
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// SASLConfig holds the credentials to authenticate to the brokers.
+// Environment variables can be used in the values, as in the rest of the acquisition files.
+type SASLConfig struct {
+	Mechanism    string `yaml:"mechanism"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	PasswordFile string `yaml:"password_file"`
+}
+
+func (c *SASLConfig) validate() error {
+	c.Mechanism = strings.ToUpper(c.Mechanism)
+
+	switch c.Mechanism {
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+	case "":
+		return errors.New("sasl.mechanism is required")
+	default:
+		return fmt.Errorf("unsupported sasl.mechanism %q, must be one of %s, %s, %s", c.Mechanism, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+	}
+
+	if c.Username == "" {
+		return errors.New("sasl.username is required")
+	}
+
+	if c.Password != "" && c.PasswordFile != "" {
+		return errors.New("sasl.password and sasl.password_file are mutually exclusive")
+	}
+
+	if c.Password == "" && c.PasswordFile == "" {
+		return errors.New("one of sasl.password or sasl.password_file is required")
+	}
+
+	return nil
+}
+
+func (c *SASLConfig) password() (string, error) {
+	if c.PasswordFile == "" {
+		return c.Password, nil
+	}
+
+	content, err := os.ReadFile(c.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read sasl.password_file: %w", err)
+	}
+
+	password := strings.TrimRight(string(content), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("sasl.password_file %s is empty", c.PasswordFile)
+	}
+
+	return password, nil
+}
+
+func (c *SASLConfig) NewMechanism() (sasl.Mechanism, error) {
+	password, err := c.password()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Mechanism {
+	case SASLPlain:
+		return plain.Mechanism{Username: c.Username, Password: password}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, c.Username, password)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, c.Username, password)
+	}
+
+	return nil, fmt.Errorf("unsupported sasl.mechanism %q", c.Mechanism)
+}
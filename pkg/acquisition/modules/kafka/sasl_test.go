@@ -0,0 +1,393 @@
+package kafkaacquisition
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xdg-go/scram"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+const (
+	apiKeySaslHandshake    = 17
+	apiKeyAPIVersions      = 18
+	apiKeySaslAuthenticate = 36
+
+	errSaslAuthenticationFailed = 58
+)
+
+// fakeBroker only knows how to authenticate clients, which is enough to test the SASL configuration
+// without a real kafka cluster.
+type fakeBroker struct {
+	listener net.Listener
+	username string
+	password string
+	// result of the last authentication, reported once the connection is closed
+	results chan error
+}
+
+func newFakeBroker(t *testing.T, username string, password string) *fakeBroker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b := &fakeBroker{
+		listener: listener,
+		username: username,
+		password: password,
+		results:  make(chan error, 1),
+	}
+
+	go b.serve()
+
+	t.Cleanup(func() { listener.Close() })
+
+	return b
+}
+
+func (b *fakeBroker) addr() string {
+	return b.listener.Addr().String()
+}
+
+func (b *fakeBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+			b.results <- b.handle(conn)
+		}()
+	}
+}
+
+// saslSession checks the client messages for a mechanism.
+type saslSession func(msg []byte) (reply []byte, done bool, err error)
+
+func (b *fakeBroker) newSession(mechanism string) (saslSession, error) {
+	switch mechanism {
+	case SASLPlain:
+		return func(msg []byte) ([]byte, bool, error) {
+			// authzid \0 username \0 password
+			parts := strings.Split(string(msg), "\x00")
+			if len(parts) != 3 || parts[1] != b.username || parts[2] != b.password {
+				return nil, true, errors.New("invalid credentials")
+			}
+
+			return nil, true, nil
+		}, nil
+	case SASLScramSHA256, SASLScramSHA512:
+		hashGen := scram.SHA256
+		if mechanism == SASLScramSHA512 {
+			hashGen = scram.SHA512
+		}
+
+		client, err := hashGen.NewClient(b.username, b.password, "")
+		if err != nil {
+			return nil, err
+		}
+
+		stored := client.GetStoredCredentials(scram.KeyFactors{Salt: "crowdsec-salt", Iters: 4096})
+
+		server, err := hashGen.NewServer(func(username string) (scram.StoredCredentials, error) {
+			if username != b.username {
+				return scram.StoredCredentials{}, errors.New("unknown user")
+			}
+
+			return stored, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		conv := server.NewConversation()
+
+		return func(msg []byte) ([]byte, bool, error) {
+			reply, err := conv.Step(string(msg))
+			if err != nil {
+				return nil, true, err
+			}
+
+			return []byte(reply), conv.Done(), nil
+		}, nil
+	}
+
+	return nil, errors.New("unsupported mechanism " + mechanism)
+}
+
+func readString(r io.Reader) (string, error) {
+	var size int16
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return "", err
+	}
+
+	if size < 0 {
+		return "", nil
+	}
+
+	buf := make([]byte, size)
+	_, err := io.ReadFull(r, buf)
+
+	return string(buf), err
+}
+
+func readBytes(r io.Reader) ([]byte, error) {
+	var size int32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	_, err := io.ReadFull(r, buf)
+
+	return buf, err
+}
+
+func writeResponse(w io.Writer, correlationID int32, fields ...any) error {
+	body := binary.BigEndian.AppendUint32(nil, uint32(correlationID))
+
+	for _, field := range fields {
+		switch v := field.(type) {
+		case int16:
+			body = binary.BigEndian.AppendUint16(body, uint16(v))
+		case int32:
+			body = binary.BigEndian.AppendUint32(body, uint32(v))
+		case []byte:
+			body = binary.BigEndian.AppendUint32(body, uint32(len(v)))
+			body = append(body, v...)
+		}
+	}
+
+	_, err := w.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(body))), body...))
+
+	return err
+}
+
+// handle returns nil when the client authenticated successfully.
+func (b *fakeBroker) handle(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+
+	var session saslSession
+
+	for {
+		var header struct {
+			Size          int32
+			APIKey        int16
+			APIVersion    int16
+			CorrelationID int32
+		}
+
+		if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+			return err
+		}
+
+		// client id
+		if _, err := readString(r); err != nil {
+			return err
+		}
+
+		switch header.APIKey {
+		case apiKeyAPIVersions:
+			// no error, 2 apis: key, min version, max version
+			err := writeResponse(conn, header.CorrelationID, int16(0), int32(2),
+				int16(apiKeySaslHandshake), int16(1), int16(1),
+				int16(apiKeySaslAuthenticate), int16(0), int16(0))
+			if err != nil {
+				return err
+			}
+		case apiKeySaslHandshake:
+			mechanism, err := readString(r)
+			if err != nil {
+				return err
+			}
+
+			session, err = b.newSession(mechanism)
+			if err != nil {
+				return err
+			}
+
+			// no error, no supported mechanisms listed
+			if err := writeResponse(conn, header.CorrelationID, int16(0), int32(0)); err != nil {
+				return err
+			}
+		case apiKeySaslAuthenticate:
+			if session == nil {
+				return errors.New("authentication without handshake")
+			}
+
+			msg, err := readBytes(r)
+			if err != nil {
+				return err
+			}
+
+			reply, done, authErr := session(msg)
+			if authErr != nil {
+				// error code, null error message, no data
+				_ = writeResponse(conn, header.CorrelationID, int16(errSaslAuthenticationFailed), int16(-1), []byte{})
+				return authErr
+			}
+
+			// no error, null error message
+			if err := writeResponse(conn, header.CorrelationID, int16(0), int16(-1), reply); err != nil {
+				return err
+			}
+
+			if done {
+				return nil
+			}
+		default:
+			return errors.New("unexpected request")
+		}
+	}
+}
+
+func TestSASLConfig(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600))
+
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name: "password",
+			config: `
+sasl:
+  mechanism: scram-sha-512
+  username: crowdsec
+  password: s3cret`,
+		},
+		{
+			name: "password file",
+			config: `
+sasl:
+  mechanism: PLAIN
+  username: crowdsec
+  password_file: ` + passwordFile,
+		},
+		{
+			name: "missing password file",
+			config: `
+sasl:
+  mechanism: PLAIN
+  username: crowdsec
+  password_file: /does/not/exist`,
+			expectedErr: "unable to read sasl.password_file: open /does/not/exist: " + cstest.FileNotFoundMessage,
+		},
+		{
+			name: "unknown mechanism",
+			config: `
+sasl:
+  mechanism: GSSAPI
+  username: crowdsec
+  password: s3cret`,
+			expectedErr: `unsupported sasl.mechanism "GSSAPI", must be one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512`,
+		},
+		{
+			name: "no username",
+			config: `
+sasl:
+  mechanism: PLAIN
+  password: s3cret`,
+			expectedErr: "sasl.username is required",
+		},
+		{
+			name: "no password",
+			config: `
+sasl:
+  mechanism: PLAIN
+  username: crowdsec`,
+			expectedErr: "one of sasl.password or sasl.password_file is required",
+		},
+		{
+			name: "both passwords",
+			config: `
+sasl:
+  mechanism: PLAIN
+  username: crowdsec
+  password: s3cret
+  password_file: ` + passwordFile,
+			expectedErr: "sasl.password and sasl.password_file are mutually exclusive",
+		},
+		{
+			name: "client cert without key",
+			config: `
+tls:
+  client_cert: ./testdata/kafkaClient.certificate.pem`,
+			expectedErr: "tls.client_cert and tls.client_key must be provided together",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Source{logger: log.WithField("type", ModuleName)}
+
+			err := s.UnmarshalConfig([]byte("source: kafka\nbrokers: [localhost:9092]\ntopic: crowdsec\n" + tc.config))
+			if err == nil {
+				_, err = s.Config.NewDialer()
+			}
+
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestSASLAuthentication(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		mechanism   string
+		password    string
+		expectedErr string
+	}{
+		{mechanism: SASLPlain, password: "s3cret"},
+		{mechanism: SASLScramSHA256, password: "s3cret"},
+		{mechanism: SASLScramSHA512, password: "s3cret"},
+		{mechanism: SASLPlain, password: "wrong", expectedErr: "SASL Authentication Failed"},
+		{mechanism: SASLScramSHA512, password: "wrong", expectedErr: "SASL Authentication Failed"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.mechanism+"/"+tc.password, func(t *testing.T) {
+			broker := newFakeBroker(t, "crowdsec", "s3cret")
+
+			c := Configuration{
+				Brokers: []string{broker.addr()},
+				Timeout: "5",
+				SASL: &SASLConfig{
+					Mechanism: tc.mechanism,
+					Username:  "crowdsec",
+					Password:  tc.password,
+				},
+			}
+
+			dialer, err := c.NewDialer()
+			require.NoError(t, err)
+
+			conn, err := dialer.DialContext(ctx, "tcp", broker.addr())
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if err == nil {
+				conn.Close()
+				assert.NoError(t, <-broker.results)
+			} else {
+				assert.Error(t, <-broker.results)
+			}
+		})
+	}
+}
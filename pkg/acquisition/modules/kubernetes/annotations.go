@@ -0,0 +1,94 @@
+package kubernetes
+
+import (
+	"maps"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Pod annotations read with use_pod_annotations. For example:
+//
+//	crowdsec.net/type: nginx                         # labels.type of all the containers
+//	crowdsec.net/labels.program: nginx               # any other label
+//	crowdsec.net/container.sidecar.type: envoy       # only for the container "sidecar"
+//
+// The container-specific annotations win over the pod-wide ones, which win over the labels of the configuration.
+const (
+	annotationPrefix          = "crowdsec.net/"
+	annotationContainerPrefix = "container."
+	annotationLabelPrefix     = "labels."
+	annotationType            = "type"
+)
+
+// annotationLabel returns the label set by an annotation (without the prefix), if any.
+func annotationLabel(name string) (string, bool) {
+	switch {
+	case name == annotationType:
+		return "type", true
+	case strings.HasPrefix(name, annotationLabelPrefix) && len(name) > len(annotationLabelPrefix):
+		return strings.TrimPrefix(name, annotationLabelPrefix), true
+	default:
+		return "", false
+	}
+}
+
+// podLabels computes the labels of the lines of a container.
+func (s *Source) podLabels(pod *corev1.Pod, container string) map[string]string {
+	if !s.config.UsePodAnnotations {
+		return s.config.Labels
+	}
+
+	labels := maps.Clone(s.config.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	containerLabels := make(map[string]string)
+
+	for key, value := range pod.Annotations {
+		name, ok := strings.CutPrefix(key, annotationPrefix)
+		if !ok {
+			continue
+		}
+
+		dest := labels
+
+		// container names can't have dots
+		if rest, ok := strings.CutPrefix(name, annotationContainerPrefix); ok {
+			target, after, found := strings.Cut(rest, ".")
+			if !found || target != container {
+				continue
+			}
+
+			name = after
+			dest = containerLabels
+		}
+
+		label, ok := annotationLabel(name)
+		if !ok {
+			s.logger.Warnf("unknown annotation %s on pod %s/%s", key, pod.Namespace, pod.Name)
+			continue
+		}
+
+		dest[label] = value
+	}
+
+	maps.Copy(labels, containerLabels)
+
+	return labels
+}
+
+// podMeta is the metadata added to the events of a container, to be used by scenarios and alert context.
+func podMeta(pod *corev1.Pod, container string) map[string]string {
+	meta := map[string]string{
+		"k8s_namespace": pod.Namespace,
+		"k8s_pod":       pod.Name,
+		"k8s_container": container,
+		"k8s_node":      pod.Spec.NodeName,
+	}
+
+	maps.DeleteFunc(meta, func(_, v string) bool { return v == "" })
+
+	return meta
+}
@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+)
+
+func TestPodLabels(t *testing.T) {
+	annotations := map[string]string{
+		"crowdsec.net/type":                      "nginx",
+		"crowdsec.net/labels.program":            "nginx",
+		"crowdsec.net/container.sidecar.type":    "envoy",
+		"crowdsec.net/container.other.type":      "apache2",
+		"crowdsec.net/container.sidecar.labels.": "ignored",
+		"crowdsec.net/unknown":                   "ignored",
+		"example.com/type":                       "ignored",
+	}
+
+	tests := []struct {
+		name        string
+		annotations bool
+		container   string
+		expected    map[string]string
+	}{
+		{
+			name:      "annotations are ignored by default",
+			container: "app",
+			expected:  map[string]string{"type": "containerd", "env": "prod"},
+		},
+		{
+			name:        "pod-wide annotations",
+			annotations: true,
+			container:   "app",
+			expected:    map[string]string{"type": "nginx", "env": "prod", "program": "nginx"},
+		},
+		{
+			name:        "container annotations",
+			annotations: true,
+			container:   "sidecar",
+			expected:    map[string]string{"type": "envoy", "env": "prod", "program": "nginx"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Source{
+				logger: testLogger(),
+				config: Configuration{
+					DataSourceCommonCfg: configuration.DataSourceCommonCfg{
+						Labels: map[string]string{"type": "containerd", "env": "prod"},
+					},
+					UsePodAnnotations: tc.annotations,
+				},
+			}
+
+			pod := newTestPod("pod", "ns", corev1.PodRunning)
+			pod.Annotations = annotations
+
+			assert.Equal(t, tc.expected, s.podLabels(pod, tc.container))
+			// the configuration is not modified
+			assert.Equal(t, map[string]string{"type": "containerd", "env": "prod"}, s.config.Labels)
+		})
+	}
+}
+
+func TestPodMeta(t *testing.T) {
+	pod := newTestPod("pod", "ns", corev1.PodRunning)
+
+	assert.Equal(t, map[string]string{"k8s_namespace": "ns", "k8s_pod": "pod", "k8s_container": "app"}, podMeta(pod, "app"))
+
+	pod.Spec.NodeName = "node1"
+
+	assert.Equal(t, "node1", podMeta(pod, "app")["k8s_node"])
+}
@@ -22,6 +22,9 @@ type Configuration struct {
 	Namespace      string `yaml:"namespace"`
 	KubeConfigFile string `yaml:"kube_config,omitempty"`
 	KubeContext    string `yaml:"kube_context,omitempty"`
+	// UsePodAnnotations reads the labels of each pod from its crowdsec.net/ annotations,
+	// the labels of the configuration are the defaults.
+	UsePodAnnotations bool `yaml:"use_pod_annotations,omitempty"`
 }
 
 func ConfigurationFromYAML(yamlConfig []byte) (Configuration, error) {
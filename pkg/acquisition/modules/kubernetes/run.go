@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 
@@ -251,23 +252,31 @@ func (s *Source) followPodLogs(ctx context.Context, ns string, pod string, conta
 	}
 }
 
-func (s *Source) processLine(line string, source string, out chan pipeline.Event) error {
-	l := pipeline.Line{
-		Raw:     line,
-		Labels:  s.config.Labels,
-		Time:    time.Now().UTC(),
-		Src:     source,
-		Process: true,
-		Module:  s.GetName(),
-	}
-	if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
-		metrics.KubernetesDataSourceLinesRead.With(prometheus.Labels{"source": source, "acquis_type": l.Labels["type"], "datasource_type": ModuleName}).Inc()
+// lineProcessor returns the function that sends the lines of a container to the pipeline,
+// with the labels and metadata of the pod.
+func (s *Source) lineProcessor(pod *corev1.Pod, container string) func(string, string, chan pipeline.Event) error {
+	labels := s.podLabels(pod, container)
+	meta := podMeta(pod, container)
+
+	return func(line string, source string, out chan pipeline.Event) error {
+		l := pipeline.Line{
+			Raw:     line,
+			Labels:  labels,
+			Time:    time.Now().UTC(),
+			Src:     source,
+			Process: true,
+			Module:  s.GetName(),
+		}
+		if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+			metrics.KubernetesDataSourceLinesRead.With(prometheus.Labels{"source": source, "acquis_type": l.Labels["type"], "datasource_type": ModuleName}).Inc()
+		}
+		evt := pipeline.MakeEvent(s.config.UseTimeMachine, pipeline.LOG, true)
+		evt.Line = l
+		maps.Copy(evt.Meta, meta)
+		out <- evt
+		s.logger.Tracef("got one line from %s: %s", source, line)
+		return nil
 	}
-	evt := pipeline.MakeEvent(s.config.UseTimeMachine, pipeline.LOG, true)
-	evt.Line = l
-	out <- evt
-	s.logger.Tracef("got one line from %s: %s", source, line)
-	return nil
 }
 
 func (s *Source) podWorker(parentCtx context.Context,
@@ -284,7 +293,7 @@ func (s *Source) podWorker(parentCtx context.Context,
 		var cw sync.WaitGroup
 		for _, cont := range pod.Spec.Containers {
 			cw.Go(func() {
-				err := s.followPodLogs(podCtx, pod.Namespace, pod.Name, cont.Name, out, s.processLine)
+				err := s.followPodLogs(podCtx, pod.Namespace, pod.Name, cont.Name, out, s.lineProcessor(pod, cont.Name))
 				if err != nil {
 					s.logger.Errorf("error following logs for %s/%s/%s: %s", pod.Namespace, pod.Name, cont.Name, err)
 				} else {
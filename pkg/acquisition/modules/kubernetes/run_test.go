@@ -65,7 +65,7 @@ func TestFollowPodLogs_NoClient(t *testing.T) {
 	// for consistency with the other followPodLogs tests below.
 	out := make(chan pipeline.Event, 1)
 
-	err := s.followPodLogs(t.Context(), "ns", "pod", "container", out, s.processLine)
+	err := s.followPodLogs(t.Context(), "ns", "pod", "container", out, s.lineProcessor(newTestPod("pod", "ns", corev1.PodRunning), "container"))
 	require.ErrorContains(t, err, "kubernetes client is not initialized")
 }
 
@@ -76,11 +76,11 @@ func TestFollowPodLogs_ReadsLinesAndStopsWhenPodNotRunning(t *testing.T) {
 
 	s := &Source{logger: testLogger(), client: client}
 	// followPodLogs is called synchronously below with no concurrent reader
-	// on out, so it must be buffered or processLine's blocking send would
+	// on out, so it must be buffered or the line processor's blocking send would
 	// deadlock the test.
 	out := make(chan pipeline.Event, 10)
 
-	err := s.followPodLogs(t.Context(), "ns", "pod", "container", out, s.processLine)
+	err := s.followPodLogs(t.Context(), "ns", "pod", "container", out, s.lineProcessor(pod, "container"))
 	require.NoError(t, err)
 
 	close(out)
@@ -88,6 +88,8 @@ func TestFollowPodLogs_ReadsLinesAndStopsWhenPodNotRunning(t *testing.T) {
 	var lines []string
 	for evt := range out {
 		lines = append(lines, evt.Line.Raw)
+		assert.Equal(t, "pod", evt.Meta["k8s_pod"])
+		assert.Equal(t, "container", evt.Meta["k8s_container"])
 	}
 
 	assert.Equal(t, []string{"line1", "line2"}, lines)
@@ -132,7 +134,7 @@ func TestFollowPodLogs_RetriesOnStreamError(t *testing.T) {
 	defer cancel()
 
 	start := time.Now()
-	err := s.followPodLogs(ctx, "ns", "pod", "container", out, s.processLine)
+	err := s.followPodLogs(ctx, "ns", "pod", "container", out, s.lineProcessor(pod, "container"))
 	elapsed := time.Since(start)
 
 	require.NoError(t, err, "a persistent stream error must be retried, not returned")
@@ -152,7 +154,7 @@ func TestFollowPodLogs_StopsOnContextCancel(t *testing.T) {
 	ctx, cancel := context.WithCancel(t.Context())
 	cancel()
 
-	err := s.followPodLogs(ctx, "ns", "pod", "container", out, s.processLine)
+	err := s.followPodLogs(ctx, "ns", "pod", "container", out, s.lineProcessor(pod, "container"))
 	require.NoError(t, err)
 }
 
@@ -2,9 +2,12 @@ package kubernetesauditacquisition
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 
 	yaml "github.com/goccy/go-yaml"
 	log "github.com/sirupsen/logrus"
@@ -14,9 +17,12 @@ import (
 )
 
 type Configuration struct {
-	ListenAddr                        string `yaml:"listen_addr"`
-	ListenPort                        int    `yaml:"listen_port"`
-	WebhookPath                       string `yaml:"webhook_path"`
+	ListenAddr                        string                         `yaml:"listen_addr"`
+	ListenPort                        int                            `yaml:"listen_port"`
+	WebhookPath                       string                         `yaml:"webhook_path"`
+	TLS                               *configuration.ServerTLSConfig `yaml:"tls"` // with a CA, see the "users" section of the webhook kubeconfig
+	BearerToken                       string                         `yaml:"bearer_token"`
+	BearerTokenFile                   string                         `yaml:"bearer_token_file"`
 	configuration.DataSourceCommonCfg `yaml:",inline"`
 }
 
@@ -68,10 +74,19 @@ func (c *Configuration) Validate() error {
 		return errors.New("webhook_path cannot be empty")
 	}
 
+	if c.BearerToken != "" && c.BearerTokenFile != "" {
+		return errors.New("bearer_token and bearer_token_file are mutually exclusive")
+	}
+
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-
 func (c *Configuration) Normalize() {
 	if c.WebhookPath != "" && c.WebhookPath[0] != '/' {
 		c.WebhookPath = "/" + c.WebhookPath
@@ -89,6 +104,20 @@ func (s *Source) Configure(_ context.Context, config []byte, logger *log.Entry,
 
 	s.logger.Tracef("K8SAudit configuration: %+v", s.config)
 
+	s.bearerToken = s.config.BearerToken
+
+	if s.config.BearerTokenFile != "" {
+		token, err := os.ReadFile(s.config.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read bearer_token_file: %w", err)
+		}
+
+		s.bearerToken = strings.TrimSpace(string(token))
+		if s.bearerToken == "" {
+			return fmt.Errorf("bearer_token_file %s is empty", s.config.BearerTokenFile)
+		}
+	}
+
 	s.addr = fmt.Sprintf("%s:%d", s.config.ListenAddr, s.config.ListenPort)
 
 	s.mux = http.NewServeMux()
@@ -103,6 +132,21 @@ func (s *Source) Configure(_ context.Context, config []byte, logger *log.Entry,
 	s.server.Protocols.SetUnencryptedHTTP2(true)
 	s.server.Protocols.SetHTTP2(true)
 
+	if s.config.TLS != nil {
+		tlsConfig, err := s.config.TLS.NewTLSConfig()
+		if err != nil {
+			return err
+		}
+
+		if tlsConfig.ClientCAs != nil {
+			// the client certificate is verified by us, to count the failed handshakes
+			tlsConfig.ClientAuth = tls.RequireAnyClientCert
+			tlsConfig.VerifyConnection = s.verifyClientCert(tlsConfig.ClientCAs)
+		}
+
+		s.server.TLSConfig = tlsConfig
+	}
+
 	s.mux.HandleFunc(s.config.WebhookPath, s.webhookHandler)
 
 	return nil
@@ -1,12 +1,23 @@
 package kubernetesauditacquisition
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"math/big"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -236,3 +247,214 @@ webhook_path: /k8s-audit`, port)
 		})
 	}
 }
+
+func TestHandlerBearerToken(t *testing.T) {
+	ctx := t.Context()
+	tests := []struct {
+		name               string
+		authorization      string
+		expectedStatusCode int
+	}{
+		{
+			name:               "no_token",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "wrong_token",
+			authorization:      "Bearer wrong",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "wrong_scheme",
+			authorization:      "Basic secret",
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "valid_token",
+			authorization:      "Bearer secret",
+			expectedStatusCode: 200,
+		},
+	}
+
+	subLogger := log.WithField("type", ModuleName)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := Source{}
+
+			config := `source: k8s-audit
+listen_addr: 127.0.0.1
+listen_port: 49250
+webhook_path: /k8s-audit
+bearer_token: secret`
+
+			err := f.Configure(ctx, []byte(config), subLogger, metrics.AcquisitionMetricsLevelNone)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/k8s-audit", strings.NewReader(`{"Items": []}`))
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			w := httptest.NewRecorder()
+
+			f.webhookHandler(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+
+			if test.expectedStatusCode == 401 {
+				assert.Equal(t, `Bearer realm="crowdsec"`, res.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestBearerTokenFile(t *testing.T) {
+	ctx := t.Context()
+	subLogger := log.WithField("type", ModuleName)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0o600))
+
+	f := Source{}
+	err := f.Configure(ctx, []byte(`source: k8s-audit
+listen_addr: 127.0.0.1
+listen_port: 49251
+webhook_path: /k8s-audit
+bearer_token_file: `+tokenFile), subLogger, metrics.AcquisitionMetricsLevelNone)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", f.bearerToken)
+
+	emptyFile := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("\n"), 0o600))
+
+	f = Source{}
+	err = f.Configure(ctx, []byte(`source: k8s-audit
+listen_addr: 127.0.0.1
+listen_port: 49251
+webhook_path: /k8s-audit
+bearer_token_file: `+emptyFile), subLogger, metrics.AcquisitionMetricsLevelNone)
+	cstest.RequireErrorContains(t, err, "is empty")
+
+	f = Source{}
+	err = f.Configure(ctx, []byte(`source: k8s-audit
+listen_addr: 127.0.0.1
+listen_port: 49251
+webhook_path: /k8s-audit
+bearer_token_file: /does/not/exist`), subLogger, metrics.AcquisitionMetricsLevelNone)
+	cstest.RequireErrorContains(t, err, "failed to read bearer_token_file: open /does/not/exist: "+cstest.FileNotFoundMessage)
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	ctx := t.Context()
+	subLogger := log.WithField("type", ModuleName)
+
+	out := make(chan pipeline.Event)
+	tb := &tomb.Tomb{}
+
+	tb.Go(func() error {
+		for {
+			select {
+			case <-out:
+			case <-tb.Dying():
+				return nil
+			}
+		}
+	})
+
+	// the test certificates are shared by the datasources that accept TLS connections
+	f := Source{}
+	err := f.Configure(ctx, []byte(`source: k8s-audit
+listen_addr: 127.0.0.1
+listen_port: 49252
+webhook_path: /k8s-audit
+tls:
+  server_cert: ../../testdata/tls/server.crt
+  server_key: ../../testdata/tls/server.key
+  ca_cert: ../../testdata/tls/ca.crt`), subLogger, metrics.AcquisitionMetricsLevelFull)
+	require.NoError(t, err)
+
+	err = f.StreamingAcquisition(ctx, out, tb)
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	caCert, err := os.ReadFile("../../testdata/tls/ca.crt")
+	require.NoError(t, err)
+
+	caCertPool := x509.NewCertPool()
+	caCertPool.AppendCertsFromPEM(caCert)
+
+	post := func(certs []tls.Certificate) (*http.Response, error) {
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:    caCertPool,
+					MinVersion: tls.VersionTLS12,
+					// send the certificate even if it's not signed by an acceptable CA
+					GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+						if len(certs) == 0 {
+							return &tls.Certificate{}, nil
+						}
+
+						return &certs[0], nil
+					},
+				},
+			},
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://127.0.0.1:49252/k8s-audit", strings.NewReader(`{"Items": []}`))
+		require.NoError(t, err)
+
+		return client.Do(req)
+	}
+
+	// no client certificate: the handshake fails
+	_, err = post(nil)
+	require.Error(t, err)
+
+	// a certificate not signed by the CA is counted as rejected
+	rejected := metrics.K8SAuditDataSourceRejectedRequestCount.WithLabelValues("127.0.0.1:49252", "client_cert")
+	before := testutil.ToFloat64(rejected)
+
+	_, err = post([]tls.Certificate{selfSignedCert(t)})
+	require.Error(t, err)
+
+	assert.InDelta(t, before+1, testutil.ToFloat64(rejected), 0)
+
+	cert, err := tls.LoadX509KeyPair("../../testdata/tls/client.crt", "../../testdata/tls/client.key")
+	require.NoError(t, err)
+
+	resp, err := post([]tls.Certificate{cert})
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	tb.Kill(nil)
+	err = tb.Wait()
+	require.NoError(t, err)
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rogue"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
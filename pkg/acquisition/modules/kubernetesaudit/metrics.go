@@ -10,6 +10,7 @@ func (*Source) GetMetrics() []prometheus.Collector {
 	return []prometheus.Collector{
 		metrics.K8SAuditDataSourceEventCount,
 		metrics.K8SAuditDataSourceRequestCount,
+		metrics.K8SAuditDataSourceRejectedRequestCount,
 	}
 }
 
@@ -17,5 +18,6 @@ func (*Source) GetAggregMetrics() []prometheus.Collector {
 	return []prometheus.Collector{
 		metrics.K8SAuditDataSourceEventCount,
 		metrics.K8SAuditDataSourceRequestCount,
+		metrics.K8SAuditDataSourceRejectedRequestCount,
 	}
 }
@@ -2,7 +2,11 @@ package kubernetesauditacquisition
 
 import (
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -27,7 +31,15 @@ func (s *Source) StreamingAcquisition(ctx context.Context, out chan pipeline.Eve
 		s.logger.Infof("Starting k8s-audit server on %s:%d%s", s.config.ListenAddr, s.config.ListenPort, s.config.WebhookPath)
 
 		t.Go(func() error {
-			err := s.server.ListenAndServe()
+			var err error
+
+			if s.server.TLSConfig != nil {
+				// the certificates are already loaded in the tls config
+				err = s.server.ListenAndServeTLS("", "")
+			} else {
+				err = s.server.ListenAndServe()
+			}
+
 			if err != nil && err != http.ErrServerClosed {
 				return fmt.Errorf("k8s-audit server failed: %w", err)
 			}
@@ -47,13 +59,75 @@ func (s *Source) StreamingAcquisition(ctx context.Context, out chan pipeline.Eve
 	return nil
 }
 
+// authorized checks the bearer token, if one is configured. The client certificate, if required,
+// has already been verified during the TLS handshake.
+func (s *Source) authorized(r *http.Request) bool {
+	if s.bearerToken == "" {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.bearerToken)) == 1
+}
+
+// verifyClientCert returns a tls.Config.VerifyConnection callback that checks the client
+// certificate against the CAs, as RequireAndVerifyClientCert would.
+func (s *Source) verifyClientCert(clientCAs *x509.CertPool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			s.reject("client_cert")
+			return errors.New("no client certificate")
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         clientCAs,
+			Intermediates: x509.NewCertPool(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+
+		if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+			s.logger.Debugf("invalid client certificate: %s", err)
+			s.reject("client_cert")
+
+			return err
+		}
+
+		return nil
+	}
+}
+
+func (s *Source) reject(reason string) {
+	if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+		metrics.K8SAuditDataSourceRejectedRequestCount.WithLabelValues(s.addr, reason).Inc()
+	}
+}
+
 func (s *Source) webhookHandler(w http.ResponseWriter, r *http.Request) {
 	if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
 		metrics.K8SAuditDataSourceRequestCount.WithLabelValues(s.addr).Inc()
 	}
 
+	if !s.authorized(r) {
+		s.logger.Debugf("unauthorized request from %s", r.RemoteAddr)
+		s.reject("unauthorized")
+		w.Header().Set("WWW-Authenticate", `Bearer realm="crowdsec"`)
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
 	if r.Method != http.MethodPost {
+		s.reject("method")
 		w.WriteHeader(http.StatusMethodNotAllowed)
+
 		return
 	}
 
@@ -64,6 +138,7 @@ func (s *Source) webhookHandler(w http.ResponseWriter, r *http.Request) {
 	jsonBody, err := io.ReadAll(r.Body)
 	if err != nil {
 		s.logger.Errorf("Error reading request body: %v", err)
+		s.reject("body")
 		w.WriteHeader(http.StatusInternalServerError)
 
 		return
@@ -74,6 +149,7 @@ func (s *Source) webhookHandler(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(jsonBody, &auditEvents)
 	if err != nil {
 		s.logger.Errorf("Error decoding audit events: %s", err)
+		s.reject("body")
 		w.WriteHeader(http.StatusInternalServerError)
 
 		return
@@ -17,6 +17,7 @@ type Source struct {
 	server       *http.Server
 	outChan      chan pipeline.Event
 	addr         string
+	bearerToken  string // from bearer_token or bearer_token_file
 }
 
 func (s *Source) GetUuid() string {
@@ -0,0 +1,5 @@
+//go:build !no_datasource_mqtt
+
+package modules
+
+import _ "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/mqtt" // register the datasource
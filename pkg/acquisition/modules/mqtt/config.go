@@ -0,0 +1,204 @@
+package mqttacquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	yaml "github.com/goccy/go-yaml"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+const (
+	defaultQoS     = 1
+	defaultTimeout = 10 * time.Second
+)
+
+type Configuration struct {
+	Brokers                           []string                       `yaml:"brokers"`
+	Topics                            []string                       `yaml:"topics"`
+	ClientID                          string                         `yaml:"client_id"`
+	QoS                               *int                           `yaml:"qos"`
+	CleanSession                      bool                           `yaml:"clean_session"`
+	Username                          string                         `yaml:"username"`
+	Password                          string                         `yaml:"password"`
+	Timeout                           time.Duration                  `yaml:"timeout"`
+	TLS                               *configuration.ClientTLSConfig `yaml:"tls"`
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+}
+
+func ConfigurationFromYAML(y []byte) (Configuration, error) {
+	var cfg Configuration
+
+	if err := yaml.UnmarshalWithOptions(y, &cfg, yaml.Strict()); err != nil {
+		return cfg, fmt.Errorf("cannot parse: %s", yaml.FormatError(err, false, false))
+	}
+
+	cfg.SetDefaults()
+
+	err := cfg.Validate()
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Configuration) SetDefaults() {
+	if c.Mode == "" {
+		c.Mode = configuration.TAIL_MODE
+	}
+
+	// without a persistent session, the id only has to be unique among the clients of the broker
+	if c.ClientID == "" && c.CleanSession {
+		c.ClientID = "crowdsec-"
+
+		if hostname, err := os.Hostname(); err == nil && hostname != "" {
+			c.ClientID += hostname + "-"
+		}
+
+		c.ClientID += uuid.NewString()[:8]
+	}
+
+	if c.QoS == nil {
+		c.QoS = new(defaultQoS)
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+}
+
+func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
+	cfg, err := ConfigurationFromYAML(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	s.config = cfg
+
+	return nil
+}
+
+// validateTopicFilter checks the wildcards of a subscription: "+" matches a whole level,
+// "#" the remaining levels.
+func validateTopicFilter(filter string) error {
+	if filter == "" {
+		return errors.New("topic cannot be empty")
+	}
+
+	levels := strings.Split(filter, "/")
+
+	for idx, level := range levels {
+		switch {
+		case level == "#" && idx != len(levels)-1:
+			return fmt.Errorf("invalid topic '%s': # must be the last level", filter)
+		case level != "#" && strings.Contains(level, "#"):
+			return fmt.Errorf("invalid topic '%s': # must be a whole level", filter)
+		case level != "+" && strings.Contains(level, "+"):
+			return fmt.Errorf("invalid topic '%s': + must be a whole level", filter)
+		}
+	}
+
+	return nil
+}
+
+func (c *Configuration) Validate() error {
+	if len(c.Brokers) == 0 {
+		return errors.New("brokers is required")
+	}
+
+	if len(c.Topics) == 0 {
+		return errors.New("topics is required")
+	}
+
+	for _, topic := range c.Topics {
+		if err := validateTopicFilter(topic); err != nil {
+			return err
+		}
+	}
+
+	if c.Mode != configuration.TAIL_MODE {
+		return fmt.Errorf("unsupported mode %s for %s datasource", c.Mode, ModuleName)
+	}
+
+	if *c.QoS < 0 || *c.QoS > 2 {
+		return fmt.Errorf("invalid qos %d: must be 0, 1 or 2", *c.QoS)
+	}
+
+	if c.Password != "" && c.Username == "" {
+		return errors.New("password requires username")
+	}
+
+	// the broker keeps a persistent session for the client id, and disconnects a client
+	// when another one connects with the same id: it must be unique to each crowdsec instance
+	if c.ClientID == "" {
+		return errors.New("client_id is required when clean_session is false")
+	}
+
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clientOptions returns the options of the mqtt client. Reconnections are
+// disabled: Stream() fails, and is restarted by the caller.
+func (c *Configuration) clientOptions() (*paho.ClientOptions, error) {
+	opts := paho.NewClientOptions().
+		SetClientID(c.ClientID).
+		SetCleanSession(c.CleanSession).
+		SetConnectTimeout(c.Timeout).
+		SetAutoReconnect(false).
+		SetConnectRetry(false).
+		// the messages are acked once they are in the pipeline
+		SetAutoAckDisabled(true)
+
+	for _, broker := range c.Brokers {
+		opts.AddBroker(broker)
+	}
+
+	if c.Username != "" {
+		opts.SetUsername(c.Username)
+		opts.SetPassword(c.Password)
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.NewTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	return opts, nil
+}
+
+func (s *Source) Configure(_ context.Context, yamlConfig []byte, logger *log.Entry, metricsLevel metrics.AcquisitionMetricsLevel) error {
+	s.logger = logger
+	s.metricsLevel = metricsLevel
+
+	err := s.UnmarshalConfig(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	// load the certificates now, to report errors early
+	if _, err := s.config.clientOptions(); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package mqttacquisition
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/acquisitiontest"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func TestValidateTopicFilter(t *testing.T) {
+	tests := []struct {
+		filter      string
+		expectedErr string
+	}{
+		{filter: "logs"},
+		{filter: "logs/nginx"},
+		{filter: "#"},
+		{filter: "logs/#"},
+		{filter: "+/syslog"},
+		{filter: "devices/+/syslog/#"},
+		{filter: "", expectedErr: "topic cannot be empty"},
+		{filter: "logs/#/nginx", expectedErr: "invalid topic 'logs/#/nginx': # must be the last level"},
+		{filter: "logs#", expectedErr: "invalid topic 'logs#': # must be a whole level"},
+		{filter: "devices/gw+/syslog", expectedErr: "invalid topic 'devices/gw+/syslog': + must be a whole level"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			err := validateTopicFilter(test.filter)
+			cstest.RequireErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		config      string
+		expectedErr string
+	}{
+		{
+			config: `
+source: mqtt
+topics: [logs/#]`,
+			expectedErr: "brokers is required",
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]`,
+			expectedErr: "topics is required",
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#/nginx]`,
+			expectedErr: "invalid topic 'logs/#/nginx': # must be the last level",
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#]
+qos: 3`,
+			expectedErr: "invalid qos 3: must be 0, 1 or 2",
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#]
+mode: cat`,
+			expectedErr: "unsupported mode cat for mqtt datasource",
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#]
+password: secret`,
+			expectedErr: "password requires username",
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#]`,
+			expectedErr: "client_id is required when clean_session is false",
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#]
+clean_session: true`,
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#]
+client_id: crowdsec-edge
+tls:
+  ca_cert: /does/not/exist`,
+			expectedErr: "failed to read ca cert: open /does/not/exist: " + cstest.FileNotFoundMessage,
+		},
+		{
+			config: `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#, devices/+/syslog]
+client_id: crowdsec-edge
+qos: 0
+username: user
+password: secret`,
+		},
+	}
+
+	subLogger := log.WithField("type", ModuleName)
+
+	for _, test := range tests {
+		t.Run(test.expectedErr, func(t *testing.T) {
+			s := Source{}
+			err := s.Configure(ctx, []byte(test.config), subLogger, metrics.AcquisitionMetricsLevelNone)
+			cstest.RequireErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestDefaultClientID(t *testing.T) {
+	config := `
+source: mqtt
+brokers: [tcp://127.0.0.1:1883]
+topics: [logs/#]
+clean_session: true`
+
+	s1 := Source{}
+	acquisitiontest.Configure(t, &s1, config)
+
+	s2 := Source{}
+	acquisitiontest.Configure(t, &s2, config)
+
+	// two instances connected to the same broker don't kick each other off
+	assert.True(t, strings.HasPrefix(s1.config.ClientID, "crowdsec-"))
+	assert.NotEqual(t, s1.config.ClientID, s2.config.ClientID)
+}
+
+// runBroker starts an embedded mqtt broker, with an inline client to publish messages.
+// The returned function stops the broker, which can't be closed twice.
+func runBroker(t *testing.T) (*mqtt.Server, string, func() error) {
+	t.Helper()
+
+	broker := mqtt.New(&mqtt.Options{InlineClient: true})
+	require.NoError(t, broker.AddHook(new(auth.AllowHook), nil))
+
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	require.NoError(t, broker.AddListener(tcp))
+	require.NoError(t, broker.Serve())
+
+	stop := sync.OnceValue(broker.Close)
+	t.Cleanup(func() { _ = stop() })
+
+	return broker, "tcp://" + tcp.Address(), stop
+}
+
+func waitSubscribed(t *testing.T, broker *mqtt.Server, clientID string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		cl, ok := broker.Clients.Get(clientID)
+		return ok && !cl.Closed() && cl.State.Subscriptions.Len() > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func receive(t *testing.T, out chan pipeline.Event, n int) []pipeline.Event {
+	t.Helper()
+
+	events := []pipeline.Event{}
+
+	for range n {
+		select {
+		case evt := <-out:
+			events = append(events, evt)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout, received %d/%d events", len(events), n)
+		}
+	}
+
+	return events
+}
+
+func TestStream(t *testing.T) {
+	broker, addr, _ := runBroker(t)
+
+	s := Source{}
+	acquisitiontest.Configure(t, &s, fmt.Sprintf(`
+source: mqtt
+brokers: [%s]
+topics: [logs/#, devices/+/syslog]
+client_id: test-stream
+labels:
+  type: syslog`, addr))
+
+	out := make(chan pipeline.Event)
+	cancel, done := acquisitiontest.StartStream(t, &s, out)
+
+	waitSubscribed(t, broker, "test-stream")
+
+	require.NoError(t, broker.Publish("logs/nginx", []byte("line 1"), false, 1))
+	require.NoError(t, broker.Publish("devices/gw1/other", []byte("filtered out"), false, 1))
+	require.NoError(t, broker.Publish("devices/gw1/syslog", []byte("line 2"), false, 1))
+
+	events := receive(t, out, 2)
+
+	assert.Equal(t, "line 1", events[0].Line.Raw)
+	assert.Equal(t, "logs/nginx", events[0].Line.Src)
+	assert.Equal(t, "syslog", events[0].Line.Labels["type"])
+	assert.Equal(t, ModuleName, events[0].Line.Module)
+
+	assert.Equal(t, "line 2", events[1].Line.Raw)
+	assert.Equal(t, "devices/gw1/syslog", events[1].Line.Src)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestStreamPersistentSession(t *testing.T) {
+	broker, addr, _ := runBroker(t)
+
+	s := Source{}
+	acquisitiontest.Configure(t, &s, fmt.Sprintf(`
+source: mqtt
+brokers: [%s]
+topics: [logs/#]
+client_id: test-session
+labels:
+  type: syslog`, addr))
+
+	out := make(chan pipeline.Event)
+	cancel, done := acquisitiontest.StartStream(t, &s, out)
+
+	waitSubscribed(t, broker, "test-session")
+
+	cancel()
+	require.NoError(t, <-done)
+
+	// the broker keeps the messages of the session while crowdsec is disconnected
+	require.Eventually(t, func() bool {
+		cl, ok := broker.Clients.Get("test-session")
+		return ok && cl.Closed()
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, broker.Publish("logs/nginx", []byte("line 1"), false, 1))
+	require.NoError(t, broker.Publish("logs/nginx", []byte("line 2"), false, 1))
+
+	cancel, done = acquisitiontest.StartStream(t, &s, out)
+
+	events := receive(t, out, 2)
+
+	assert.ElementsMatch(t, []string{"line 1", "line 2"}, []string{events[0].Line.Raw, events[1].Line.Raw})
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestStreamConnectionLost(t *testing.T) {
+	broker, addr, stop := runBroker(t)
+
+	s := Source{}
+	acquisitiontest.Configure(t, &s, fmt.Sprintf(`
+source: mqtt
+brokers: [%s]
+topics: [logs/#]
+client_id: test-lost
+labels:
+  type: syslog`, addr))
+
+	_, done := acquisitiontest.StartStream(t, &s, make(chan pipeline.Event))
+
+	waitSubscribed(t, broker, "test-lost")
+
+	require.NoError(t, stop())
+
+	// the caller restarts the stream
+	select {
+	case err := <-done:
+		cstest.RequireErrorContains(t, err, "connection lost")
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not return")
+	}
+}
@@ -0,0 +1,107 @@
+package mqttacquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// disconnectQuiesce is how long (in ms) the client waits for the pending work before disconnecting.
+const disconnectQuiesce = 250
+
+// wait returns the error of a token, or a timeout.
+func wait(token paho.Token, timeout time.Duration) error {
+	if !token.WaitTimeout(timeout) {
+		return errors.New("timeout")
+	}
+
+	return token.Error()
+}
+
+func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
+	opts, err := s.config.clientOptions()
+	if err != nil {
+		return err
+	}
+
+	lost := make(chan error, 1)
+
+	opts.SetConnectionLostHandler(func(_ paho.Client, err error) {
+		lost <- err
+	})
+
+	handler := func(_ paho.Client, msg paho.Message) {
+		s.handleMessage(ctx, msg, out)
+	}
+
+	// with a persistent session, the broker sends the pending messages before the subscription is done
+	opts.SetDefaultPublishHandler(handler)
+
+	client := paho.NewClient(opts)
+
+	if err := wait(client.Connect(), s.config.Timeout); err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", strings.Join(s.config.Brokers, ","), err)
+	}
+	defer client.Disconnect(disconnectQuiesce)
+
+	filters := make(map[string]byte, len(s.config.Topics))
+	for _, topic := range s.config.Topics {
+		filters[topic] = byte(*s.config.QoS)
+	}
+
+	if err := wait(client.SubscribeMultiple(filters, handler), s.config.Timeout); err != nil {
+		return fmt.Errorf("cannot subscribe to %s: %w", strings.Join(s.config.Topics, ","), err)
+	}
+
+	s.logger.Infof("subscribed to %s", strings.Join(s.config.Topics, ","))
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-lost:
+		return fmt.Errorf("connection lost: %w", err)
+	}
+}
+
+// handleMessage sends a message to the pipeline. It is acked once it's in the output channel, so with
+// a persistent session and qos > 0, the broker delivers it again if crowdsec stops before that.
+func (s *Source) handleMessage(ctx context.Context, msg paho.Message, out chan pipeline.Event) {
+	l := pipeline.Line{
+		Raw:     string(msg.Payload()),
+		Labels:  s.config.Labels,
+		Time:    time.Now().UTC(),
+		Src:     msg.Topic(),
+		Process: true,
+		Module:  s.GetName(),
+	}
+
+	s.logger.Tracef("line with message read from topic '%s': %+v", msg.Topic(), l)
+
+	evt := pipeline.MakeEvent(s.config.UseTimeMachine, pipeline.LOG, true)
+	evt.Line = l
+
+	select {
+	case out <- evt:
+	case <-ctx.Done():
+		return
+	}
+
+	switch s.metricsLevel {
+	case metrics.AcquisitionMetricsLevelAggregated:
+		metrics.MQTTDataSourceLinesRead.With(prometheus.Labels{"topic": "", "datasource_type": ModuleName, "acquis_type": l.Labels["type"]}).Inc()
+	case metrics.AcquisitionMetricsLevelFull:
+		metrics.MQTTDataSourceLinesRead.With(prometheus.Labels{"topic": msg.Topic(), "datasource_type": ModuleName, "acquis_type": l.Labels["type"]}).Inc()
+	case metrics.AcquisitionMetricsLevelNone:
+		// No metrics for this level
+	}
+
+	msg.Ack()
+}
@@ -0,0 +1,5 @@
+//go:build !no_datasource_otlp
+
+package modules
+
+import _ "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/otlp" // register the datasource
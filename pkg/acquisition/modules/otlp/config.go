@@ -0,0 +1,182 @@
+package otlpacquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	yaml "github.com/goccy/go-yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+const (
+	RawFormatBody = "body"
+	RawFormatJSON = "json"
+
+	defaultPath        = "/v1/logs"
+	defaultMaxBodySize = 10 * 1024 * 1024
+)
+
+type Configuration struct {
+	ListenAddr                        string                         `yaml:"listen_addr"`      // OTLP/HTTP
+	GRPCListenAddr                    string                         `yaml:"grpc_listen_addr"` // OTLP/gRPC
+	Path                              string                         `yaml:"path"`
+	TLS                               *configuration.ServerTLSConfig `yaml:"tls"` // http and grpc
+	MaxBodySize                       int64                          `yaml:"max_body_size"`
+	RawFormat                         string                         `yaml:"raw_format"`
+	AttributeLabels                   map[string]string              `yaml:"attribute_labels"`
+	Routes                            []RouteConfig                  `yaml:"routes"`
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+}
+
+// RouteConfig sets the labels of the log records matching the filter. The first matching route wins.
+type RouteConfig struct {
+	Filter string            `yaml:"filter"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type route struct {
+	filter *vm.Program
+	labels map[string]string
+}
+
+func ConfigurationFromYAML(y []byte) (Configuration, error) {
+	var cfg Configuration
+
+	if err := yaml.UnmarshalWithOptions(y, &cfg, yaml.Strict()); err != nil {
+		return cfg, fmt.Errorf("cannot parse: %s", yaml.FormatError(err, false, false))
+	}
+
+	cfg.SetDefaults()
+
+	err := cfg.Validate()
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Configuration) SetDefaults() {
+	if c.Mode == "" {
+		c.Mode = configuration.TAIL_MODE
+	}
+
+	if c.Path == "" {
+		c.Path = defaultPath
+	}
+
+	if c.MaxBodySize == 0 {
+		c.MaxBodySize = defaultMaxBodySize
+	}
+
+	if c.RawFormat == "" {
+		c.RawFormat = RawFormatBody
+	}
+}
+
+func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
+	cfg, err := ConfigurationFromYAML(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	s.config = cfg
+
+	return nil
+}
+
+func (c *Configuration) Validate() error {
+	if c.ListenAddr == "" && c.GRPCListenAddr == "" {
+		return errors.New("listen_addr or grpc_listen_addr is required")
+	}
+
+	if c.Path[0] != '/' {
+		return errors.New("path must start with /")
+	}
+
+	if c.MaxBodySize < 0 {
+		return errors.New("max_body_size must be positive")
+	}
+
+	switch c.RawFormat {
+	case RawFormatBody, RawFormatJSON:
+	default:
+		return fmt.Errorf("invalid raw_format '%s': must be one of %s, %s", c.RawFormat, RawFormatBody, RawFormatJSON)
+	}
+
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for idx, r := range c.Routes {
+		if r.Filter == "" {
+			return fmt.Errorf("route %d: filter is required", idx+1)
+		}
+
+		if len(r.Labels) == 0 {
+			return fmt.Errorf("route %d: labels are required", idx+1)
+		}
+	}
+
+	return nil
+}
+
+// routeEnv is the environment of the route filters, filled by recordEnv.
+func routeEnv() map[string]any {
+	return map[string]any{
+		"resource":        map[string]any{},
+		"scope":           "",
+		"attributes":      map[string]any{},
+		"body":            "",
+		"severity":        "",
+		"severity_number": 0,
+	}
+}
+
+func (c *Configuration) compileRoutes() ([]route, error) {
+	routes := make([]route, 0, len(c.Routes))
+
+	for idx, r := range c.Routes {
+		program, err := expr.Compile(r.Filter, append(exprhelpers.GetExprOptions(routeEnv()), expr.AsBool())...)
+		if err != nil {
+			return nil, fmt.Errorf("route %d: unable to compile filter '%s': %w", idx+1, r.Filter, err)
+		}
+
+		routes = append(routes, route{filter: program, labels: r.Labels})
+	}
+
+	return routes, nil
+}
+
+func (s *Source) Configure(_ context.Context, yamlConfig []byte, logger *log.Entry, metricsLevel metrics.AcquisitionMetricsLevel) error {
+	s.logger = logger
+	s.metricsLevel = metricsLevel
+
+	err := s.UnmarshalConfig(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	s.routes, err = s.config.compileRoutes()
+	if err != nil {
+		return err
+	}
+
+	if s.config.TLS != nil {
+		s.tlsConfig, err = s.config.TLS.NewTLSConfig()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
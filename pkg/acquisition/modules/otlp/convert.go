@@ -0,0 +1,207 @@
+package otlpacquisition
+
+import (
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/prometheus/client_golang/prometheus"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/structured"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// anyValue converts an OTLP value to the equivalent go value.
+func anyValue(v *commonpb.AnyValue) any {
+	switch val := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return val.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return val.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return val.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return val.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, 0, len(val.ArrayValue.GetValues()))
+		for _, item := range val.ArrayValue.GetValues() {
+			values = append(values, anyValue(item))
+		}
+
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return attributes(val.KvlistValue.GetValues())
+	default:
+		return nil
+	}
+}
+
+func attributes(kvs []*commonpb.KeyValue) map[string]any {
+	ret := make(map[string]any, len(kvs))
+
+	for _, kv := range kvs {
+		ret[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+
+	return ret
+}
+
+// record is a log record with the context of its resource and scope.
+type record struct {
+	resource   map[string]any
+	scope      string
+	attributes map[string]any
+	body       any
+	log        *logspb.LogRecord
+}
+
+// timestamp is the time of the event or, if unknown, the time the collector observed it.
+func (r *record) timestamp() time.Time {
+	if ts := r.log.GetTimeUnixNano(); ts != 0 {
+		return time.Unix(0, int64(ts)).UTC()
+	}
+
+	if ts := r.log.GetObservedTimeUnixNano(); ts != 0 {
+		return time.Unix(0, int64(ts)).UTC()
+	}
+
+	return time.Now().UTC()
+}
+
+func (r *record) env() map[string]any {
+	return map[string]any{
+		"resource":        r.resource,
+		"scope":           r.scope,
+		"attributes":      r.attributes,
+		"body":            r.body,
+		"severity":        r.log.GetSeverityText(),
+		"severity_number": int(r.log.GetSeverityNumber()),
+	}
+}
+
+// raw is the content of Line.Raw: the body, or with raw_format: json the whole record.
+func (r *record) raw(format string) (string, error) {
+	if format == RawFormatBody {
+		return structured.String(r.body), nil
+	}
+
+	doc := map[string]any{
+		"timestamp": r.timestamp().Format(time.RFC3339Nano),
+		"body":      r.body,
+	}
+
+	if len(r.resource) > 0 {
+		doc["resource"] = r.resource
+	}
+
+	if r.scope != "" {
+		doc["scope"] = r.scope
+	}
+
+	if len(r.attributes) > 0 {
+		doc["attributes"] = r.attributes
+	}
+
+	if sev := r.log.GetSeverityText(); sev != "" {
+		doc["severity"] = sev
+	}
+
+	if sev := r.log.GetSeverityNumber(); sev != logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED {
+		doc["severity_number"] = int(sev)
+	}
+
+	if id := r.log.GetTraceId(); len(id) > 0 {
+		doc["trace_id"] = hex.EncodeToString(id)
+	}
+
+	if id := r.log.GetSpanId(); len(id) > 0 {
+		doc["span_id"] = hex.EncodeToString(id)
+	}
+
+	return structured.JSON(doc)
+}
+
+// labels computes the labels of a record: the ones of the configuration, then attribute_labels
+// (the log attributes win over the resource ones), then the first matching route.
+func (s *Source) labels(r *record) map[string]string {
+	if len(s.config.AttributeLabels) == 0 && len(s.routes) == 0 {
+		return s.config.Labels
+	}
+
+	labels := structured.Labels(s.config.Labels, s.config.AttributeLabels, r.attributes, r.resource)
+
+	if len(s.routes) == 0 {
+		return labels
+	}
+
+	env := r.env()
+
+	for idx, rt := range s.routes {
+		match, err := expr.Run(rt.filter, env)
+		if err != nil {
+			s.logger.Errorf("unable to run the filter of route %d: %s", idx+1, err)
+			continue
+		}
+
+		if ok, _ := match.(bool); ok {
+			maps.Copy(labels, rt.labels)
+			break
+		}
+	}
+
+	return labels
+}
+
+// export sends the records of a request to the pipeline. source is the address of
+// the listener that received the request, src the address of the exporter.
+func (s *Source) export(resourceLogs []*logspb.ResourceLogs, source string, src string) error {
+	for _, rl := range resourceLogs {
+		resource := attributes(rl.GetResource().GetAttributes())
+
+		for _, sl := range rl.GetScopeLogs() {
+			scope := sl.GetScope().GetName()
+
+			for _, lr := range sl.GetLogRecords() {
+				r := &record{
+					resource:   resource,
+					scope:      scope,
+					attributes: attributes(lr.GetAttributes()),
+					body:       anyValue(lr.GetBody()),
+					log:        lr,
+				}
+
+				raw, err := r.raw(s.config.RawFormat)
+				if err != nil {
+					return fmt.Errorf("unable to serialize log record: %w", err)
+				}
+
+				labels := s.labels(r)
+
+				if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+					metrics.OTLPDataSourceLinesRead.With(prometheus.Labels{"source": source, "datasource_type": ModuleName, "acquis_type": labels["type"]}).Inc()
+				}
+
+				evt := pipeline.MakeEvent(s.config.UseTimeMachine, pipeline.LOG, true)
+				evt.Line = pipeline.Line{
+					Raw:     raw,
+					Labels:  labels,
+					Time:    r.timestamp(),
+					Src:     src,
+					Process: true,
+					Module:  s.GetName(),
+				}
+
+				s.outChan <- evt
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package otlpacquisition
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/acquisitiontest"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+// exportRequest builds a request with one log record per body.
+func exportRequest(service string, bodies ...string) *collogspb.ExportLogsServiceRequest {
+	records := make([]*logspb.LogRecord, 0, len(bodies))
+
+	for _, body := range bodies {
+		records = append(records, &logspb.LogRecord{
+			TimeUnixNano: uint64(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano()),
+			SeverityText: "INFO",
+			Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: body}},
+			Attributes:   []*commonpb.KeyValue{stringAttr("log.file.name", "access.log")},
+		})
+	}
+
+	return &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", service)}},
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						Scope:      &commonpb.InstrumentationScope{Name: "filelog"},
+						LogRecords: records,
+					},
+				},
+			},
+		},
+	}
+}
+
+func configureSource(t *testing.T, config string) (*Source, chan pipeline.Event) {
+	t.Helper()
+
+	s := &Source{}
+	acquisitiontest.Configure(t, s, config)
+
+	out := make(chan pipeline.Event, 10)
+	s.outChan = out
+
+	return s, out
+}
+
+func TestLabels(t *testing.T) {
+	s, out := configureSource(t, `
+source: otlp
+listen_addr: 127.0.0.1:4318
+labels:
+  type: syslog
+attribute_labels:
+  service.name: program
+  log.file.name: file
+routes:
+  - filter: resource["service.name"] == "nginx"
+    labels:
+      type: nginx
+  - filter: resource["service.name"] startsWith "ngi"
+    labels:
+      type: never
+`)
+
+	err := s.export(exportRequest("nginx", "GET /").GetResourceLogs(), "test", "1.2.3.4")
+	require.NoError(t, err)
+
+	err = s.export(exportRequest("sshd", "Failed password").GetResourceLogs(), "test", "1.2.3.4")
+	require.NoError(t, err)
+
+	evt := <-out
+	assert.Equal(t, map[string]string{"type": "nginx", "program": "nginx", "file": "access.log"}, evt.Line.Labels)
+	assert.Equal(t, "GET /", evt.Line.Raw)
+	assert.Equal(t, "1.2.3.4", evt.Line.Src)
+	assert.Equal(t, ModuleName, evt.Line.Module)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), evt.Line.Time)
+
+	evt = <-out
+	assert.Equal(t, map[string]string{"type": "syslog", "program": "sshd", "file": "access.log"}, evt.Line.Labels)
+	assert.Equal(t, "Failed password", evt.Line.Raw)
+
+	// the configuration labels are not modified
+	assert.Equal(t, map[string]string{"type": "syslog"}, s.config.Labels)
+}
+
+func TestRaw(t *testing.T) {
+	kvBody := &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+		Values: []*commonpb.KeyValue{
+			stringAttr("msg", "hello"),
+			{Key: "status", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 404}}},
+		},
+	}}}
+
+	r := &record{
+		resource:   map[string]any{"service.name": "nginx"},
+		scope:      "filelog",
+		attributes: map[string]any{},
+		body:       anyValue(kvBody),
+		log: &logspb.LogRecord{
+			TimeUnixNano:   uint64(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano()),
+			SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+			TraceId:        []byte{0xca, 0xfe},
+		},
+	}
+
+	raw, err := r.raw(RawFormatBody)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"msg": "hello", "status": 404}`, raw)
+
+	raw, err = r.raw(RawFormatJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"timestamp": "2026-01-02T03:04:05Z",
+		"body": {"msg": "hello", "status": 404},
+		"resource": {"service.name": "nginx"},
+		"scope": "filelog",
+		"severity_number": 13,
+		"trace_id": "cafe"
+	}`, raw)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	s, out := configureSource(t, `
+source: otlp
+listen_addr: 127.0.0.1:4318
+max_body_size: 1024
+labels:
+  type: syslog
+`)
+
+	protoBody, err := proto.Marshal(exportRequest("nginx", "line 1", "line 2"))
+	require.NoError(t, err)
+
+	jsonBody, err := protojson.Marshal(exportRequest("nginx", "line 1"))
+	require.NoError(t, err)
+
+	var gzipBody bytes.Buffer
+
+	gz := gzip.NewWriter(&gzipBody)
+	_, err = gz.Write(protoBody)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	tests := []struct {
+		name               string
+		method             string
+		contentType        string
+		contentEncoding    string
+		body               []byte
+		expectedStatusCode int
+		expectedEvents     int
+	}{
+		{
+			name:               "protobuf",
+			method:             http.MethodPost,
+			contentType:        "application/x-protobuf",
+			body:               protoBody,
+			expectedStatusCode: http.StatusOK,
+			expectedEvents:     2,
+		},
+		{
+			name:               "json",
+			method:             http.MethodPost,
+			contentType:        "application/json; charset=utf-8",
+			body:               jsonBody,
+			expectedStatusCode: http.StatusOK,
+			expectedEvents:     1,
+		},
+		{
+			name:               "gzip",
+			method:             http.MethodPost,
+			contentType:        "application/x-protobuf",
+			contentEncoding:    "gzip",
+			body:               gzipBody.Bytes(),
+			expectedStatusCode: http.StatusOK,
+			expectedEvents:     2,
+		},
+		{
+			name:               "invalid_body",
+			method:             http.MethodPost,
+			contentType:        "application/json",
+			body:               []byte("not json"),
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "too_large",
+			method:             http.MethodPost,
+			contentType:        "application/json",
+			body:               bytes.Repeat([]byte(" "), 2048),
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unsupported_content_type",
+			method:             http.MethodPost,
+			contentType:        "text/plain",
+			body:               []byte("line"),
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:               "invalid_method",
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/v1/logs", bytes.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+
+			if test.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", test.contentEncoding)
+			}
+
+			w := httptest.NewRecorder()
+
+			s.httpHandler(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.expectedStatusCode, res.StatusCode)
+			assert.Len(t, out, test.expectedEvents)
+
+			for range test.expectedEvents {
+				<-out
+			}
+		})
+	}
+}
+
+func TestGRPCExport(t *testing.T) {
+	ctx := t.Context()
+
+	s, out := configureSource(t, `
+source: otlp
+grpc_listen_addr: 127.0.0.1:49320
+labels:
+  type: syslog
+`)
+
+	cancel, done := acquisitiontest.StartStream(t, s, out)
+
+	conn, err := grpc.NewClient("127.0.0.1:49320", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	client := collogspb.NewLogsServiceClient(conn)
+
+	require.Eventually(t, func() bool {
+		_, err = client.Export(ctx, exportRequest("nginx", "line 1", "line 2"))
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	evt := <-out
+	assert.Equal(t, "line 1", evt.Line.Raw)
+	assert.Equal(t, "127.0.0.1", evt.Line.Src)
+	assert.Equal(t, "syslog", evt.Line.Labels["type"])
+
+	evt = <-out
+	assert.Equal(t, "line 2", evt.Line.Raw)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestStreamListenError(t *testing.T) {
+	ctx := t.Context()
+
+	listenConfig := &net.ListenConfig{}
+
+	// the address is already in use, the error is returned so the caller can restart the datasource
+	listener, err := listenConfig.Listen(ctx, "tcp", "127.0.0.1:49321")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	s, out := configureSource(t, `
+source: otlp
+listen_addr: 127.0.0.1:49322
+grpc_listen_addr: 127.0.0.1:49321
+labels:
+  type: syslog
+`)
+
+	err = s.Stream(ctx, out)
+	require.ErrorContains(t, err, "otlp/grpc server failed")
+}
@@ -0,0 +1,287 @@
+package otlpacquisition
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // accept gzip compressed exports
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/crowdsecurity/go-cs-lib/trace"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+func (s *Source) newHTTPServer(tlsConfig *tls.Config) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.config.Path, s.httpHandler)
+
+	server := &http.Server{
+		Addr:      s.config.ListenAddr,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+		Protocols: &http.Protocols{},
+	}
+
+	server.Protocols.SetHTTP1(true)
+	server.Protocols.SetUnencryptedHTTP2(true)
+	server.Protocols.SetHTTP2(true)
+
+	return server
+}
+
+func (s *Source) newGRPCServer(tlsConfig *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(int(s.config.MaxBodySize)),
+	}
+
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(opts...)
+	collogspb.RegisterLogsServiceServer(server, &logsService{source: s})
+
+	return server
+}
+
+// logsService implements the OTLP/gRPC logs service.
+type logsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	source *Source
+}
+
+func (l *logsService) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	src := ""
+
+	if p, ok := peer.FromContext(ctx); ok {
+		src, _, _ = net.SplitHostPort(p.Addr.String())
+	}
+
+	if err := l.source.export(req.GetResourceLogs(), l.source.config.GRPCListenAddr, src); err != nil {
+		l.source.logger.Errorf("export from %s failed: %s", src, err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// decodeRequest reads an OTLP/HTTP export request, in protobuf or json encoding.
+func (s *Source) decodeRequest(r *http.Request) (*collogspb.ExportLogsServiceRequest, string, error) {
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != contentTypeProtobuf && contentType != contentTypeJSON) {
+		return nil, "", fmt.Errorf("unsupported content type '%s'", r.Header.Get("Content-Type"))
+	}
+
+	var reader io.Reader = r.Body
+
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, contentType, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gz.Close()
+
+		// the limit applies to the uncompressed body too
+		reader = io.LimitReader(gz, s.config.MaxBodySize+1)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, contentType, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if int64(len(body)) > s.config.MaxBodySize {
+		return nil, contentType, fmt.Errorf("body size exceeds max body size: %d", s.config.MaxBodySize)
+	}
+
+	req := &collogspb.ExportLogsServiceRequest{}
+
+	if contentType == contentTypeJSON {
+		err = protojson.Unmarshal(body, req)
+	} else {
+		err = proto.Unmarshal(body, req)
+	}
+
+	if err != nil {
+		return nil, contentType, fmt.Errorf("failed to decode: %w", err)
+	}
+
+	return req, contentType, nil
+}
+
+func (s *Source) httpHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxBodySize)
+
+	req, contentType, err := s.decodeRequest(r)
+
+	switch {
+	case err != nil && contentType == "":
+		s.logger.Errorf("request from %s: %s", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+
+		return
+	case err != nil:
+		s.logger.Errorf("request from %s: %s", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	src, _, _ := net.SplitHostPort(r.RemoteAddr)
+
+	if err := s.export(req.GetResourceLogs(), s.config.ListenAddr, src); err != nil {
+		s.logger.Errorf("export from %s failed: %s", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	var resp []byte
+
+	if contentType == contentTypeJSON {
+		resp, err = protojson.Marshal(&collogspb.ExportLogsServiceResponse{})
+	} else {
+		resp, err = proto.Marshal(&collogspb.ExportLogsServiceResponse{})
+	}
+
+	if err != nil {
+		s.logger.Errorf("failed to encode response: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(resp); err != nil {
+		s.logger.Errorf("failed to write response: %v", err)
+	}
+}
+
+// shutdownTimeout is how long the pending exports have to complete when the datasource stops.
+const shutdownTimeout = 5 * time.Second
+
+// serveHTTP serves the OTLP/HTTP endpoint until the server is shut down.
+func (s *Source) serveHTTP(ctx context.Context, server *http.Server) error {
+	listenConfig := &net.ListenConfig{}
+
+	listener, err := listenConfig.Listen(ctx, "tcp", s.config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("otlp/http server failed: %w", err)
+	}
+
+	if server.TLSConfig != nil {
+		s.logger.Infof("start otlp/https server on %s%s", s.config.ListenAddr, s.config.Path)
+		// the certificates are already loaded in the tls config
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		s.logger.Infof("start otlp/http server on %s%s", s.config.ListenAddr, s.config.Path)
+		err = server.Serve(listener)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("otlp/http server failed: %w", err)
+	}
+
+	return nil
+}
+
+// serveGRPC serves the OTLP/gRPC endpoint until the server is stopped.
+func (s *Source) serveGRPC(ctx context.Context, server *grpc.Server) error {
+	listenConfig := &net.ListenConfig{}
+
+	listener, err := listenConfig.Listen(ctx, "tcp", s.config.GRPCListenAddr)
+	if err != nil {
+		return fmt.Errorf("otlp/grpc server failed: %w", err)
+	}
+
+	s.logger.Infof("start otlp/grpc server on %s", s.config.GRPCListenAddr)
+
+	if err := server.Serve(listener); err != nil {
+		return fmt.Errorf("otlp/grpc server failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
+	s.outChan = out
+
+	// the servers are created for each run, a stopped server cannot be started again
+	var (
+		httpServer *http.Server
+		grpcServer *grpc.Server
+	)
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	if s.config.ListenAddr != "" {
+		httpServer = s.newHTTPServer(s.tlsConfig)
+
+		g.Go(func() error {
+			defer trace.ReportPanic()
+			return s.serveHTTP(ctx, httpServer)
+		})
+	}
+
+	if s.config.GRPCListenAddr != "" {
+		grpcServer = s.newGRPCServer(s.tlsConfig)
+
+		g.Go(func() error {
+			defer trace.ReportPanic()
+			return s.serveGRPC(ctx, grpcServer)
+		})
+	}
+
+	// stop both servers when the datasource stops, or when one of them fails
+	g.Go(func() error {
+		<-ctx.Done()
+
+		s.logger.Infof("%s datasource stopping", s.GetName())
+
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+
+		if httpServer != nil {
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+			defer cancel()
+
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				s.logger.Errorf("while closing %s server: %s", s.GetName(), err)
+			}
+		}
+
+		return nil
+	})
+
+	return g.Wait()
+}
@@ -2,6 +2,7 @@ package syslogacquisition
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
@@ -9,15 +10,19 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	syslogserver "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/syslog/internal/server"
 	"github.com/crowdsecurity/crowdsec/pkg/metrics"
 )
 
 type Configuration struct {
-	Proto                             string `yaml:"protocol,omitempty"`
-	Port                              int    `yaml:"listen_port,omitempty"`
-	Addr                              string `yaml:"listen_addr,omitempty"`
-	MaxMessageLen                     int    `yaml:"max_message_len,omitempty"`
-	DisableRFCParser                  bool   `yaml:"disable_rfc_parser,omitempty"` // if true, we don't try to be smart and just remove the PRI
+	Proto            string `yaml:"protocol,omitempty"`
+	Port             int    `yaml:"listen_port,omitempty"`
+	Addr             string `yaml:"listen_addr,omitempty"`
+	MaxMessageLen    int    `yaml:"max_message_len,omitempty"`
+	DisableRFCParser bool   `yaml:"disable_rfc_parser,omitempty"` // if true, we don't try to be smart and just remove the PRI
+	// how the messages are delimited with tcp: auto, octet_counting or non_transparent
+	Framing                           syslogserver.Framing           `yaml:"framing,omitempty"`
+	TLS                               *configuration.ServerTLSConfig `yaml:"tls,omitempty"` // RFC5425
 	configuration.DataSourceCommonCfg `yaml:",inline"`
 }
 
@@ -42,12 +47,28 @@ func (c *Configuration) SetDefaults() {
 		c.Mode = configuration.TAIL_MODE
 	}
 
+	if c.Proto == "" {
+		c.Proto = "udp"
+
+		if c.TLS != nil {
+			c.Proto = "tcp"
+		}
+	}
+
+	if c.Framing == "" {
+		c.Framing = syslogserver.FramingAuto
+	}
+
 	if c.Addr == "" {
 		c.Addr = "127.0.0.1" // do we want a usable or secure default ?
 	}
 
 	if c.Port == 0 {
 		c.Port = 514
+
+		if c.TLS != nil {
+			c.Port = 6514
+		}
 	}
 
 	if c.MaxMessageLen == 0 {
@@ -64,6 +85,29 @@ func (c *Configuration) Validate() error {
 		return fmt.Errorf("invalid listen IP %s", c.Addr)
 	}
 
+	switch c.Proto {
+	case "udp", "tcp":
+	default:
+		return fmt.Errorf("invalid protocol %s, must be udp or tcp", c.Proto)
+	}
+
+	switch c.Framing {
+	case syslogserver.FramingAuto, syslogserver.FramingOctetCounting, syslogserver.FramingNonTransparent:
+	default:
+		return fmt.Errorf("invalid framing %s, must be one of %s, %s, %s", c.Framing,
+			syslogserver.FramingAuto, syslogserver.FramingOctetCounting, syslogserver.FramingNonTransparent)
+	}
+
+	if c.TLS != nil {
+		if c.Proto != "tcp" {
+			return errors.New("tls requires the tcp protocol")
+		}
+
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -75,6 +119,13 @@ func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
 
 	s.config = cfg
 
+	if cfg.TLS != nil {
+		s.tlsConfig, err = cfg.TLS.NewTLSConfig()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,146 @@
+package syslogserver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Framing is how the messages are delimited on a tcp connection (RFC6587).
+type Framing string
+
+const (
+	// detect the framing of each message: octet counting if it starts with a digit,
+	// non-transparent otherwise
+	FramingAuto Framing = "auto"
+	// "<length> <message>", mandatory for syslog over TLS (RFC5425)
+	FramingOctetCounting Framing = "octet_counting"
+	// one message per line
+	FramingNonTransparent Framing = "non_transparent"
+)
+
+var (
+	ErrFraming      = errors.New("invalid framing")
+	ErrTLSHandshake = errors.New("tls handshake failed")
+)
+
+// the length of an octet-counted message can't be longer than this
+const maxMsgLenDigits = 10
+
+// FrameReader splits a stream in syslog messages. Messages longer than maxMessageLen are truncated.
+type FrameReader struct {
+	r             *bufio.Reader
+	framing       Framing
+	maxMessageLen int
+}
+
+func NewFrameReader(r io.Reader, framing Framing, maxMessageLen int) *FrameReader {
+	if framing == "" {
+		framing = FramingAuto
+	}
+
+	return &FrameReader{
+		r:             bufio.NewReaderSize(r, maxMessageLen),
+		framing:       framing,
+		maxMessageLen: maxMessageLen,
+	}
+}
+
+// Next returns the next message, or nil at the end of the stream.
+func (f *FrameReader) Next() ([]byte, error) {
+	for {
+		first, err := f.r.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		var msg []byte
+
+		switch {
+		case f.framing == FramingOctetCounting, f.framing == FramingAuto && first[0] >= '0' && first[0] <= '9':
+			msg, err = f.readOctetCounted()
+		default:
+			msg, err = f.readLine()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// skip empty lines, some senders add a trailer after octet-counted messages too
+		if len(msg) > 0 {
+			return msg, nil
+		}
+	}
+}
+
+func (f *FrameReader) readOctetCounted() ([]byte, error) {
+	var digits []byte
+
+	for {
+		c, err := f.r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		if c == ' ' {
+			break
+		}
+
+		if c < '0' || c > '9' || len(digits) == maxMsgLenDigits {
+			return nil, fmt.Errorf("%w: expected a message length", ErrFraming)
+		}
+
+		digits = append(digits, c)
+	}
+
+	length, err := strconv.Atoi(string(digits))
+	if err != nil || length == 0 {
+		return nil, fmt.Errorf("%w: invalid message length %q", ErrFraming, digits)
+	}
+
+	msg := make([]byte, min(length, f.maxMessageLen))
+
+	if _, err := io.ReadFull(f.r, msg); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if _, err := f.r.Discard(length - len(msg)); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return msg, nil
+}
+
+func (f *FrameReader) readLine() ([]byte, error) {
+	line, err := f.r.ReadSlice('\n')
+	// the slice is overwritten by the next read
+	msg := bytes.Clone(bytes.TrimRight(line, "\r\n"))
+
+	// too long, keep the beginning and drop the rest of the line
+	for errors.Is(err, bufio.ErrBufferFull) {
+		_, err = f.r.ReadSlice('\n')
+	}
+
+	// the last message has no trailer
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return msg[:min(len(msg), f.maxMessageLen)], nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated message", ErrFraming)
+	}
+
+	return err
+}
@@ -0,0 +1,102 @@
+package syslogserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+func TestFrameReader(t *testing.T) {
+	tests := []struct {
+		name          string
+		framing       Framing
+		maxMessageLen int
+		input         string
+		expected      []string
+		expectedErr   string
+	}{
+		{
+			name:     "non transparent",
+			framing:  FramingNonTransparent,
+			input:    "<13>msg 1\n<13>msg 2\r\n\n<13>msg 3",
+			expected: []string{"<13>msg 1", "<13>msg 2", "<13>msg 3"},
+		},
+		{
+			name:     "octet counting",
+			framing:  FramingOctetCounting,
+			input:    "9 <13>msg 110 <13>msg\n 2",
+			expected: []string{"<13>msg 1", "<13>msg\n 2"},
+		},
+		{
+			name:     "auto",
+			framing:  FramingAuto,
+			input:    "9 <13>msg 1<13>msg 2\n9 <13>msg 3\n",
+			expected: []string{"<13>msg 1", "<13>msg 2", "<13>msg 3"},
+		},
+		{
+			name:          "truncated messages",
+			framing:       FramingAuto,
+			maxMessageLen: 16,
+			input:         "20 <13>message number 1<13>message number 2\n<13>msg 3\n",
+			expected:      []string{"<13>message numb", "<13>message numb", "<13>msg 3"},
+		},
+		{
+			name:        "octet counting without a length",
+			framing:     FramingOctetCounting,
+			input:       "<13>msg 1\n",
+			expectedErr: "invalid framing: expected a message length",
+		},
+		{
+			name:        "length too long",
+			framing:     FramingOctetCounting,
+			input:       "12345678901 <13>msg 1",
+			expectedErr: "invalid framing: expected a message length",
+		},
+		{
+			name:        "zero length",
+			framing:     FramingOctetCounting,
+			input:       "0 <13>msg 1",
+			expectedErr: `invalid framing: invalid message length "0"`,
+		},
+		{
+			name:        "incomplete message",
+			framing:     FramingOctetCounting,
+			input:       "9 <13>msg 1100 <13>msg 2",
+			expected:    []string{"<13>msg 1"},
+			expectedErr: "invalid framing: truncated message",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			maxMessageLen := tc.maxMessageLen
+			if maxMessageLen == 0 {
+				maxMessageLen = 2048
+			}
+
+			reader := NewFrameReader(strings.NewReader(tc.input), tc.framing, maxMessageLen)
+
+			var (
+				got []string
+				err error
+			)
+
+			for {
+				var frame []byte
+
+				frame, err = reader.Next()
+				if err != nil || frame == nil {
+					break
+				}
+
+				got = append(got, string(frame))
+			}
+
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -2,18 +2,31 @@ package syslogserver
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type SyslogServer struct {
 	conn          *net.UDPConn
+	listener      net.Listener
 	Logger        *log.Entry
 	MaxMessageLen int
+	// "udp" or "tcp"
+	Protocol string
+	// if set, the tcp connections are wrapped in TLS (RFC5425)
+	TLSConfig *tls.Config
+	Framing   Framing
+	// optional, called when a tcp connection is accepted and when it's closed.
+	// err is nil if the client closed the connection or the server is shutting down
+	OnConnect    func(client string)
+	OnDisconnect func(client string, err error)
 }
 
 type SyslogMessage struct {
@@ -22,6 +35,10 @@ type SyslogMessage struct {
 }
 
 func (s *SyslogServer) Listen(listenAddr string, port int) error {
+	if s.Protocol == "tcp" {
+		return s.listenTCP(listenAddr, port)
+	}
+
 	udpAddr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(listenAddr, strconv.Itoa(port)))
 	if err != nil {
 		return fmt.Errorf("could not resolve addr %s: %w", listenAddr, err)
@@ -38,7 +55,23 @@ func (s *SyslogServer) Listen(listenAddr string, port int) error {
 	return nil
 }
 
+func (s *SyslogServer) listenTCP(listenAddr string, port int) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(listenAddr, strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("could not listen on port %d: %w", port, err)
+	}
+
+	s.Logger.Debugf("listening on %s:%d (tcp, tls: %t)", listenAddr, port, s.TLSConfig != nil)
+	s.listener = listener
+
+	return nil
+}
+
 func (s *SyslogServer) Serve(ctx context.Context, msgChan chan SyslogMessage) error {
+	if s.listener != nil {
+		return s.serveTCP(ctx, msgChan)
+	}
+
 	go func() {
 		<-ctx.Done()
 		// closing the socket unblocks ReadFrom()
@@ -69,7 +102,139 @@ func (s *SyslogServer) Serve(ctx context.Context, msgChan chan SyslogMessage) er
 	}
 }
 
+func (s *SyslogServer) serveTCP(ctx context.Context, msgChan chan SyslogMessage) error {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		conns = make(map[net.Conn]struct{})
+	)
+
+	go func() {
+		<-ctx.Done()
+		// unblocks Accept() and the pending reads
+		s.listener.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	// the caller closes msgChan when we return
+	defer wg.Wait()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil //nolint:nilerr  // context cancelation is not a failure
+			}
+
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+
+		mu.Lock()
+
+		if ctx.Err() != nil {
+			mu.Unlock()
+			conn.Close()
+
+			return nil
+		}
+
+		conns[conn] = struct{}{}
+		mu.Unlock()
+
+		wg.Go(func() {
+			defer func() {
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+			}()
+
+			s.handleConn(ctx, conn, msgChan)
+		})
+	}
+}
+
+func clientAddr(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return host
+}
+
+func (s *SyslogServer) handleConn(ctx context.Context, conn net.Conn, msgChan chan SyslogMessage) {
+	defer conn.Close()
+
+	client := clientAddr(conn.RemoteAddr())
+	logger := s.Logger.WithField("client", client)
+
+	if s.OnConnect != nil {
+		s.OnConnect(client)
+	}
+
+	err := s.readConn(ctx, conn, client, msgChan)
+	if ctx.Err() != nil {
+		err = nil
+	}
+
+	if err != nil {
+		logger.Warnf("closing connection: %s", err)
+	} else {
+		logger.Debug("connection closed")
+	}
+
+	if s.OnDisconnect != nil {
+		s.OnDisconnect(client, err)
+	}
+}
+
+func (s *SyslogServer) readConn(ctx context.Context, conn net.Conn, client string, msgChan chan SyslogMessage) error {
+	if s.TLSConfig != nil {
+		tlsConn := tls.Server(conn, s.TLSConfig)
+
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("%w: %w", ErrTLSHandshake, err)
+		}
+
+		conn = tlsConn
+	}
+
+	reader := NewFrameReader(conn, s.Framing, s.MaxMessageLen)
+
+	for {
+		frame, err := reader.Next()
+		if err != nil {
+			return err
+		}
+
+		if frame == nil {
+			// client closed the connection
+			return nil
+		}
+
+		select {
+		case msgChan <- SyslogMessage{Message: frame, Client: client}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 func (s *SyslogServer) KillServer() error {
+	if s.listener != nil {
+		if err := s.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			return fmt.Errorf("could not close TCP listener: %w", err)
+		}
+
+		return nil
+	}
+
 	if err := s.conn.Close(); err != nil {
 		return fmt.Errorf("could not close UDP connection: %w", err)
 	}
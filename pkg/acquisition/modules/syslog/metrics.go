@@ -1,8 +1,11 @@
 package syslogacquisition
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
 
+	syslogserver "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/syslog/internal/server"
 	"github.com/crowdsecurity/crowdsec/pkg/metrics"
 )
 
@@ -10,6 +13,9 @@ func (*Source) GetMetrics() []prometheus.Collector {
 	return []prometheus.Collector{
 		metrics.SyslogDataSourceLinesReceived,
 		metrics.SyslogDataSourceLinesParsed,
+		metrics.SyslogDataSourceConnections,
+		metrics.SyslogDataSourceActiveConnections,
+		metrics.SyslogDataSourceConnectionErrors,
 	}
 }
 
@@ -17,5 +23,49 @@ func (*Source) GetAggregMetrics() []prometheus.Collector {
 	return []prometheus.Collector{
 		metrics.SyslogDataSourceLinesReceived,
 		metrics.SyslogDataSourceLinesParsed,
+		metrics.SyslogDataSourceConnections,
+		metrics.SyslogDataSourceActiveConnections,
+		metrics.SyslogDataSourceConnectionErrors,
+	}
+}
+
+// protocolLabel tells plain tcp from tls in the connection metrics
+func (s *Source) protocolLabel() string {
+	if s.tlsConfig != nil {
+		return "tls"
+	}
+
+	return s.config.Proto
+}
+
+func connectionErrorReason(err error) string {
+	switch {
+	case errors.Is(err, syslogserver.ErrTLSHandshake):
+		return "tls_handshake"
+	case errors.Is(err, syslogserver.ErrFraming):
+		return "framing"
+	default:
+		return "read"
+	}
+}
+
+func (s *Source) onConnect(client string) {
+	if s.metricsLevel == metrics.AcquisitionMetricsLevelNone {
+		return
+	}
+
+	metrics.SyslogDataSourceConnections.With(prometheus.Labels{"source": client, "protocol": s.protocolLabel(), "datasource_type": ModuleName, "acquis_type": s.config.Labels["type"]}).Inc()
+	metrics.SyslogDataSourceActiveConnections.With(prometheus.Labels{"protocol": s.protocolLabel(), "datasource_type": ModuleName, "acquis_type": s.config.Labels["type"]}).Inc()
+}
+
+func (s *Source) onDisconnect(client string, err error) {
+	if s.metricsLevel == metrics.AcquisitionMetricsLevelNone {
+		return
+	}
+
+	metrics.SyslogDataSourceActiveConnections.With(prometheus.Labels{"protocol": s.protocolLabel(), "datasource_type": ModuleName, "acquis_type": s.config.Labels["type"]}).Dec()
+
+	if err != nil {
+		metrics.SyslogDataSourceConnectionErrors.With(prometheus.Labels{"source": client, "reason": connectionErrorReason(err), "protocol": s.protocolLabel(), "datasource_type": ModuleName, "acquis_type": s.config.Labels["type"]}).Inc()
 	}
 }
@@ -23,6 +23,11 @@ func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
 	srv := &syslogserver.SyslogServer{
 		Logger:        s.logger.WithField("syslog", "internal"),
 		MaxMessageLen: s.config.MaxMessageLen,
+		Protocol:      s.config.Proto,
+		TLSConfig:     s.tlsConfig,
+		Framing:       s.config.Framing,
+		OnConnect:     s.onConnect,
+		OnDisconnect:  s.onDisconnect,
 	}
 
 	msgChan := make(chan syslogserver.SyslogMessage)
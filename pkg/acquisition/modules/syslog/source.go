@@ -1,6 +1,8 @@
 package syslogacquisition
 
 import (
+	"crypto/tls"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/crowdsecurity/crowdsec/pkg/metrics"
@@ -10,6 +12,7 @@ type Source struct {
 	metricsLevel metrics.AcquisitionMetricsLevel
 	config       Configuration
 	logger       *log.Entry
+	tlsConfig    *tls.Config
 }
 
 func (s *Source) GetUuid() string {
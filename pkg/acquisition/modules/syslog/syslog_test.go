@@ -2,13 +2,19 @@ package syslogacquisition
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
+	"os"
 	"runtime"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 
@@ -167,3 +173,256 @@ disable_rfc_parser: true`,
 		})
 	}
 }
+
+func octetCounted(msg string) string {
+	return fmt.Sprintf("%d %s", len(msg), msg)
+}
+
+func clientTLSConfig(t *testing.T, withCert bool) *tls.Config {
+	t.Helper()
+
+	// the test certificates are shared by the datasources that accept TLS connections
+	caCert, err := os.ReadFile("../../testdata/tls/ca.crt")
+	require.NoError(t, err)
+
+	caCertPool := x509.NewCertPool()
+	caCertPool.AppendCertsFromPEM(caCert)
+
+	tlsConfig := &tls.Config{
+		RootCAs:    caCertPool,
+		ServerName: "localhost",
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if withCert {
+		cert, err := tls.LoadX509KeyPair("../../testdata/tls/client.crt", "../../testdata/tls/client.key")
+		require.NoError(t, err)
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig
+}
+
+func TestStreamingAcquisitionTCP(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		name          string
+		config        string
+		clientTLS     *tls.Config
+		payload       string
+		expectedLines int
+		// reason of the connection error, if any
+		expectedErr string
+	}{
+		{
+			name: "tcp with mixed framing",
+			config: `source: syslog
+protocol: tcp
+listen_port: 4243
+listen_addr: 127.0.0.1
+labels:
+  type: syslog-tcp`,
+			payload: "<13>May 18 12:37:56 mantis sshd[49340]: line 1\n" +
+				octetCounted("<13>May 18 12:37:56 mantis sshd[49340]: line 2") +
+				octetCounted("<13>May 18 12:37:56 mantis sshd[49340]: line 3"),
+			expectedLines: 3,
+		},
+		{
+			name: "tcp octet counting only",
+			config: `source: syslog
+protocol: tcp
+framing: octet_counting
+listen_port: 4243
+listen_addr: 127.0.0.1
+labels:
+  type: syslog-framing`,
+			payload:       "<13>May 18 12:37:56 mantis sshd[49340]: line 1\n",
+			expectedLines: 0,
+			expectedErr:   "framing",
+		},
+		{
+			name: "tls with client certificate",
+			config: `source: syslog
+listen_port: 4243
+listen_addr: 127.0.0.1
+tls:
+  server_cert: ../../testdata/tls/server.crt
+  server_key: ../../testdata/tls/server.key
+  ca_cert: ../../testdata/tls/ca.crt
+labels:
+  type: syslog-tls`,
+			clientTLS:     clientTLSConfig(t, true),
+			payload:       octetCounted("<13>May 18 12:37:56 mantis sshd[49340]: line 1"),
+			expectedLines: 1,
+		},
+		{
+			name: "tls without client certificate",
+			config: `source: syslog
+listen_port: 4243
+listen_addr: 127.0.0.1
+tls:
+  server_cert: ../../testdata/tls/server.crt
+  server_key: ../../testdata/tls/server.key
+  ca_cert: ../../testdata/tls/ca.crt
+labels:
+  type: syslog-nocert`,
+			clientTLS:     clientTLSConfig(t, false),
+			payload:       octetCounted("<13>May 18 12:37:56 mantis sshd[49340]: line 1"),
+			expectedLines: 0,
+			expectedErr:   "tls_handshake",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Source{}
+
+			err := s.Configure(ctx, []byte(tc.config), log.WithField("type", ModuleName), metrics.AcquisitionMetricsLevelFull)
+			require.NoError(t, err)
+
+			acquisType := s.config.Labels["type"]
+			protocol := s.protocolLabel()
+
+			out := make(chan pipeline.Event)
+
+			g, gctx := errgroup.WithContext(ctx)
+
+			gctx, cancel := context.WithCancel(gctx)
+			defer cancel()
+
+			g.Go(func() error {
+				return s.Stream(gctx, out)
+			})
+
+			var conn net.Conn
+
+			require.Eventually(t, func() bool {
+				dialer := &net.Dialer{}
+
+				if tc.clientTLS != nil {
+					conn, err = (&tls.Dialer{NetDialer: dialer, Config: tc.clientTLS}).DialContext(gctx, "tcp", "127.0.0.1:4243")
+				} else {
+					conn, err = dialer.DialContext(gctx, "tcp", "127.0.0.1:4243")
+				}
+
+				return err == nil
+			}, 2*time.Second, 50*time.Millisecond)
+
+			// with TLS 1.3, a missing client certificate is only reported on the first read or write
+			_, _ = fmt.Fprint(conn, tc.payload)
+
+			actualLines := 0
+
+			require.Eventually(t, func() bool {
+				for {
+					select {
+					case <-out:
+						actualLines++
+					default:
+						return actualLines == tc.expectedLines
+					}
+				}
+			}, 1*time.Second, 50*time.Millisecond)
+
+			active := metrics.SyslogDataSourceActiveConnections.With(prometheus.Labels{"protocol": protocol, "datasource_type": ModuleName, "acquis_type": acquisType})
+
+			if tc.expectedErr != "" {
+				require.Eventually(t, func() bool {
+					return testutil.ToFloat64(metrics.SyslogDataSourceConnectionErrors.With(prometheus.Labels{
+						"source": "127.0.0.1", "reason": tc.expectedErr, "protocol": protocol, "datasource_type": ModuleName, "acquis_type": acquisType,
+					})) == 1
+				}, 1*time.Second, 50*time.Millisecond)
+				assert.InDelta(t, 0, testutil.ToFloat64(active), 0)
+			} else {
+				assert.InDelta(t, 1, testutil.ToFloat64(active), 0)
+			}
+
+			conn.Close()
+
+			// closed by the client
+			require.Eventually(t, func() bool {
+				return testutil.ToFloat64(active) == 0
+			}, 1*time.Second, 50*time.Millisecond)
+
+			cancel()
+
+			err = g.Wait()
+			require.NoError(t, err)
+
+			assert.InDelta(t, 1, testutil.ToFloat64(metrics.SyslogDataSourceConnections.With(prometheus.Labels{
+				"source": "127.0.0.1", "protocol": protocol, "datasource_type": ModuleName, "acquis_type": acquisType,
+			})), 0)
+		})
+	}
+}
+
+func TestConfigurationTLS(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           string
+		expectedErr      string
+		expectedProtocol string
+		expectedPort     int
+	}{
+		{
+			name: "tls defaults",
+			config: `source: syslog
+tls:
+  server_cert: ../../testdata/tls/server.crt
+  server_key: ../../testdata/tls/server.key`,
+			expectedProtocol: "tcp",
+			expectedPort:     6514,
+		},
+		{
+			name: "tls over udp",
+			config: `source: syslog
+protocol: udp
+tls:
+  server_cert: ../../testdata/tls/server.crt
+  server_key: ../../testdata/tls/server.key`,
+			expectedErr: "tls requires the tcp protocol",
+		},
+		{
+			name: "missing key",
+			config: `source: syslog
+tls:
+  server_cert: ../../testdata/tls/server.crt`,
+			expectedErr: "server_key is required",
+		},
+		{
+			name: "invalid framing",
+			config: `source: syslog
+protocol: tcp
+framing: lines`,
+			expectedErr: "invalid framing lines, must be one of auto, octet_counting, non_transparent",
+		},
+		{
+			name: "invalid ca",
+			config: `source: syslog
+tls:
+  server_cert: ../../testdata/tls/server.crt
+  server_key: ../../testdata/tls/server.key
+  ca_cert: ../../testdata/tls/server.key`,
+			expectedErr: "no certificate found in ../../testdata/tls/server.key",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Source{}
+
+			err := s.UnmarshalConfig([]byte(tc.config))
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			assert.Equal(t, tc.expectedProtocol, s.config.Proto)
+			assert.Equal(t, tc.expectedPort, s.config.Port)
+			assert.NotNil(t, s.tlsConfig)
+		})
+	}
+}
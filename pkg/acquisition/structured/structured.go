@@ -0,0 +1,58 @@
+// Package structured has the helpers of the datasources that receive structured records
+// (fluentd records, OTLP log records...) instead of log lines.
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// String converts a value of a record to a label, or to Line.Raw. Structured values are serialized to json.
+func String(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool, int64, uint64, float64:
+		return fmt.Sprint(val)
+	default:
+		ret, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+
+		return string(ret)
+	}
+}
+
+// JSON serializes a whole record to Line.Raw.
+func JSON(v any) (string, error) {
+	ret, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(ret), nil
+}
+
+// Labels returns a copy of the labels of the configuration, with the labels set from the
+// fields of a record (field -> label). If a field is in more than one record, the first wins.
+func Labels(labels map[string]string, fields map[string]string, records ...map[string]any) map[string]string {
+	ret := maps.Clone(labels)
+	if ret == nil {
+		ret = make(map[string]string, len(fields))
+	}
+
+	for field, label := range fields {
+		for _, record := range records {
+			if v, ok := record[field]; ok {
+				ret[label] = String(v)
+				break
+			}
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,45 @@
+package structured
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{nil, ""},
+		{"line", "line"},
+		{true, "true"},
+		{int64(-42), "-42"},
+		{uint64(42), "42"},
+		{1.5, "1.5"},
+		{[]any{"a", int64(1)}, `["a",1]`},
+		{map[string]any{"k": "v"}, `{"k":"v"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			assert.Equal(t, test.expected, String(test.value))
+		})
+	}
+}
+
+func TestLabels(t *testing.T) {
+	config := map[string]string{"type": "syslog"}
+	fields := map[string]string{"service.name": "service", "host.name": "host"}
+
+	attributes := map[string]any{"service.name": "nginx"}
+	resource := map[string]any{"service.name": "ignored", "host.name": "web1"}
+
+	labels := Labels(config, fields, attributes, resource)
+	assert.Equal(t, map[string]string{"type": "syslog", "service": "nginx", "host": "web1"}, labels)
+
+	// the configuration is not modified
+	assert.Equal(t, map[string]string{"type": "syslog"}, config)
+
+	assert.Equal(t, map[string]string{"host": "web1"}, Labels(nil, fields, map[string]any{"host.name": "web1"}))
+}
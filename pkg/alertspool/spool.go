@@ -0,0 +1,354 @@
+// Package alertspool keeps on disk the alerts that the agent could not send to the LAPI,
+// so they survive a restart or a crash and are sent in order once the LAPI is back.
+package alertspool
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// ErrFull is returned when a batch is dropped because the spool is full.
+var ErrFull = errors.New("alert spool is full")
+
+const (
+	segmentExt = ".json"
+	tmpPrefix  = ".tmp-"
+)
+
+// a batch of alerts, as it was pushed to the LAPI
+type segment struct {
+	seq     uint64
+	alerts  int
+	size    int64
+	modTime time.Time
+}
+
+// Batch is the oldest batch of alerts in the spool, to be removed once it's sent.
+type Batch struct {
+	Seq    uint64
+	Alerts []pipeline.RuntimeAlert
+}
+
+// Spool stores the batches of alerts in a directory, one file per batch. A file is written
+// atomically, a crash can't leave a partial batch behind.
+type Spool struct {
+	dir        string
+	maxAlerts  int
+	maxSize    int64
+	maxAge     time.Duration
+	dropOldest bool
+
+	mu       sync.Mutex
+	segments []segment
+	alerts   int
+	size     int64
+	nextSeq  uint64
+	now      func() time.Time
+}
+
+// New opens the spool, and loads the batches left by a previous run.
+func New(cfg *csconfig.AlertSpoolCfg) (*Spool, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("while creating alert spool: %w", err)
+	}
+
+	s := &Spool{
+		dir:        cfg.Dir,
+		maxAlerts:  cfg.MaxAlerts,
+		maxSize:    int64(cfg.MaxSize) * 1024 * 1024,
+		maxAge:     cfg.MaxAge,
+		dropOldest: cfg.DropPolicy != csconfig.AlertSpoolDropNewest,
+		nextSeq:    1,
+		now:        time.Now,
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Spool) path(seq uint64) string {
+	// zero padded, to list them in order
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+func (s *Spool) load() error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("while reading alert spool: %w", err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+
+		if strings.HasPrefix(name, tmpPrefix) {
+			// interrupted write
+			_ = os.Remove(filepath.Join(s.dir, name))
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if file.IsDir() || !strings.HasSuffix(name, segmentExt) || err != nil {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return err
+		}
+
+		alerts, err := s.read(seq)
+		if err != nil {
+			log.Warnf("dropping unreadable alert spool file %s: %s", name, err)
+			metrics.AlertSpoolDropped.With(prometheus.Labels{"reason": "corrupted"}).Inc()
+
+			_ = os.Remove(s.path(seq))
+
+			continue
+		}
+
+		s.segments = append(s.segments, segment{seq: seq, alerts: len(alerts), size: info.Size(), modTime: info.ModTime()})
+		s.alerts += len(alerts)
+		s.size += info.Size()
+		s.nextSeq = max(s.nextSeq, seq+1)
+	}
+
+	slices.SortFunc(s.segments, func(a, b segment) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	s.expire()
+
+	if s.alerts > 0 {
+		log.Infof("%d alerts waiting in the spool (%s) will be sent to the LAPI", s.alerts, s.dir)
+	}
+
+	s.updateMetrics()
+
+	return nil
+}
+
+func (s *Spool) read(seq uint64) ([]pipeline.RuntimeAlert, error) {
+	data, err := os.ReadFile(s.path(seq))
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []pipeline.RuntimeAlert
+
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+func (s *Spool) updateMetrics() {
+	metrics.AlertSpoolAlerts.Set(float64(s.alerts))
+	metrics.AlertSpoolBytes.Set(float64(s.size))
+}
+
+// drop removes the oldest batch. The lock must be held.
+func (s *Spool) drop(reason string) {
+	seg := s.segments[0]
+
+	if err := os.Remove(s.path(seg.seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Errorf("while removing alert spool file: %s", err)
+	}
+
+	s.segments = s.segments[1:]
+	s.alerts -= seg.alerts
+	s.size -= seg.size
+
+	log.Warnf("dropping %d alerts from the spool (%s)", seg.alerts, reason)
+	metrics.AlertSpoolDropped.With(prometheus.Labels{"reason": reason}).Add(float64(seg.alerts))
+}
+
+// expire drops the batches older than max_age. The lock must be held.
+func (s *Spool) expire() {
+	now := s.now()
+
+	for len(s.segments) > 0 && now.Sub(s.segments[0].modTime) > s.maxAge {
+		s.drop("max_age")
+	}
+}
+
+// overLimit returns the limit that would be exceeded by adding a batch, if any.
+func (s *Spool) overLimit(alerts int, size int64) string {
+	switch {
+	case s.alerts+alerts > s.maxAlerts:
+		return "max_alerts"
+	case s.size+size > s.maxSize:
+		return "max_size"
+	default:
+		return ""
+	}
+}
+
+// Add appends a batch of alerts, after the ones already in the spool.
+func (s *Spool) Add(alerts []pipeline.RuntimeAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("while serializing alerts: %w", err)
+	}
+
+	size := int64(len(data))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer s.updateMetrics()
+
+	s.expire()
+
+	// would not fit even in an empty spool
+	if len(alerts) > s.maxAlerts {
+		return s.reject(alerts, "max_alerts")
+	}
+
+	if size > s.maxSize {
+		return s.reject(alerts, "max_size")
+	}
+
+	for reason := s.overLimit(len(alerts), size); reason != ""; reason = s.overLimit(len(alerts), size) {
+		if !s.dropOldest {
+			return s.reject(alerts, reason)
+		}
+
+		s.drop(reason)
+	}
+
+	seq := s.nextSeq
+
+	if err := writeFileSync(s.dir, s.path(seq), data); err != nil {
+		return fmt.Errorf("while writing to the alert spool: %w", err)
+	}
+
+	s.nextSeq++
+	s.segments = append(s.segments, segment{seq: seq, alerts: len(alerts), size: size, modTime: s.now()})
+	s.alerts += len(alerts)
+	s.size += size
+
+	return nil
+}
+
+func (*Spool) reject(alerts []pipeline.RuntimeAlert, reason string) error {
+	metrics.AlertSpoolDropped.With(prometheus.Labels{"reason": reason}).Add(float64(len(alerts)))
+	return fmt.Errorf("%w (%s): dropping %d alerts", ErrFull, reason, len(alerts))
+}
+
+// Len returns the number of alerts in the spool.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.alerts
+}
+
+// Oldest returns the first batch to send, or nil if the spool is empty.
+func (s *Spool) Oldest() *Batch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer s.updateMetrics()
+
+	s.expire()
+
+	for len(s.segments) > 0 {
+		seq := s.segments[0].seq
+
+		alerts, err := s.read(seq)
+		if err == nil {
+			return &Batch{Seq: seq, Alerts: alerts}
+		}
+
+		log.Warnf("dropping unreadable alert spool file %s: %s", s.path(seq), err)
+		s.drop("corrupted")
+	}
+
+	return nil
+}
+
+// Remove deletes a batch once it has been sent.
+func (s *Spool) Remove(seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer s.updateMetrics()
+
+	idx := slices.IndexFunc(s.segments, func(seg segment) bool { return seg.seq == seq })
+	if idx == -1 {
+		// expired in the meantime
+		return nil
+	}
+
+	seg := s.segments[idx]
+
+	if err := os.Remove(s.path(seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("while removing alert spool file: %w", err)
+	}
+
+	s.segments = slices.Delete(s.segments, idx, idx+1)
+	s.alerts -= seg.alerts
+	s.size -= seg.size
+
+	return nil
+}
+
+// writeFileSync writes a file atomically and makes sure it's on disk before returning.
+func writeFileSync(dir string, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// persist the rename too, not supported on all platforms
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
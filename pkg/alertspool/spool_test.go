@@ -0,0 +1,207 @@
+package alertspool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func testConfig(t *testing.T) *csconfig.AlertSpoolCfg {
+	t.Helper()
+
+	return &csconfig.AlertSpoolCfg{
+		Enable:     new(true),
+		Dir:        t.TempDir(),
+		MaxAlerts:  100,
+		MaxSize:    1,
+		MaxAge:     time.Hour,
+		DropPolicy: csconfig.AlertSpoolDropOldest,
+	}
+}
+
+func makeAlerts(scenarios ...string) []pipeline.RuntimeAlert {
+	ret := make([]pipeline.RuntimeAlert, 0, len(scenarios))
+
+	for _, scenario := range scenarios {
+		ret = append(ret, pipeline.RuntimeAlert{Alert: &models.Alert{Scenario: new(scenario)}})
+	}
+
+	return ret
+}
+
+func scenarios(batch *Batch) []string {
+	ret := []string{}
+
+	for _, alert := range batch.Alerts {
+		ret = append(ret, *alert.Alert.Scenario)
+	}
+
+	return ret
+}
+
+func TestSpool(t *testing.T) {
+	cfg := testConfig(t)
+
+	spool, err := New(cfg)
+	require.NoError(t, err)
+
+	assert.Nil(t, spool.Oldest())
+
+	require.NoError(t, spool.Add(makeAlerts("a", "b")))
+	require.NoError(t, spool.Add(makeAlerts("c")))
+	assert.Equal(t, 3, spool.Len())
+	assert.InDelta(t, 3, testutil.ToFloat64(metrics.AlertSpoolAlerts), 0)
+
+	// left by an interrupted write, or not ours
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Dir, ".tmp-1234"), []byte("[{"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Dir, "README"), []byte("hello"), 0o600))
+
+	// still there after a restart
+	spool, err = New(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, spool.Len())
+	assert.NoFileExists(t, filepath.Join(cfg.Dir, ".tmp-1234"))
+	assert.FileExists(t, filepath.Join(cfg.Dir, "README"))
+
+	require.NoError(t, spool.Add(makeAlerts("d")))
+
+	// in order
+	batch := spool.Oldest()
+	require.NotNil(t, batch)
+	assert.Equal(t, []string{"a", "b"}, scenarios(batch))
+
+	// until it's removed
+	assert.Equal(t, batch.Seq, spool.Oldest().Seq)
+	require.NoError(t, spool.Remove(batch.Seq))
+
+	batch = spool.Oldest()
+	require.NotNil(t, batch)
+	assert.Equal(t, []string{"c"}, scenarios(batch))
+	require.NoError(t, spool.Remove(batch.Seq))
+
+	batch = spool.Oldest()
+	require.NotNil(t, batch)
+	assert.Equal(t, []string{"d"}, scenarios(batch))
+	require.NoError(t, spool.Remove(batch.Seq))
+
+	assert.Nil(t, spool.Oldest())
+	assert.Equal(t, 0, spool.Len())
+	assert.InDelta(t, 0, testutil.ToFloat64(metrics.AlertSpoolBytes), 0)
+}
+
+func TestSpoolCorrupted(t *testing.T) {
+	cfg := testConfig(t)
+
+	spool, err := New(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, spool.Add(makeAlerts("a")))
+	require.NoError(t, spool.Add(makeAlerts("b")))
+
+	first := spool.Oldest()
+	require.NotNil(t, first)
+
+	dropped := metrics.AlertSpoolDropped.With(prometheus.Labels{"reason": "corrupted"})
+	before := testutil.ToFloat64(dropped)
+
+	require.NoError(t, os.WriteFile(spool.path(first.Seq), []byte("[{"), 0o600))
+
+	batch := spool.Oldest()
+	require.NotNil(t, batch)
+	assert.Equal(t, []string{"b"}, scenarios(batch))
+	assert.Equal(t, 1, spool.Len())
+	assert.InDelta(t, before+1, testutil.ToFloat64(dropped), 0)
+}
+
+func TestSpoolLimits(t *testing.T) {
+	t.Run("drop oldest", func(t *testing.T) {
+		cfg := testConfig(t)
+		cfg.MaxAlerts = 3
+
+		spool, err := New(cfg)
+		require.NoError(t, err)
+
+		require.NoError(t, spool.Add(makeAlerts("a", "b")))
+		require.NoError(t, spool.Add(makeAlerts("c")))
+		require.NoError(t, spool.Add(makeAlerts("d")))
+
+		assert.Equal(t, 2, spool.Len())
+		assert.Equal(t, []string{"c"}, scenarios(spool.Oldest()))
+
+		// would never fit
+		err = spool.Add(makeAlerts("e", "f", "g", "h"))
+		require.ErrorIs(t, err, ErrFull)
+		assert.Equal(t, 2, spool.Len())
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		cfg := testConfig(t)
+		cfg.MaxAlerts = 3
+		cfg.DropPolicy = csconfig.AlertSpoolDropNewest
+
+		spool, err := New(cfg)
+		require.NoError(t, err)
+
+		dropped := metrics.AlertSpoolDropped.With(prometheus.Labels{"reason": "max_alerts"})
+		before := testutil.ToFloat64(dropped)
+
+		require.NoError(t, spool.Add(makeAlerts("a", "b")))
+		require.NoError(t, spool.Add(makeAlerts("c")))
+
+		err = spool.Add(makeAlerts("d"))
+		require.ErrorIs(t, err, ErrFull)
+		require.ErrorContains(t, err, "alert spool is full (max_alerts): dropping 1 alerts")
+
+		assert.Equal(t, 3, spool.Len())
+		assert.Equal(t, []string{"a", "b"}, scenarios(spool.Oldest()))
+		assert.InDelta(t, before+1, testutil.ToFloat64(dropped), 0)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		cfg := testConfig(t)
+		cfg.MaxSize = 1
+
+		spool, err := New(cfg)
+		require.NoError(t, err)
+
+		// about 600KB each
+		large := makeAlerts("a")
+		large[0].Alert.Message = new(strings.Repeat("x", 600*1024))
+
+		require.NoError(t, spool.Add(large))
+		require.NoError(t, spool.Add(large))
+
+		assert.Equal(t, 1, spool.Len())
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		cfg := testConfig(t)
+
+		spool, err := New(cfg)
+		require.NoError(t, err)
+
+		now := time.Now()
+		spool.now = func() time.Time { return now }
+
+		require.NoError(t, spool.Add(makeAlerts("a")))
+
+		now = now.Add(30 * time.Minute)
+		require.NoError(t, spool.Add(makeAlerts("b")))
+
+		now = now.Add(45 * time.Minute)
+		assert.Equal(t, []string{"b"}, scenarios(spool.Oldest()))
+		assert.Equal(t, 1, spool.Len())
+	})
+}
@@ -133,3 +133,106 @@ func (s *AllowlistsService) CheckIfAllowlistedBulk(ctx context.Context, values [
 
 	return responseBody, resp, nil
 }
+
+func (s *AllowlistsService) Create(ctx context.Context, body *models.CreateAllowlistRequest) (*models.GetAllowlistResponse, *Response, error) {
+	u := s.client.URLPrefix + "/allowlists"
+
+	req, err := s.client.PrepareRequest(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allowlist := &models.GetAllowlistResponse{}
+
+	resp, err := s.client.Do(ctx, req, allowlist)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return allowlist, resp, nil
+}
+
+func (s *AllowlistsService) Update(ctx context.Context, name string, body *models.UpdateAllowlistRequest) (*models.GetAllowlistResponse, *Response, error) {
+	u := s.client.URLPrefix + "/allowlists/" + url.PathEscape(name)
+
+	req, err := s.client.PrepareRequest(ctx, http.MethodPut, u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allowlist := &models.GetAllowlistResponse{}
+
+	resp, err := s.client.Do(ctx, req, allowlist)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return allowlist, resp, nil
+}
+
+func (s *AllowlistsService) Delete(ctx context.Context, name string) (*Response, error) {
+	u := s.client.URLPrefix + "/allowlists/" + url.PathEscape(name)
+
+	req, err := s.client.PrepareRequest(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+func (s *AllowlistsService) AddItems(ctx context.Context, name string, items []*models.AllowlistItem) (*models.AllowlistItemsResponse, *Response, error) {
+	u := s.client.URLPrefix + "/allowlists/" + url.PathEscape(name) + "/items"
+
+	body := &models.AddAllowlistItemsRequest{
+		Items: items,
+	}
+
+	req, err := s.client.PrepareRequest(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responseBody := &models.AllowlistItemsResponse{}
+
+	resp, err := s.client.Do(ctx, req, responseBody)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return responseBody, resp, nil
+}
+
+type AllowlistRemoveItemsOpts struct {
+	Values []string `url:"value"`
+}
+
+func (s *AllowlistsService) RemoveItems(ctx context.Context, name string, opts AllowlistRemoveItemsOpts) (*models.AllowlistItemsResponse, *Response, error) {
+	u := s.client.URLPrefix + "/allowlists/" + url.PathEscape(name) + "/items"
+
+	params, err := qs.Values(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building query: %w", err)
+	}
+
+	u += "?" + params.Encode()
+
+	req, err := s.client.PrepareRequest(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responseBody := &models.AllowlistItemsResponse{}
+
+	resp, err := s.client.Do(ctx, req, responseBody)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return responseBody, resp, nil
+}
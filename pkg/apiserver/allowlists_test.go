@@ -201,3 +201,143 @@ func TestBulkCheckAllowlist_BadRequest(t *testing.T) {
 	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists/check", strings.NewReader("{invalid-json"), passwordAuthType)
 	require.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func TestCreateAllowlist(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	reqBody := `{"name":"test","description":"my list","items":[{"value":"1.2.3.4","description":"a comment"},{"value":"10.0.0.0/8"},{"value":"1.2.3.4"}]}`
+	w := lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists", strings.NewReader(reqBody), passwordAuthType)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	allowlist := models.GetAllowlistResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &allowlist))
+
+	assert.Equal(t, "test", allowlist.Name)
+	assert.Equal(t, "my list", allowlist.Description)
+	require.Len(t, allowlist.Items, 2)
+
+	// already exists
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists", strings.NewReader(reqBody), passwordAuthType)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// invalid value
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists", strings.NewReader(`{"name":"other","items":[{"value":"foo"}]}`), passwordAuthType)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// missing name
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists", strings.NewReader(`{"description":"no name"}`), passwordAuthType)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAllowlistItems(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	_, err := lapi.DBClient.CreateAllowList(ctx, "test", "test", "", false)
+	require.NoError(t, err)
+
+	expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	reqBody := `{"items":[{"value":"1.2.3.4"},{"value":"2.3.4.5","description":"temporary","expiration":"` + expiration + `"}]}`
+
+	w := lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists/test/items", strings.NewReader(reqBody), passwordAuthType)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	resp := models.AllowlistItemsResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(2), resp.Count)
+
+	// values already in the allowlist are ignored
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists/test/items", strings.NewReader(reqBody), passwordAuthType)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	resp = models.AllowlistItemsResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(0), resp.Count)
+
+	allowlisted, reason, err := lapi.DBClient.IsAllowlisted(ctx, "2.3.4.5")
+	require.NoError(t, err)
+	assert.True(t, allowlisted)
+	assert.Equal(t, "2.3.4.5 from test (temporary)", reason)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/allowlists/test/items?value=2.3.4.5&value=3.4.5.6", emptyBody, passwordAuthType)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	resp = models.AllowlistItemsResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(1), resp.Count)
+
+	allowlisted, _, err = lapi.DBClient.IsAllowlisted(ctx, "2.3.4.5")
+	require.NoError(t, err)
+	assert.False(t, allowlisted)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/allowlists/test/items", emptyBody, passwordAuthType)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists/nope/items", strings.NewReader(reqBody), passwordAuthType)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateAndDeleteAllowlist(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	l, err := lapi.DBClient.CreateAllowList(ctx, "test", "test", "", false)
+	require.NoError(t, err)
+
+	_, err = lapi.DBClient.AddToAllowlist(ctx, l, []*models.AllowlistItem{{Value: "1.2.3.4"}})
+	require.NoError(t, err)
+
+	// no items: the content is kept
+	w := lapi.RecordResponse(t, ctx, http.MethodPut, "/v1/allowlists/test", strings.NewReader(`{"description":"new description"}`), passwordAuthType)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	allowlist := models.GetAllowlistResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &allowlist))
+	assert.Equal(t, "new description", allowlist.Description)
+	require.Len(t, allowlist.Items, 1)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodPut, "/v1/allowlists/test", strings.NewReader(`{"description":"new description","items":[{"value":"5.6.7.8"}]}`), passwordAuthType)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	allowlist = models.GetAllowlistResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &allowlist))
+	require.Len(t, allowlist.Items, 1)
+	assert.Equal(t, "5.6.7.8", allowlist.Items[0].Value)
+
+	// no description: the description is kept
+	w = lapi.RecordResponse(t, ctx, http.MethodPut, "/v1/allowlists/test", strings.NewReader(`{"items":[{"value":"6.7.8.9"}]}`), passwordAuthType)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	allowlist = models.GetAllowlistResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &allowlist))
+	assert.Equal(t, "new description", allowlist.Description)
+	require.Len(t, allowlist.Items, 1)
+	assert.Equal(t, "6.7.8.9", allowlist.Items[0].Value)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/allowlists/test", emptyBody, passwordAuthType)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/allowlists/test", emptyBody, passwordAuthType)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/allowlists/test", emptyBody, passwordAuthType)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestConsoleAllowlistReadOnly(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	_, err := lapi.DBClient.CreateAllowList(ctx, "console", "from the console", "some-id", true)
+	require.NoError(t, err)
+
+	w := lapi.RecordResponse(t, ctx, http.MethodPut, "/v1/allowlists/console", strings.NewReader(`{"description":"mine"}`), passwordAuthType)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/allowlists/console/items", strings.NewReader(`{"items":[{"value":"1.2.3.4"}]}`), passwordAuthType)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/allowlists/console", emptyBody, passwordAuthType)
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -187,6 +187,7 @@ func NewServer(ctx context.Context, config *csconfig.LocalApiServerCfg, accessLo
 		ConsoleConfig:                 config.ConsoleConfig,
 		DisableRemoteLapiRegistration: config.DisableRemoteLapiRegistration,
 		AutoRegisterCfg:               config.AutoRegister,
+		RateLimitCfg:                  config.RateLimit,
 	}
 
 	var (
@@ -346,6 +347,9 @@ func (s *APIServer) Run(ctx context.Context, apiReady chan bool) error {
 	s.httpServer.Protocols.SetUnencryptedHTTP2(true)
 	s.httpServer.Protocols.SetHTTP2(true)
 
+	// stop the decision push streams, they would prevent the server from shutting down
+	s.httpServer.RegisterOnShutdown(s.dbClient.DecisionFeed().Close)
+
 	if s.apic != nil {
 		s.initAPIC(ctx)
 	}
@@ -1,12 +1,18 @@
 package v1
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/strfmt"
+	log "github.com/sirupsen/logrus"
 
+	"github.com/crowdsecurity/crowdsec/pkg/csnet"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
 )
 
@@ -93,32 +99,7 @@ func (c *Controller) GetAllowlists(gctx *gin.Context) {
 	resp := models.GetAllowlistsResponse{}
 
 	for _, allowlist := range allowlists {
-		items := make([]*models.AllowlistItem, 0)
-
-		if withContent {
-			for _, item := range allowlist.Edges.AllowlistItems {
-				if !item.ExpiresAt.IsZero() && item.ExpiresAt.Before(time.Now()) {
-					continue
-				}
-
-				items = append(items, &models.AllowlistItem{
-					CreatedAt:   strfmt.DateTime(item.CreatedAt),
-					Description: item.Comment,
-					Expiration:  strfmt.DateTime(item.ExpiresAt),
-					Value:       item.Value,
-				})
-			}
-		}
-
-		resp = append(resp, &models.GetAllowlistResponse{
-			AllowlistID:    allowlist.AllowlistID,
-			Name:           allowlist.Name,
-			Description:    allowlist.Description,
-			CreatedAt:      strfmt.DateTime(allowlist.CreatedAt),
-			UpdatedAt:      strfmt.DateTime(allowlist.UpdatedAt),
-			ConsoleManaged: allowlist.FromConsole,
-			Items:          items,
-		})
+		resp = append(resp, formatAllowlist(allowlist, withContent))
 	}
 
 	gctx.JSON(http.StatusOK, resp)
@@ -136,10 +117,272 @@ func (c *Controller) GetAllowlist(gctx *gin.Context) {
 		return
 	}
 
+	gctx.JSON(http.StatusOK, formatAllowlist(allowlistModel, withContent))
+}
+
+func (c *Controller) CreateAllowlist(gctx *gin.Context) {
+	var req models.CreateAllowlistRequest
+
+	if err := gctx.ShouldBindJSON(&req); err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err := req.Validate(strfmt.Default); err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if *req.Name == "" {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": "name is required"})
+		return
+	}
+
+	items, err := newAllowlistItems(req.Items, nil)
+	if err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := gctx.Request.Context()
+
+	allowlist, err := c.DBClient.CreateAllowList(ctx, *req.Name, req.Description, "", false)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	if len(items) > 0 {
+		if _, err = c.DBClient.AddToAllowlist(ctx, allowlist, items); err != nil {
+			c.HandleDBErrors(gctx, err)
+			return
+		}
+
+		c.applyAllowlists(ctx)
+	}
+
+	allowlist, err = c.DBClient.GetAllowList(ctx, allowlist.Name, true)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	gctx.JSON(http.StatusCreated, formatAllowlist(allowlist, true))
+}
+
+func (c *Controller) UpdateAllowlist(gctx *gin.Context) {
+	var req models.UpdateAllowlistRequest
+
+	if err := gctx.ShouldBindJSON(&req); err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err := req.Validate(strfmt.Default); err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := gctx.Request.Context()
+
+	allowlist, ok := c.getWritableAllowlist(gctx, false)
+	if !ok {
+		return
+	}
+
+	items, err := newAllowlistItems(req.Items, nil)
+	if err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	// a missing description or list leaves it untouched, an empty list clears the content
+	if req.Description != nil {
+		if err = c.DBClient.UpdateAllowlistDescription(ctx, allowlist, *req.Description); err != nil {
+			c.HandleDBErrors(gctx, err)
+			return
+		}
+	}
+
+	if req.Items != nil {
+		if _, err = c.DBClient.ReplaceAllowlist(ctx, allowlist, items, false); err != nil {
+			c.HandleDBErrors(gctx, err)
+			return
+		}
+
+		c.applyAllowlists(ctx)
+	}
+
+	allowlist, err = c.DBClient.GetAllowList(ctx, allowlist.Name, true)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	gctx.JSON(http.StatusOK, formatAllowlist(allowlist, true))
+}
+
+func (c *Controller) DeleteAllowlist(gctx *gin.Context) {
+	allowlist, ok := c.getWritableAllowlist(gctx, false)
+	if !ok {
+		return
+	}
+
+	if err := c.DBClient.DeleteAllowList(gctx.Request.Context(), allowlist.Name, false); err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	gctx.Status(http.StatusNoContent)
+}
+
+func (c *Controller) AddAllowlistItems(gctx *gin.Context) {
+	var req models.AddAllowlistItemsRequest
+
+	if err := gctx.ShouldBindJSON(&req); err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err := req.Validate(strfmt.Default); err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if len(req.Items) == 0 {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": "items list cannot be empty"})
+		return
+	}
+
+	ctx := gctx.Request.Context()
+
+	allowlist, ok := c.getWritableAllowlist(gctx, true)
+	if !ok {
+		return
+	}
+
+	items, err := newAllowlistItems(req.Items, allowlist.Edges.AllowlistItems)
+	if err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	resp := models.AllowlistItemsResponse{}
+
+	if len(items) == 0 {
+		gctx.JSON(http.StatusOK, resp)
+		return
+	}
+
+	added, err := c.DBClient.AddToAllowlist(ctx, allowlist, items)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	resp.Count = int64(added)
+	resp.DecisionsDeleted = int64(c.applyAllowlists(ctx))
+
+	gctx.JSON(http.StatusOK, resp)
+}
+
+func (c *Controller) RemoveAllowlistItems(gctx *gin.Context) {
+	values := gctx.QueryArray("value")
+
+	if len(values) == 0 {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": "at least one value is required"})
+		return
+	}
+
+	allowlist, ok := c.getWritableAllowlist(gctx, false)
+	if !ok {
+		return
+	}
+
+	deleted, err := c.DBClient.RemoveFromAllowlist(gctx.Request.Context(), allowlist, values...)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	gctx.JSON(http.StatusOK, models.AllowlistItemsResponse{Count: int64(deleted)})
+}
+
+// getWritableAllowlist fetches the allowlist named in the request path, and writes the
+// error response if it doesn't exist or is managed by the console.
+func (c *Controller) getWritableAllowlist(gctx *gin.Context, withContent bool) (*ent.AllowList, bool) {
+	name := gctx.Param("allowlist_name")
+
+	allowlist, err := c.DBClient.GetAllowList(gctx.Request.Context(), name, withContent)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return nil, false
+	}
+
+	if allowlist.FromConsole {
+		gctx.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("allowlist %s is managed by console, it cannot be modified with the API", name)})
+		return nil, false
+	}
+
+	return allowlist, true
+}
+
+// applyAllowlists deletes the active decisions matching the allowlists, after their content changed.
+func (c *Controller) applyAllowlists(ctx context.Context) int {
+	deleted, err := c.DBClient.ApplyAllowlistsToExistingDecisions(ctx)
+	if err != nil {
+		log.Errorf("unable to apply allowlists to existing decisions: %s", err)
+		return 0
+	}
+
+	if deleted > 0 {
+		log.Infof("%d decisions deleted by allowlists", deleted)
+	}
+
+	return deleted
+}
+
+// newAllowlistItems validates the values of the items, and drops the ones that are
+// already present in the request or in the allowlist.
+func newAllowlistItems(items []*models.AllowlistItem, existing []*ent.AllowListItem) ([]*models.AllowlistItem, error) {
+	seen := make(map[string]struct{}, len(existing))
+
+	for _, item := range existing {
+		seen[item.Value] = struct{}{}
+	}
+
+	ret := make([]*models.AllowlistItem, 0, len(items))
+
+	for _, item := range items {
+		if item == nil || item.Value == "" {
+			return nil, errors.New("item value is required")
+		}
+
+		if _, err := csnet.NewRange(item.Value); err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[item.Value]; ok {
+			continue
+		}
+
+		seen[item.Value] = struct{}{}
+
+		ret = append(ret, &models.AllowlistItem{
+			Value:       item.Value,
+			Description: item.Description,
+			Expiration:  item.Expiration,
+		})
+	}
+
+	return ret, nil
+}
+
+func formatAllowlist(allowlist *ent.AllowList, withContent bool) *models.GetAllowlistResponse {
 	items := make([]*models.AllowlistItem, 0)
 
 	if withContent {
-		for _, item := range allowlistModel.Edges.AllowlistItems {
+		for _, item := range allowlist.Edges.AllowlistItems {
 			if !item.ExpiresAt.IsZero() && item.ExpiresAt.Before(time.Now()) {
 				continue
 			}
@@ -153,15 +396,13 @@ func (c *Controller) GetAllowlist(gctx *gin.Context) {
 		}
 	}
 
-	resp := models.GetAllowlistResponse{
-		AllowlistID:    allowlistModel.AllowlistID,
-		Name:           allowlistModel.Name,
-		Description:    allowlistModel.Description,
-		CreatedAt:      strfmt.DateTime(allowlistModel.CreatedAt),
-		UpdatedAt:      strfmt.DateTime(allowlistModel.UpdatedAt),
-		ConsoleManaged: allowlistModel.FromConsole,
+	return &models.GetAllowlistResponse{
+		AllowlistID:    allowlist.AllowlistID,
+		Name:           allowlist.Name,
+		Description:    allowlist.Description,
+		CreatedAt:      strfmt.DateTime(allowlist.CreatedAt),
+		UpdatedAt:      strfmt.DateTime(allowlist.UpdatedAt),
+		ConsoleManaged: allowlist.FromConsole,
 		Items:          items,
 	}
-
-	gctx.JSON(http.StatusOK, resp)
 }
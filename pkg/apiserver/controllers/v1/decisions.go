@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -155,7 +156,7 @@ func (c *Controller) DeleteDecisions(gctx *gin.Context) {
 	gctx.JSON(http.StatusOK, deleteDecisionResp)
 }
 
-func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string][]string, dbFunc func(context.Context, time.Time, map[string][]string) ([]*ent.Decision, error)) error {
+func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string][]string, pushed decisionIDs, dbFunc func(context.Context, time.Time, map[string][]string) ([]*ent.Decision, error)) error {
 	limit := 30000 // FIXME : make it configurable
 	needComma := false
 	lastId := 0
@@ -183,6 +184,10 @@ func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string]
 		}
 
 		for _, d := range data {
+			if pushed.skip(d.ID, now) {
+				continue
+			}
+
 			if needComma {
 				gctx.Writer.WriteString(",")
 			} else {
@@ -223,7 +228,7 @@ func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string]
 	return nil
 }
 
-func writeDeltaDecisions(gctx *gin.Context, now time.Time, filters map[string][]string, lastPull *time.Time, dbFunc func(context.Context, time.Time, *time.Time, map[string][]string) ([]*ent.Decision, error)) error {
+func writeDeltaDecisions(gctx *gin.Context, now time.Time, filters map[string][]string, lastPull *time.Time, pushed decisionIDs, dbFunc func(context.Context, time.Time, *time.Time, map[string][]string) ([]*ent.Decision, error)) error {
 	limit := 30000 // FIXME : make it configurable
 	needComma := false
 	lastId := 0
@@ -251,6 +256,10 @@ func writeDeltaDecisions(gctx *gin.Context, now time.Time, filters map[string][]
 		}
 
 		for _, d := range data {
+			if pushed.skip(d.ID, now) {
+				continue
+			}
+
 			if needComma {
 				gctx.Writer.WriteString(",")
 			} else {
@@ -292,17 +301,35 @@ func writeDeltaDecisions(gctx *gin.Context, now time.Time, filters map[string][]
 }
 
 func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer, now time.Time, filters map[string][]string) error {
-	var err error
-
 	gctx.Writer.Header().Set("Content-Type", "application/json")
 	gctx.Writer.Header().Set("Transfer-Encoding", "chunked")
 	gctx.Writer.WriteHeader(http.StatusOK)
+
+	return c.writeDecisionStream(gctx, isStartup(gctx), bouncerInfo.LastPull, now, filters, nil)
+}
+
+// if the blocker just started, it wants all the decisions
+func isStartup(gctx *gin.Context) bool {
+	val, ok := gctx.Request.URL.Query()["startup"]
+	return ok && val[0] == "true"
+}
+
+// writeDecisionStream writes the new and deleted decisions as a single line of JSON.
+// On startup, these are all the active and expired decisions, otherwise the changes since lastPull.
+// The decisions already pushed are skipped, if pushed is not nil.
+func (c *Controller) writeDecisionStream(gctx *gin.Context, startup bool, lastPull *time.Time, now time.Time, filters map[string][]string, pushed *pushedDecisions) error {
+	var err error
+
+	var pushedNew, pushedDeleted decisionIDs
+	if pushed != nil {
+		pushedNew, pushedDeleted = pushed.new, pushed.deleted
+	}
+
 	gctx.Writer.WriteString(`{"new": [`) // No need to check for errors, the doc says it always returns nil
 
-	// if the blocker just started, return all decisions
-	if val, ok := gctx.Request.URL.Query()["startup"]; ok && val[0] == "true" {
+	if startup {
 		// Active decisions
-		err := writeStartupDecisions(gctx, now, filters, c.DBClient.QueryAllDecisionsWithFilters)
+		err := writeStartupDecisions(gctx, now, filters, pushedNew, c.DBClient.QueryAllDecisionsWithFilters)
 		if err != nil {
 			log.Errorf("failed sending new decisions for startup: %v", err)
 			gctx.Writer.WriteString(`], "deleted": []}`)
@@ -313,7 +340,7 @@ func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer
 
 		gctx.Writer.WriteString(`], "deleted": [`)
 		// Expired decisions
-		err = writeStartupDecisions(gctx, now, filters, c.DBClient.QueryExpiredDecisionsWithFilters)
+		err = writeStartupDecisions(gctx, now, filters, pushedDeleted, c.DBClient.QueryExpiredDecisionsWithFilters)
 		if err != nil {
 			log.Errorf("failed sending expired decisions for startup: %v", err)
 			gctx.Writer.WriteString(`]}`)
@@ -325,7 +352,7 @@ func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer
 		gctx.Writer.WriteString(`]}`)
 		gctx.Writer.Flush()
 	} else {
-		err = writeDeltaDecisions(gctx, now, filters, bouncerInfo.LastPull, c.DBClient.QueryNewDecisionsSinceWithFilters)
+		err = writeDeltaDecisions(gctx, now, filters, lastPull, pushedNew, c.DBClient.QueryNewDecisionsSinceWithFilters)
 		if err != nil {
 			log.Errorf("failed sending new decisions for delta: %v", err)
 			gctx.Writer.WriteString(`], "deleted": []}`)
@@ -338,12 +365,12 @@ func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer
 
 		// Use a 2-second overlap to avoid missing decisions that expired around the last pull time
 		var expiredSince *time.Time
-		if bouncerInfo.LastPull != nil {
-			since := bouncerInfo.LastPull.Add(-2 * time.Second)
+		if lastPull != nil {
+			since := lastPull.Add(-2 * time.Second)
 			expiredSince = &since
 		}
 
-		err = writeDeltaDecisions(gctx, now, filters, expiredSince, c.DBClient.QueryExpiredDecisionsSinceWithFilters)
+		err = writeDeltaDecisions(gctx, now, filters, expiredSince, pushedDeleted, c.DBClient.QueryExpiredDecisionsSinceWithFilters)
 		if err != nil {
 			log.Errorf("failed sending expired decisions for delta: %v", err)
 			gctx.Writer.WriteString("]}")
@@ -382,6 +409,11 @@ func (c *Controller) StreamDecision(gctx *gin.Context) {
 		filters["scopes"] = []string{"ip,range"}
 	}
 
+	if strings.Contains(gctx.GetHeader("Accept"), "text/event-stream") {
+		c.pushDecisions(gctx, bouncerInfo, filters)
+		return
+	}
+
 	err = c.streamDecisions(gctx, bouncerInfo, streamStartTime, filters)
 
 	if err == nil {
@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+)
+
+const (
+	// the changes that are not signaled by the feed (expired decisions, other processes) are
+	// picked up at this interval, which also acts as a keepalive
+	decisionPushRefresh = 30 * time.Second
+	// coalesce bursts of changes (ie. a large alert or a blocklist being inserted)
+	decisionPushMinInterval = time.Second
+	// new decisions are created before their transaction is committed, query
+	// a bit before the previous push to not miss them. The decisions that have
+	// already been pushed are skipped.
+	decisionPushOverlap = 2 * time.Second
+	// don't update the last pull of the bouncer on every push
+	decisionPushLastPullUpdate = time.Minute
+)
+
+// decisionIDs are the decisions pushed to a bouncer, with the time of the push.
+type decisionIDs map[int]time.Time
+
+// skip returns true if the decision has already been pushed, and records it otherwise.
+func (ids decisionIDs) skip(id int, now time.Time) bool {
+	if ids == nil {
+		return false
+	}
+
+	if _, ok := ids[id]; ok {
+		return true
+	}
+
+	ids[id] = now
+
+	return false
+}
+
+// forget removes the decisions pushed before a time: they can't be returned by the next queries.
+func (ids decisionIDs) forget(before time.Time) {
+	for id, pushed := range ids {
+		if pushed.Before(before) {
+			delete(ids, id)
+		}
+	}
+}
+
+// pushedDecisions are the decisions pushed in the overlap window, a decision can be new then deleted.
+type pushedDecisions struct {
+	new     decisionIDs
+	deleted decisionIDs
+}
+
+// pushDecisions is the server-sent events variant of the decision stream: the connection
+// is kept open and an event with the new and deleted decisions is sent as soon as they are
+// committed. The first event follows the startup semantics if requested, the next ones are deltas.
+func (c *Controller) pushDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer, filters map[string][]string) {
+	ctx := gctx.Request.Context()
+	feed := c.DBClient.DecisionFeed()
+
+	gctx.Writer.Header().Set("Content-Type", "text/event-stream")
+	gctx.Writer.Header().Set("Cache-Control", "no-cache")
+	gctx.Writer.Header().Set("X-Accel-Buffering", "no")
+	gctx.Writer.WriteHeader(http.StatusOK)
+	gctx.Writer.Flush()
+
+	startup := isStartup(gctx)
+	since := bouncerInfo.LastPull
+
+	var lastPush, lastSaved time.Time
+
+	pushed := &pushedDecisions{new: decisionIDs{}, deleted: decisionIDs{}}
+
+	defer func() {
+		if lastPush.After(lastSaved) {
+			// the request context is canceled by now
+			if err := c.DBClient.UpdateBouncerLastPull(context.Background(), lastPush, bouncerInfo.ID); err != nil {
+				log.Errorf("unable to update bouncer '%s' pull: %v", bouncerInfo.Name, err)
+			}
+		}
+	}()
+
+	refresh := time.NewTicker(decisionPushRefresh)
+	defer refresh.Stop()
+
+	for {
+		// get the channel before querying, so a change committed in the meantime wakes us up
+		changed, _ := feed.Changed()
+		now := time.Now().UTC()
+
+		gctx.Writer.WriteString("event: decisions\ndata: ")
+
+		if err := c.writeDecisionStream(gctx, startup, since, now, filters, pushed); err != nil {
+			// the bouncer will reconnect and get a delta from its last pull
+			log.Errorf("failed pushing decisions to bouncer '%s': %v", bouncerInfo.Name, err)
+			return
+		}
+
+		gctx.Writer.WriteString("\n\n")
+		gctx.Writer.Flush()
+
+		startup = false
+		lastPush = now
+		overlap := now.Add(-decisionPushOverlap)
+		since = &overlap
+
+		// the expired decisions are queried with an extra overlap
+		pushed.new.forget(overlap)
+		pushed.deleted.forget(overlap.Add(-decisionPushOverlap))
+
+		if now.Sub(lastSaved) >= decisionPushLastPullUpdate {
+			if err := c.DBClient.UpdateBouncerLastPull(ctx, now, bouncerInfo.ID); err != nil {
+				log.Errorf("unable to update bouncer '%s' pull: %v", bouncerInfo.Name, err)
+			} else {
+				lastSaved = now
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-refresh.C:
+		case <-feed.Closed():
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		if wait := decisionPushMinInterval - time.Since(now); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-feed.Closed():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
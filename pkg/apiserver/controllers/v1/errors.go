@@ -12,9 +12,12 @@ import (
 
 func (*Controller) HandleDBErrors(gctx *gin.Context, err error) {
 	switch {
-	case errors.Is(err, database.ItemNotFound):
+	case errors.Is(err, database.ItemNotFound), errors.Is(err, database.AllowlistNotFound):
 		gctx.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
 		return
+	case errors.Is(err, database.AllowlistExists):
+		gctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
 	case errors.Is(err, database.UserExists):
 		gctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
 		return
@@ -1,18 +1,24 @@
 package v1
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/metric"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
 )
 
@@ -96,6 +102,7 @@ func (c *Controller) UsageMetrics(gctx *gin.Context) {
 		baseMetrics models.BaseMetrics
 		hubItems    models.HubItems
 		datasources map[string]int64
+		rcMetrics   []*models.DetailedMetrics
 	)
 
 	switch len(input.LogProcessors) {
@@ -157,6 +164,7 @@ func (c *Controller) UsageMetrics(gctx *gin.Context) {
 			"metrics": item0.Metrics,
 		}
 		baseMetrics = item0.BaseMetrics
+		rcMetrics = item0.Metrics
 	default:
 		gctx.JSON(http.StatusBadRequest, gin.H{"message": "Payload has more than one remediation component"})
 		return
@@ -196,8 +204,73 @@ func (c *Controller) UsageMetrics(gctx *gin.Context) {
 		return
 	}
 
+	if bouncer != nil {
+		exportBouncerUsage(bouncer.Name, rcMetrics)
+	}
+
 	// if CreateMetrics() returned nil, the metric was already there, we're good
 	// and don't split hair about 201 vs 200/204
 
 	gctx.Status(http.StatusCreated)
 }
+
+// isGaugeMetric returns true if the bouncer reports the current value of the metric,
+// instead of the delta since the previous report (same convention as cscli metrics)
+func isGaugeMetric(name string) bool {
+	return name == "active_decisions" || strings.HasSuffix(name, "_gauge")
+}
+
+// exportBouncerUsage updates the prometheus metrics with the usage reported by a bouncer
+func exportBouncerUsage(bouncerName string, detailed []*models.DetailedMetrics) {
+	// a payload can contain several time windows, the last value of a gauge wins
+	windows := slices.Clone(detailed)
+	windows = slices.DeleteFunc(windows, func(d *models.DetailedMetrics) bool {
+		return d == nil || d.Meta == nil || d.Meta.UtcNowTimestamp == nil
+	})
+
+	slices.SortStableFunc(windows, func(a, b *models.DetailedMetrics) int {
+		return cmp.Compare(*a.Meta.UtcNowTimestamp, *b.Meta.UtcNowTimestamp)
+	})
+
+	gauges := make(map[[5]string]float64)
+
+	for _, window := range windows {
+		// the labels we don't export are aggregated
+		windowGauges := make(map[[5]string]float64)
+
+		for _, item := range window.Items {
+			if item == nil || item.Name == nil || item.Unit == nil || item.Value == nil {
+				continue
+			}
+
+			key := [5]string{*item.Name, *item.Unit, item.Labels["origin"], item.Labels["remediation"], item.Labels["ip_type"]}
+
+			if isGaugeMetric(*item.Name) {
+				key[0] = strings.TrimSuffix(key[0], "_gauge")
+				windowGauges[key] += *item.Value
+
+				continue
+			}
+
+			// a counter can't go down
+			if *item.Value < 0 {
+				continue
+			}
+
+			metrics.LapiBouncerUsage.WithLabelValues(bouncerName, key[0], key[1], key[2], key[3], key[4]).Add(*item.Value)
+		}
+
+		maps.Copy(gauges, windowGauges)
+	}
+
+	if len(gauges) == 0 {
+		return
+	}
+
+	// the bouncer reports all its gauges: the series that are not there anymore must go
+	metrics.LapiBouncerUsageGauge.DeletePartialMatch(prometheus.Labels{"bouncer": bouncerName})
+
+	for key, value := range gauges {
+		metrics.LapiBouncerUsageGauge.WithLabelValues(bouncerName, key[0], key[1], key[2], key[3], key[4]).Set(value)
+	}
+}
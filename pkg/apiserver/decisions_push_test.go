@@ -0,0 +1,101 @@
+package apiserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+// readEvent returns the data of the next server-sent event
+func readEvent(t *testing.T, scanner *bufio.Scanner) map[string][]*models.Decision {
+	t.Helper()
+
+	var data string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+
+		if after, ok := strings.CutPrefix(line, "data: "); ok {
+			data = after
+		}
+	}
+
+	require.NoError(t, scanner.Err())
+	require.NotEmpty(t, data)
+
+	ret := map[string][]*models.Decision{}
+	require.NoError(t, json.Unmarshal([]byte(data), &ret))
+
+	return ret
+}
+
+func TestPushDecisions(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	srv := httptest.NewServer(lapi.router)
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/decisions/stream?startup=true", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("X-Api-Key", lapi.bouncerKey)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	decisions := readEvent(t, scanner)
+	assert.Empty(t, decisions["new"])
+	assert.Empty(t, decisions["deleted"])
+
+	// 3 decisions for 127.0.0.1, the longest has id=3
+	start := time.Now()
+	w := lapi.InsertAlertFromFile(t, ctx, "./tests/alert_sample.json")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	decisions = readEvent(t, scanner)
+	assert.Less(t, time.Since(start), 10*time.Second, "the decisions have been pushed, not refreshed")
+	require.Len(t, decisions["new"], 1)
+	assert.Equal(t, int64(3), decisions["new"][0].ID)
+	assert.Equal(t, "127.0.0.1", *decisions["new"][0].Value)
+
+	// decisions for another IP, while the first ones are still in the overlap window: they are not sent twice
+	body, err := io.ReadAll(GetAlertReaderFromFile(t, "./tests/alert_sample.json"))
+	require.NoError(t, err)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/alerts", strings.NewReader(strings.ReplaceAll(string(body), "127.0.0.1", "127.0.0.2")), PASSWORD)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	decisions = readEvent(t, scanner)
+	require.Len(t, decisions["new"], 1)
+	assert.Equal(t, "127.0.0.2", *decisions["new"][0].Value)
+	assert.Empty(t, decisions["deleted"])
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/decisions?ip=127.0.0.1", emptyBody, PASSWORD)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	decisions = readEvent(t, scanner)
+	assert.Empty(t, decisions["new"])
+	require.NotEmpty(t, decisions["deleted"])
+	assert.Equal(t, "127.0.0.1", *decisions["deleted"][0].Value)
+}
@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"cmp"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+// limiters that have not been used for this long are forgotten
+const rateLimiterIdleTimeout = 10 * time.Minute
+
+type rateLimiterKey struct {
+	route  string
+	method string
+	client string
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter throttles the requests with a token bucket per client and route.
+// Clients are identified by machine ID or bouncer name once authenticated, by source IP otherwise.
+type RateLimiter struct {
+	defaultLimit *csconfig.RateLimit
+	routes       []csconfig.RouteRateLimit
+	mu           sync.Mutex
+	limiters     map[rateLimiterKey]*clientLimiter
+	lastCleanup  time.Time
+	now          func() time.Time
+}
+
+// NewRateLimiter returns nil if rate limiting is disabled, which is a valid no-op middleware.
+func NewRateLimiter(cfg *csconfig.LocalAPIRateLimitCfg) *RateLimiter {
+	if cfg == nil || cfg.Enable == nil || !*cfg.Enable {
+		return nil
+	}
+
+	return &RateLimiter{
+		defaultLimit: cfg.Default,
+		routes:       cfg.Routes,
+		limiters:     make(map[rateLimiterKey]*clientLimiter),
+		lastCleanup:  time.Now(),
+		now:          time.Now,
+	}
+}
+
+// limitFor returns the limit that applies to a route, or nil if it's not limited.
+// A route with a method takes precedence over one that applies to all methods.
+func (r *RateLimiter) limitFor(route string, method string) *csconfig.RateLimit {
+	var anyMethod *csconfig.RateLimit
+
+	for i := range r.routes {
+		rl := &r.routes[i]
+
+		if rl.Route != route {
+			continue
+		}
+
+		if rl.Method == method {
+			return &rl.RateLimit
+		}
+
+		if rl.Method == "" && anyMethod == nil {
+			anyMethod = &rl.RateLimit
+		}
+	}
+
+	return cmp.Or(anyMethod, r.defaultLimit)
+}
+
+func clientIdentity(c *gin.Context) (string, string) {
+	if claims := jwt.ExtractClaims(c); claims != nil {
+		if machineID, ok := claims[MachineIDKey].(string); ok && machineID != "" {
+			return "machine", machineID
+		}
+	}
+
+	if value, ok := c.Get(BouncerContextKey); ok {
+		if bouncer, ok := value.(*ent.Bouncer); ok {
+			return "bouncer", bouncer.Name
+		}
+	}
+
+	return "ip", c.ClientIP()
+}
+
+func (r *RateLimiter) getLimiter(key rateLimiterKey, limit *csconfig.RateLimit, now time.Time) *rate.Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.lastCleanup) > rateLimiterIdleTimeout {
+		for k, l := range r.limiters {
+			if now.Sub(l.lastSeen) > rateLimiterIdleTimeout {
+				delete(r.limiters, k)
+			}
+		}
+
+		r.lastCleanup = now
+	}
+
+	l, ok := r.limiters[key]
+	if !ok {
+		l = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		r.limiters[key] = l
+	}
+
+	l.lastSeen = now
+
+	return l.limiter
+}
+
+// Middleware must run after the authentication, to identify the clients.
+func (r *RateLimiter) Middleware(c *gin.Context) {
+	if r == nil {
+		return
+	}
+
+	route := cmp.Or(c.FullPath(), "invalid-endpoint")
+	method := c.Request.Method
+
+	limit := r.limitFor(route, method)
+	if limit == nil {
+		return
+	}
+
+	clientType, client := clientIdentity(c)
+	now := r.now()
+
+	limiter := r.getLimiter(rateLimiterKey{route: route, method: method, client: clientType + ":" + client}, limit, now)
+
+	if limiter.AllowN(now, 1) {
+		return
+	}
+
+	// how long until a token is available, without consuming it
+	reservation := limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	reservation.CancelAt(now)
+
+	metrics.LapiThrottledRequests.With(prometheus.Labels{
+		"route":       route,
+		"method":      method,
+		"client_type": clientType,
+	}).Inc()
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(delay.Seconds())))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "too many requests"})
+}
@@ -0,0 +1,101 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+)
+
+func NewAPITestRateLimit(t *testing.T, ctx context.Context, rateLimit *csconfig.LocalAPIRateLimitCfg) (*gin.Engine, csconfig.Config) {
+	config := LoadTestConfig(t)
+	config.API.Server.RateLimit = rateLimit
+	require.NoError(t, config.API.Server.LoadRateLimit())
+
+	logger, _ := logtest.NewNullLogger()
+	apiServer, err := NewServer(ctx, config.API.Server, logger.WithFields(nil))
+	require.NoError(t, err)
+
+	err = apiServer.InitController()
+	require.NoError(t, err)
+
+	router, err := apiServer.Router()
+	require.NoError(t, err)
+
+	return router, config
+}
+
+func TestRateLimitUnauthenticated(t *testing.T) {
+	ctx := t.Context()
+	router, _ := NewAPITestRateLimit(t, ctx, &csconfig.LocalAPIRateLimitCfg{
+		Default: &csconfig.RateLimit{Rate: 0.01, Burst: 2},
+	})
+
+	login := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/v1/watchers/login", strings.NewReader(`{"machine_id":"test","password":"wrong"}`))
+		require.NoError(t, err)
+		req.Header.Set("User-Agent", UserAgent)
+		req.RemoteAddr = "1.2.3.4:4242"
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for range 2 {
+		w := login()
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+
+	w := login()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.JSONEq(t, `{"message":"too many requests"}`, w.Body.String())
+	assert.Equal(t, "100", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitBouncer(t *testing.T) {
+	ctx := t.Context()
+	router, config := NewAPITestRateLimit(t, ctx, &csconfig.LocalAPIRateLimitCfg{
+		Routes: []csconfig.RouteRateLimit{
+			{Route: "/v1/decisions", Method: http.MethodGet, RateLimit: csconfig.RateLimit{Rate: 0.5, Burst: 1}},
+		},
+	})
+
+	apiKey, _ := CreateTestBouncer(t, ctx, config.API.Server.DbConfig)
+
+	query := func(method string, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequestWithContext(ctx, method, url, http.NoBody)
+		require.NoError(t, err)
+		req.Header.Add("User-Agent", UserAgent)
+		req.Header.Add("X-Api-Key", apiKey)
+		req.RemoteAddr = "127.0.0.1:4242"
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := query(http.MethodGet, "/v1/decisions")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = query(http.MethodGet, "/v1/decisions")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	// other routes and methods are not limited
+	w = query(http.MethodHead, "/v1/decisions")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	for range 3 {
+		w = query(http.MethodGet, "/v1/decisions/stream")
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/metric"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
 )
 
 func TestLPMetrics(t *testing.T) {
@@ -385,3 +388,76 @@ func TestRCMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestRCMetricsPrometheus(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	body := `
+{
+	"remediation_components": [
+	{
+		"version": "1.42",
+		"os": {"name":"foo", "version": "42"},
+		"utc_startup_timestamp": 42,
+		"metrics": [
+			{"meta":{"utc_now_timestamp":43, "window_size_seconds": 1}, "items": [
+				{"name": "dropped", "value": 10, "unit": "packet", "labels": {"origin": "CAPI", "ip_type": "ipv4"}},
+				{"name": "active_decisions", "value": 7, "unit": "ip", "labels": {"origin": "CAPI", "ip_type": "ipv4"}}
+			]},
+			{"meta":{"utc_now_timestamp":42, "window_size_seconds": 1}, "items": [
+				{"name": "dropped", "value": 5, "unit": "packet", "labels": {"origin": "CAPI", "ip_type": "ipv4"}},
+				{"name": "active_decisions", "value": 3, "unit": "ip", "labels": {"origin": "CAPI", "ip_type": "ipv4"}},
+				{"name": "processed_gauge", "value": 2, "unit": "ip", "labels": {"ip_type": "ipv4"}}
+			]}
+		]
+	}
+	]
+}`
+
+	dropped := metrics.LapiBouncerUsage.With(prometheus.Labels{
+		"bouncer": "test", "name": "dropped", "unit": "packet", "origin": "CAPI", "remediation": "", "ip_type": "ipv4",
+	})
+	// the gauge series are replaced by each report, they must be looked up after it
+	activeDecisions := func() prometheus.Gauge {
+		return metrics.LapiBouncerUsageGauge.With(prometheus.Labels{
+			"bouncer": "test", "name": "active_decisions", "unit": "ip", "origin": "CAPI", "remediation": "", "ip_type": "ipv4",
+		})
+	}
+	processed := prometheus.Labels{
+		"bouncer": "test", "name": "processed", "unit": "ip", "origin": "", "remediation": "", "ip_type": "ipv4",
+	}
+
+	before := testutil.ToFloat64(dropped)
+
+	w := lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/usage-metrics", strings.NewReader(body), APIKEY)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// counters are deltas, gauges keep the value of the most recent window
+	assert.InDelta(t, before+15, testutil.ToFloat64(dropped), 0)
+	assert.InDelta(t, 7, testutil.ToFloat64(activeDecisions()), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(metrics.LapiBouncerUsageGauge.With(processed)), 0)
+
+	// a gauge that is not reported anymore is removed
+	body = `
+{
+	"remediation_components": [
+	{
+		"version": "1.42",
+		"os": {"name":"foo", "version": "42"},
+		"utc_startup_timestamp": 42,
+		"metrics": [
+			{"meta":{"utc_now_timestamp":44, "window_size_seconds": 1}, "items": [
+				{"name": "active_decisions", "value": 4, "unit": "ip", "labels": {"origin": "CAPI", "ip_type": "ipv4"}}
+			]}
+		]
+	}
+	]
+}`
+
+	w = lapi.RecordResponse(t, ctx, http.MethodPost, "/v1/usage-metrics", strings.NewReader(body), APIKEY)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	assert.False(t, metrics.LapiBouncerUsageGauge.Delete(processed))
+	assert.InDelta(t, 4, testutil.ToFloat64(activeDecisions()), 0)
+}
@@ -48,6 +48,7 @@ const (
 	hookOnMatch
 	hookOnChallenge
 	hookOnChallengeSubmit
+	hookOnResponse
 )
 
 func (s hookStage) String() string {
@@ -64,18 +65,21 @@ func (s hookStage) String() string {
 		return "on_challenge"
 	case hookOnChallengeSubmit:
 		return "on_challenge_submit"
+	case hookOnResponse:
+		return "on_response"
 	default:
 		return "unknown"
 	}
 }
 
-// PhaseHooks bundles the three phase-scoped hook lists (pre_eval, post_eval,
-// on_match) that run during request evaluation. OnLoad is excluded because it
-// runs once at startup and is not phase-scoped.
+// PhaseHooks bundles the phase-scoped hook lists (pre_eval, post_eval,
+// on_match, on_response) that run during request evaluation. OnLoad is excluded
+// because it runs once at startup and is not phase-scoped.
 type PhaseHooks struct {
-	PreEval  []Hook
-	PostEval []Hook
-	OnMatch  []Hook
+	PreEval    []Hook
+	PostEval   []Hook
+	OnMatch    []Hook
+	OnResponse []Hook
 }
 
 // get returns the hook list for a given stage, or nil for stages that are not
@@ -88,6 +92,8 @@ func (p *PhaseHooks) get(stage hookStage) []Hook {
 		return p.PostEval
 	case hookOnMatch:
 		return p.OnMatch
+	case hookOnResponse:
+		return p.OnResponse
 	default:
 		return nil
 	}
@@ -150,6 +156,8 @@ func (h *Hook) Build(ctx context.Context, stage hookStage, patcher *appsecExprPa
 		env = GetOnChallengeEnv(ctx, &AppsecRuntimeConfig{}, placeholderState, &ParsedRequest{})
 	case hookOnChallengeSubmit:
 		env = GetOnChallengeSubmitEnv(&AppsecRuntimeConfig{}, placeholderState, &ParsedRequest{})
+	case hookOnResponse:
+		env = GetOnResponseEnv(&AppsecRuntimeConfig{}, placeholderState, &ParsedRequest{})
 	}
 
 	opts := exprhelpers.GetExprOptions(env)
@@ -324,6 +332,19 @@ func (s *AppsecRequestState) ApplyPendingResponse() {
 type AppsecSubEngineOpts struct {
 	DisableBodyInspection    bool `yaml:"disable_body_inspection"`
 	RequestBodyInMemoryLimit *int `yaml:"request_body_in_memory_limit"`
+	// ResponseBodyMimeTypes are the content types of the responses whose body is inspected
+	// in the response phase. Defaults to DefaultResponseBodyMimeTypes.
+	ResponseBodyMimeTypes []string `yaml:"response_body_mime_types"`
+}
+
+// DefaultResponseBodyMimeTypes are the content types of the response bodies inspected by default.
+var DefaultResponseBodyMimeTypes = []string{
+	"text/plain",
+	"text/html",
+	"text/xml",
+	"application/json",
+	"application/xml",
+	"application/problem+json",
 }
 
 // BodySettings controls how oversized request bodies are handled.
@@ -346,6 +367,7 @@ type AppsecPhaseConfig struct {
 	PostEval          []Hook              `yaml:"post_eval"`
 	OnChallenge       []Hook              `yaml:"on_challenge"`
 	OnChallengeSubmit []Hook              `yaml:"on_challenge_submit"`
+	OnResponse        []Hook              `yaml:"on_response"`
 	Options           AppsecSubEngineOpts `yaml:"options"`
 	VariablesTracking []string            `yaml:"variables_tracking"`
 }
@@ -534,6 +556,7 @@ type AppsecConfig struct {
 	OnMatch           []Hook              `yaml:"on_match"`
 	OnChallenge       []Hook              `yaml:"on_challenge"`
 	OnChallengeSubmit []Hook              `yaml:"on_challenge_submit"`
+	OnResponse        []Hook              `yaml:"on_response"`
 	VariablesTracking []string            `yaml:"variables_tracking"`
 	InbandOptions     AppsecSubEngineOpts `yaml:"inband_options"`
 	OutOfBandOptions  AppsecSubEngineOpts `yaml:"outofband_options"`
@@ -680,6 +703,10 @@ func (wc *AppsecConfig) LoadByPath(file string) error {
 		wc.OnChallengeSubmit = append(wc.OnChallengeSubmit, tmp.OnChallengeSubmit...)
 	}
 
+	if tmp.OnResponse != nil {
+		wc.OnResponse = append(wc.OnResponse, tmp.OnResponse...)
+	}
+
 	if tmp.VariablesTracking != nil {
 		wc.VariablesTracking = append(wc.VariablesTracking, tmp.VariablesTracking...)
 	}
@@ -699,6 +726,7 @@ func (wc *AppsecConfig) LoadByPath(file string) error {
 		wc.InBand.PostEval = append(wc.InBand.PostEval, tmp.InBand.PostEval...)
 		wc.InBand.OnChallenge = append(wc.InBand.OnChallenge, tmp.InBand.OnChallenge...)
 		wc.InBand.OnChallengeSubmit = append(wc.InBand.OnChallengeSubmit, tmp.InBand.OnChallengeSubmit...)
+		wc.InBand.OnResponse = append(wc.InBand.OnResponse, tmp.InBand.OnResponse...)
 	}
 
 	if tmp.OutOfBand != nil {
@@ -711,6 +739,7 @@ func (wc *AppsecConfig) LoadByPath(file string) error {
 		wc.OutOfBand.PostEval = append(wc.OutOfBand.PostEval, tmp.OutOfBand.PostEval...)
 		wc.OutOfBand.OnChallenge = append(wc.OutOfBand.OnChallenge, tmp.OutOfBand.OnChallenge...)
 		wc.OutOfBand.OnChallengeSubmit = append(wc.OutOfBand.OnChallengeSubmit, tmp.OutOfBand.OnChallengeSubmit...)
+		wc.OutOfBand.OnResponse = append(wc.OutOfBand.OnResponse, tmp.OutOfBand.OnResponse...)
 	}
 
 	// override other options
@@ -731,6 +760,10 @@ func (wc *AppsecConfig) LoadByPath(file string) error {
 		wc.InbandOptions.RequestBodyInMemoryLimit = tmp.InbandOptions.RequestBodyInMemoryLimit
 	}
 
+	if tmp.InbandOptions.ResponseBodyMimeTypes != nil {
+		wc.InbandOptions.ResponseBodyMimeTypes = tmp.InbandOptions.ResponseBodyMimeTypes
+	}
+
 	if tmp.OutOfBandOptions.DisableBodyInspection {
 		wc.OutOfBandOptions.DisableBodyInspection = true
 	}
@@ -739,6 +772,10 @@ func (wc *AppsecConfig) LoadByPath(file string) error {
 		wc.OutOfBandOptions.RequestBodyInMemoryLimit = tmp.OutOfBandOptions.RequestBodyInMemoryLimit
 	}
 
+	if tmp.OutOfBandOptions.ResponseBodyMimeTypes != nil {
+		wc.OutOfBandOptions.ResponseBodyMimeTypes = tmp.OutOfBandOptions.ResponseBodyMimeTypes
+	}
+
 	// Merge challenge tuning field by field so multiple appsec-configs can
 	// each contribute a disjoint subset without one wiping out the others.
 	// Each non-nil field in tmp overrides the corresponding field in wc.
@@ -768,6 +805,10 @@ func (wc *AppsecConfig) normalizePhaseScoped() {
 			wc.InbandOptions.RequestBodyInMemoryLimit = wc.InBand.Options.RequestBodyInMemoryLimit
 		}
 
+		if wc.InBand.Options.ResponseBodyMimeTypes != nil {
+			wc.InbandOptions.ResponseBodyMimeTypes = wc.InBand.Options.ResponseBodyMimeTypes
+		}
+
 		wc.VariablesTracking = append(wc.VariablesTracking, wc.InBand.VariablesTracking...)
 		wc.InBand.VariablesTracking = nil
 	}
@@ -784,6 +825,10 @@ func (wc *AppsecConfig) normalizePhaseScoped() {
 			wc.OutOfBandOptions.RequestBodyInMemoryLimit = wc.OutOfBand.Options.RequestBodyInMemoryLimit
 		}
 
+		if wc.OutOfBand.Options.ResponseBodyMimeTypes != nil {
+			wc.OutOfBandOptions.ResponseBodyMimeTypes = wc.OutOfBand.Options.ResponseBodyMimeTypes
+		}
+
 		wc.VariablesTracking = append(wc.VariablesTracking, wc.OutOfBand.VariablesTracking...)
 		wc.OutOfBand.VariablesTracking = nil
 	}
@@ -808,9 +853,9 @@ func buildHookList(ctx context.Context, hooks []Hook, stage hookStage, patcher *
 	return compiled, nil
 }
 
-// buildPhaseHooks compiles pre_eval / post_eval / on_match hook lists into a
+// buildPhaseHooks compiles pre_eval / post_eval / on_match / on_response hook lists into a
 // PhaseHooks. phaseName is only used to wrap errors ("" for the shared section).
-func buildPhaseHooks(ctx context.Context, phaseName string, pre, post, onMatch []Hook, patcher *appsecExprPatcher) (PhaseHooks, error) {
+func buildPhaseHooks(ctx context.Context, phaseName string, pre, post, onMatch, onResponse []Hook, patcher *appsecExprPatcher) (PhaseHooks, error) {
 	var (
 		out PhaseHooks
 		err error
@@ -835,6 +880,10 @@ func buildPhaseHooks(ctx context.Context, phaseName string, pre, post, onMatch [
 		return PhaseHooks{}, wrap(err)
 	}
 
+	if out.OnResponse, err = buildHookList(ctx, onResponse, hookOnResponse, patcher); err != nil {
+		return PhaseHooks{}, wrap(err)
+	}
+
 	return out, nil
 }
 
@@ -953,20 +1002,20 @@ func (wc *AppsecConfig) Build(ctx context.Context, hub *cwhub.Hub) (*AppsecRunti
 		return nil, err
 	}
 
-	if ret.CommonHooks, err = buildPhaseHooks(ctx, "", wc.PreEval, wc.PostEval, wc.OnMatch, patcher); err != nil {
+	if ret.CommonHooks, err = buildPhaseHooks(ctx, "", wc.PreEval, wc.PostEval, wc.OnMatch, wc.OnResponse, patcher); err != nil {
 		return nil, err
 	}
 
 	if wc.InBand != nil {
 		if ret.InBandHooks, err = buildPhaseHooks(ctx, "inband",
-			wc.InBand.PreEval, wc.InBand.PostEval, wc.InBand.OnMatch, patcher); err != nil {
+			wc.InBand.PreEval, wc.InBand.PostEval, wc.InBand.OnMatch, wc.InBand.OnResponse, patcher); err != nil {
 			return nil, err
 		}
 	}
 
 	if wc.OutOfBand != nil {
 		if ret.OutOfBandHooks, err = buildPhaseHooks(ctx, "outofband",
-			wc.OutOfBand.PreEval, wc.OutOfBand.PostEval, wc.OutOfBand.OnMatch, patcher); err != nil {
+			wc.OutOfBand.PreEval, wc.OutOfBand.PostEval, wc.OutOfBand.OnMatch, wc.OutOfBand.OnResponse, patcher); err != nil {
 			return nil, err
 		}
 
@@ -1250,6 +1299,11 @@ func (w *AppsecRuntimeConfig) ProcessPostEvalRules(ctx context.Context, state *A
 	return w.runPhaseHooks(hookPostEval, GetPostEvalEnv(ctx, w, state, request), request)
 }
 
+// ProcessOnResponseRules runs the on_response hooks, before the response phase is evaluated by the WAF.
+func (w *AppsecRuntimeConfig) ProcessOnResponseRules(state *AppsecRequestState, request *ParsedRequest) error {
+	return w.runPhaseHooks(hookOnResponse, GetOnResponseEnv(w, state, request), request)
+}
+
 func (w *AppsecRuntimeConfig) RemoveInbandRuleByID(state *AppsecRequestState, id int) error {
 	if state.CurrentPhase != PhaseInBand {
 		w.Logger.Warnf("cannot remove inband rule %d when not in inband phase", id)
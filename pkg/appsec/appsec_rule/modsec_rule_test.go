@@ -336,6 +336,39 @@ SecRule REQUEST_FILENAME "@contains /admin" "id:2534092724,phase:2,log,msg:'test
 SecRule REQUEST_METHOD "@streq POST" "id:220760465,phase:2,deny,log,msg:'test rule',tag:'crowdsec-Deep nesting',tag:'cs-custom-rule',chain"
 SecRule ARGS_GET:cmd "@rx exec" "id:2515501355,phase:2,log,msg:'test rule',tag:'crowdsec-Deep nesting',tag:'cs-custom-rule',chain"
 SecRule REQUEST_HEADERS:x-debug "@streq true" "id:1447329030,phase:2,log,msg:'test rule',tag:'crowdsec-Deep nesting',tag:'cs-custom-rule'"`,
+		},
+		{
+			name:        "Response body",
+			description: "test rule",
+			rule: CustomRule{
+				Zones: []string{"RESPONSE_BODY"},
+				Match: Match{Type: "contains", Value: "Traceback (most recent call last)"},
+			},
+			expected: `SecRule RESPONSE_BODY "@contains Traceback (most recent call last)" "id:174998338,phase:4,deny,log,msg:'test rule',tag:'crowdsec-Response body',tag:'cs-custom-rule',severity:'emergency'"`,
+		},
+		{
+			name:        "Response status and request",
+			description: "test rule",
+			rule: CustomRule{
+				And: []CustomRule{
+					{
+						Zones: []string{"RESPONSE_STATUS"},
+						Match: Match{Type: "equals", Value: "200"},
+					},
+					{
+						Zones:     []string{"RESPONSE_HEADERS"},
+						Variables: []string{"content-type"},
+						Match:     Match{Type: "contains", Value: "text/html"},
+					},
+					{
+						Zones: []string{"URI"},
+						Match: Match{Type: "endsWith", Value: "/"},
+					},
+				},
+			},
+			expected: `SecRule RESPONSE_STATUS "@streq 200" "id:2063393651,phase:3,deny,log,msg:'test rule',tag:'crowdsec-Response status and request',tag:'cs-custom-rule',severity:'emergency',chain"
+SecRule RESPONSE_HEADERS:content-type "@contains text/html" "id:40258323,phase:3,log,msg:'test rule',tag:'crowdsec-Response status and request',tag:'cs-custom-rule',chain"
+SecRule REQUEST_FILENAME "@endsWith /" "id:3990166239,phase:3,log,msg:'test rule',tag:'crowdsec-Response status and request',tag:'cs-custom-rule'"`,
 		},
 		{
 			name: "all transforms",
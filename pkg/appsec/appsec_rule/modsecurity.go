@@ -16,6 +16,8 @@ type ModsecurityRule struct {
 	// identical leaves of different rules don't collide (positions restart at 0
 	// per rule).
 	ruleIndex int
+	// phase is the coraza phase the rule is evaluated in.
+	phase int
 }
 
 var zonesMap = map[string]string{
@@ -36,6 +38,22 @@ var zonesMap = map[string]string{
 	"URI_FULL":         "REQUEST_URI",
 	"RAW_BODY":         "RAW_REQUEST_BODY",
 	"FILENAMES":        "FILES",
+	// response zones, only populated when the remediation component forwards the upstream response
+	"RESPONSE_STATUS":        "RESPONSE_STATUS",
+	"RESPONSE_HEADERS":       "RESPONSE_HEADERS",
+	"RESPONSE_HEADERS_NAMES": "RESPONSE_HEADERS_NAMES",
+	"RESPONSE_BODY":          "RESPONSE_BODY",
+	"RESPONSE_CONTENT_TYPE":  "RESPONSE_CONTENT_TYPE",
+}
+
+// zonesPhase is the coraza phase needed to evaluate a zone. Zones that are not
+// listed are available in the request body phase (2).
+var zonesPhase = map[string]int{
+	"RESPONSE_STATUS":        3,
+	"RESPONSE_HEADERS":       3,
+	"RESPONSE_HEADERS_NAMES": 3,
+	"RESPONSE_CONTENT_TYPE":  3,
+	"RESPONSE_BODY":          4,
 }
 
 var transformMap = map[string]string{
@@ -115,6 +133,10 @@ func (m *ModsecurityRule) Build(rule *CustomRule, appsecRuleName string, appsecR
 		return "", nil, err
 	}
 
+	// skip and chain only work within a phase, so the whole rule is evaluated
+	// in the latest phase needed by one of its zones
+	m.phase = rulePhase(dnf)
+
 	rules, err := m.buildFromDNF(dnf, rule.Severity, appsecRuleName, appsecRuleDescription)
 	if err != nil {
 		return "", nil, err
@@ -123,6 +145,21 @@ func (m *ModsecurityRule) Build(rule *CustomRule, appsecRuleName string, appsecR
 	return strings.Join(rules, "\n"), m.ids, nil
 }
 
+// rulePhase returns the phase in which all the zones of the rule are available.
+func rulePhase(dnf [][]*CustomRule) int {
+	phase := 2
+
+	for _, group := range dnf {
+		for _, leaf := range group {
+			for _, zone := range leaf.Zones {
+				phase = max(phase, zonesPhase[zone])
+			}
+		}
+	}
+
+	return phase
+}
+
 // leafCopy returns a shallow copy of the rule with And/Or cleared.
 func leafCopy(rule *CustomRule) *CustomRule {
 	cp := *rule
@@ -362,7 +399,7 @@ func (m *ModsecurityRule) buildSingleRule(rule *CustomRule, severity string, app
 		msg = appsecRuleName
 	}
 
-	r.WriteString(fmt.Sprintf(` "id:%d,phase:2`, m.generateRuleID(rule, appsecRuleName, opts.position)))
+	r.WriteString(fmt.Sprintf(` "id:%d,phase:%d`, m.generateRuleID(rule, appsecRuleName, opts.position), m.phase))
 
 	// Disruptive actions are only accepted on the chain starter, where coraza
 	// evaluates them once the whole chain matched.
@@ -62,7 +62,14 @@ func baseEventFromRequest(r *ParsedRequest, labels map[string]string, txUuid, so
 }
 
 func EventFromRequest(r *ParsedRequest, labels map[string]string, txUuid string) (pipeline.Event, error) {
-	return baseEventFromRequest(r, labels, txUuid, SourceWAF), nil
+	evt := baseEventFromRequest(r, labels, txUuid, SourceWAF)
+
+	// matches of the response phase carry the upstream status
+	if r.Response != nil {
+		evt.Parsed["response_status"] = strconv.Itoa(r.Response.StatusCode)
+	}
+
+	return evt, nil
 }
 
 // ChallengeEventInfo describes a single challenge lifecycle moment to be turned
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
@@ -27,8 +28,19 @@ const (
 	UserAgentHeaderName     = "X-Crowdsec-Appsec-User-Agent"
 	HTTPVersionHeaderName   = "X-Crowdsec-Appsec-Http-Version"
 	TransactionIDHeaderName = "X-Crowdsec-Appsec-Transaction-Id"
+	// ResponseStatusHeaderName carries the status code of the upstream response, on the response endpoint.
+	ResponseStatusHeaderName = "X-Crowdsec-Appsec-Response-Status"
 )
 
+// ParsedResponse is the upstream response forwarded by the remediation component
+// for the response phase.
+type ParsedResponse struct {
+	StatusCode    int         `json:"status_code,omitempty"`
+	Headers       http.Header `json:"headers,omitempty"`
+	Body          []byte      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"body_truncated,omitempty"`
+}
+
 type ParsedRequest struct {
 	RemoteAddr           string                  `json:"remote_addr,omitempty"`
 	Host                 string                  `json:"host,omitempty"`
@@ -54,6 +66,10 @@ type ParsedRequest struct {
 	// BodySizeExceeded is true when the body exceeded the configured limit and the action is drop.
 	// The body is not populated in this case; a fake interruption will be triggered in the runner.
 	BodySizeExceeded bool `json:"body_size_exceeded,omitempty"`
+	// IsResponse is true when the request carries the upstream response of a request that
+	// was already inspected, and only the response phase must be evaluated.
+	IsResponse bool            `json:"-"`
+	Response   *ParsedResponse `json:"response,omitempty"`
 }
 
 type ReqDumpFilter struct {
@@ -303,6 +319,7 @@ var forwardedHeaders = []string{
 	APIKeyHeaderName,
 	HTTPVersionHeaderName,
 	TransactionIDHeaderName,
+	ResponseStatusHeaderName,
 }
 
 // readRequestBody reads r.Body bounded by bodySettings.MaxSize, applies the oversize action, and
@@ -486,3 +503,54 @@ func NewParsedRequestFromRequest(r *http.Request, logger *log.Entry, bodySetting
 		HTTPRequest:          originalHTTPRequest,
 	}, nil
 }
+
+// NewParsedResponseFromRequest generates a ParsedRequest for the response phase from a http.Request
+// sent to the response endpoint. The original request is described by the same X-Crowdsec-Appsec-*
+// headers as for the request endpoint, the other headers and the body are the ones of the upstream response.
+func NewParsedResponseFromRequest(r *http.Request, logger *log.Entry, bodySettings BodySettings) (ParsedRequest, error) {
+	status := r.Header.Get(ResponseStatusHeaderName)
+	if status == "" {
+		return ParsedRequest{}, fmt.Errorf("missing '%s' header", ResponseStatusHeaderName)
+	}
+
+	statusCode, err := strconv.Atoi(status)
+	if err != nil || statusCode < 100 || statusCode > 999 {
+		return ParsedRequest{}, fmt.Errorf("invalid '%s' header: %s", ResponseStatusHeaderName, status)
+	}
+
+	userAgent := r.Header.Get(UserAgentHeaderName)
+
+	// the body size limit applies, but an oversized response is inspected partially, never dropped
+	bodySettings.Action = BodySizeActionPartial
+
+	parsed, err := NewParsedRequestFromRequest(r, logger, bodySettings)
+	if err != nil {
+		return ParsedRequest{}, err
+	}
+
+	// the User-Agent has been set from the forwarded one, it's not a response header
+	responseHeaders := parsed.Headers.Clone()
+	responseHeaders.Del("User-Agent")
+
+	parsed.IsResponse = true
+	parsed.Response = &ParsedResponse{
+		StatusCode:    statusCode,
+		Headers:       responseHeaders,
+		Body:          parsed.Body,
+		BodyTruncated: parsed.BodyTruncated,
+	}
+
+	// the headers and body of the original request are not known
+	parsed.Headers = http.Header{}
+	parsed.Body = nil
+	parsed.BodyTruncated = false
+	parsed.TransferEncoding = nil
+	parsed.HTTPRequest.Header = http.Header{}
+	parsed.HTTPRequest.Body = http.NoBody
+
+	if userAgent != "" {
+		parsed.HTTPRequest.Header.Set("User-Agent", userAgent)
+	}
+
+	return parsed, nil
+}
@@ -273,3 +273,41 @@ func TestNewParsedRequestFromRequestBodyLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestNewParsedResponseFromRequest(t *testing.T) {
+	logger := log.WithField("test", "response")
+
+	r := makeTestRequest(t, []byte(`{"error": "stack trace"}`))
+	r.Header.Set(ResponseStatusHeaderName, "500")
+	r.Header.Set(UserAgentHeaderName, "curl/8.0")
+	r.Header.Set("Content-Type", "application/json")
+
+	parsed, err := NewParsedResponseFromRequest(r, logger, BodySettings{MaxSize: 10, Action: BodySizeActionDrop})
+	require.NoError(t, err)
+
+	require.True(t, parsed.IsResponse)
+	require.NotNil(t, parsed.Response)
+	require.Equal(t, 500, parsed.Response.StatusCode)
+	require.Equal(t, "application/json", parsed.Response.Headers.Get("Content-Type"))
+	require.Empty(t, parsed.Response.Headers.Get("User-Agent"))
+	require.Empty(t, parsed.Response.Headers.Get(ResponseStatusHeaderName))
+
+	// an oversized response is inspected partially
+	require.Equal(t, []byte(`{"error": `), parsed.Response.Body)
+	require.True(t, parsed.Response.BodyTruncated)
+	require.False(t, parsed.BodySizeExceeded)
+
+	// the original request headers and body are not known
+	require.Empty(t, parsed.Headers)
+	require.Nil(t, parsed.Body)
+	require.Equal(t, "curl/8.0", parsed.HTTPRequest.UserAgent())
+
+	r = makeTestRequest(t, nil)
+	_, err = NewParsedResponseFromRequest(r, logger, BodySettings{})
+	require.ErrorContains(t, err, "missing 'X-Crowdsec-Appsec-Response-Status' header")
+
+	r = makeTestRequest(t, nil)
+	r.Header.Set(ResponseStatusHeaderName, "nope")
+	_, err = NewParsedResponseFromRequest(r, logger, BodySettings{})
+	require.ErrorContains(t, err, "invalid 'X-Crowdsec-Appsec-Response-Status' header")
+}
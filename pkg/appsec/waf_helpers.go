@@ -164,6 +164,35 @@ func GetPostEvalEnv(ctx context.Context, w *AppsecRuntimeConfig, state *AppsecRe
 	}
 }
 
+// GetOnResponseEnv is the env exposed to on_response hooks. They run before the
+// response phase is evaluated, and can disable rules or change the remediation
+// for this response. The original request headers and body are not available.
+func GetOnResponseEnv(w *AppsecRuntimeConfig, state *AppsecRequestState, request *ParsedRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"IsInBand":                request.IsInBand,
+		"IsOutBand":               request.IsOutBand,
+		"req":                     request.HTTPRequest,
+		"resp":                    request.Response,
+		"hook_vars":               state.HookVars,
+		"RemoveInBandRuleByID":    func(id int) error { return w.RemoveInbandRuleByID(state, id) },
+		"RemoveInBandRuleByName":  func(name string) error { return w.RemoveInbandRuleByName(state, name) },
+		"RemoveInBandRuleByTag":   func(tag string) error { return w.RemoveInbandRuleByTag(state, tag) },
+		"RemoveOutBandRuleByID":   func(id int) error { return w.RemoveOutbandRuleByID(state, id) },
+		"RemoveOutBandRuleByTag":  func(tag string) error { return w.RemoveOutbandRuleByTag(state, tag) },
+		"RemoveOutBandRuleByName": func(name string) error { return w.RemoveOutbandRuleByName(state, name) },
+		"DropRequest":             func(reason string) error { return w.DropRequest(state, request, reason) },
+		"SetRemediation": func(action string) error {
+			state.PendingAction = &action
+			return nil
+		},
+		"SetReturnCode": func(code int) error {
+			state.PendingHTTPCode = &code
+			return nil
+		},
+		"DumpRequest": request.DumpRequest,
+	}
+}
+
 func GetOnChallengeEnv(ctx context.Context, w *AppsecRuntimeConfig, state *AppsecRequestState, request *ParsedRequest) map[string]interface{} {
 	return map[string]interface{}{
 		"req":         request.HTTPRequest,
@@ -284,6 +313,8 @@ func GetOnMatchEnv(w *AppsecRuntimeConfig, state *AppsecRequestState, request *P
 		"hook_vars":           state.HookVars,
 		"IsInBand":            request.IsInBand,
 		"IsOutBand":           request.IsOutBand,
+		"IsResponse":          request.IsResponse,
+		"resp":                request.Response,
 		"SetRemediation":      func(action string) error { return w.SetAction(state, action) },
 		"SetReturnCode":       func(code int) error { return w.SetHTTPCode(state, code) },
 		"CancelEvent":         func() error { return w.CancelEvent(state) },
@@ -0,0 +1,81 @@
+package csconfig
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const (
+	AlertSpoolDropOldest = "oldest"
+	AlertSpoolDropNewest = "newest"
+
+	defaultAlertSpoolMaxAlerts = 100000
+	defaultAlertSpoolMaxSize   = 100 // MB
+	defaultAlertSpoolMaxAge    = 24 * time.Hour
+)
+
+// AlertSpoolCfg configures where the agent keeps the alerts it could not send to the LAPI.
+type AlertSpoolCfg struct {
+	Enable    *bool         `yaml:"enabled"`
+	Dir       string        `yaml:"dir,omitempty"`
+	MaxAlerts int           `yaml:"max_alerts,omitempty"`
+	MaxSize   int           `yaml:"max_size,omitempty"` // in MB
+	MaxAge    time.Duration `yaml:"max_age,omitempty"`
+	// which alerts are dropped when the spool is full
+	DropPolicy string `yaml:"drop_policy,omitempty"`
+}
+
+func (c *AlertSpoolCfg) IsEnabled() bool {
+	return c != nil && c.Enable != nil && *c.Enable
+}
+
+func (c *Config) loadAlertSpool() error {
+	spool := c.Crowdsec.AlertSpool
+	if spool == nil {
+		return nil
+	}
+
+	if spool.Enable == nil {
+		spool.Enable = new(true)
+	}
+
+	if !*spool.Enable {
+		return nil
+	}
+
+	if spool.Dir == "" {
+		if c.ConfigPaths == nil || c.ConfigPaths.DataDir == "" {
+			return errors.New("alert_spool.dir is required when config_paths.data_dir is not set")
+		}
+
+		spool.Dir = filepath.Join(c.ConfigPaths.DataDir, "alert_spool")
+	}
+
+	if spool.MaxAlerts < 0 || spool.MaxSize < 0 || spool.MaxAge < 0 {
+		return errors.New("alert_spool limits can't be negative")
+	}
+
+	if spool.MaxAlerts == 0 {
+		spool.MaxAlerts = defaultAlertSpoolMaxAlerts
+	}
+
+	if spool.MaxSize == 0 {
+		spool.MaxSize = defaultAlertSpoolMaxSize
+	}
+
+	if spool.MaxAge == 0 {
+		spool.MaxAge = defaultAlertSpoolMaxAge
+	}
+
+	switch spool.DropPolicy {
+	case "":
+		spool.DropPolicy = AlertSpoolDropOldest
+	case AlertSpoolDropOldest, AlertSpoolDropNewest:
+	default:
+		return fmt.Errorf("invalid alert_spool.drop_policy %q, must be %s or %s", spool.DropPolicy, AlertSpoolDropOldest, AlertSpoolDropNewest)
+	}
+
+	return nil
+}
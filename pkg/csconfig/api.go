@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/netip"
 	"os"
@@ -246,6 +247,7 @@ type LocalApiServerCfg struct {
 	CapiWhitelists                *CapiWhitelist           `yaml:"-"`
 	AutoRegister                  *LocalAPIAutoRegisterCfg `yaml:"auto_registration,omitempty"`
 	DisableUsageMetricsExport     bool                     `yaml:"disable_usage_metrics_export"`
+	RateLimit                     *LocalAPIRateLimitCfg    `yaml:"rate_limit,omitempty"`
 }
 
 // NewAccessLogger builds and returns a logger configured for HTTP access
@@ -321,6 +323,27 @@ type LocalAPIAutoRegisterCfg struct {
 	AllowedRangesParsed []*net.IPNet `yaml:"-"`
 }
 
+// RateLimit is a token bucket: requests are allowed at Rate per second, with bursts up to Burst.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// RouteRateLimit overrides the default rate limit for a route (as declared in the router, ie. "/v1/alerts/:alert_id").
+// An empty method applies to all of them.
+type RouteRateLimit struct {
+	Route     string `yaml:"route"`
+	Method    string `yaml:"method,omitempty"`
+	RateLimit `yaml:",inline"`
+}
+
+// LocalAPIRateLimitCfg limits the requests of each client (machine, bouncer or source IP for the unauthenticated routes).
+type LocalAPIRateLimitCfg struct {
+	Enable  *bool            `yaml:"enabled"`
+	Default *RateLimit       `yaml:"default,omitempty"`
+	Routes  []RouteRateLimit `yaml:"routes,omitempty"`
+}
+
 func (c *LocalApiServerCfg) ClientURL() string {
 	if c == nil {
 		return ""
@@ -414,6 +437,10 @@ func (c *Config) LoadAPIServer(inCli bool, skipOnlineCreds bool) error {
 		log.Infof("auto LAPI registration enabled for ranges %+v", c.API.Server.AutoRegister.AllowedRanges)
 	}
 
+	if err := c.API.Server.LoadRateLimit(); err != nil {
+		return err
+	}
+
 	if c.API.Server.UseForwardedForHeaders && c.API.Server.TrustedProxies == nil {
 		c.API.Server.TrustedProxies = &[]string{"0.0.0.0/0"}
 	}
@@ -560,3 +587,64 @@ func (c *LocalApiServerCfg) LoadAutoRegister() error {
 
 	return nil
 }
+
+func (l *RateLimit) validate() error {
+	if l.Rate <= 0 {
+		return fmt.Errorf("rate must be positive (got %v)", l.Rate)
+	}
+
+	if l.Burst < 0 {
+		return fmt.Errorf("burst can't be negative (got %d)", l.Burst)
+	}
+
+	if l.Burst == 0 {
+		l.Burst = int(math.Ceil(l.Rate))
+	}
+
+	return nil
+}
+
+func (c *LocalApiServerCfg) LoadRateLimit() error {
+	if c.RateLimit == nil {
+		c.RateLimit = &LocalAPIRateLimitCfg{
+			Enable: new(false),
+		}
+
+		return nil
+	}
+
+	// Enabled by default if the section is present
+	if c.RateLimit.Enable == nil {
+		c.RateLimit.Enable = new(true)
+	}
+
+	if !*c.RateLimit.Enable {
+		return nil
+	}
+
+	if c.RateLimit.Default == nil && len(c.RateLimit.Routes) == 0 {
+		return errors.New("api.server.rate_limit: a default limit or at least one route is required")
+	}
+
+	if c.RateLimit.Default != nil {
+		if err := c.RateLimit.Default.validate(); err != nil {
+			return fmt.Errorf("api.server.rate_limit.default: %w", err)
+		}
+	}
+
+	for i := range c.RateLimit.Routes {
+		route := &c.RateLimit.Routes[i]
+
+		if !strings.HasPrefix(route.Route, "/") {
+			return fmt.Errorf("api.server.rate_limit: route '%s' must start with '/'", route.Route)
+		}
+
+		route.Method = strings.ToUpper(route.Method)
+
+		if err := route.validate(); err != nil {
+			return fmt.Errorf("api.server.rate_limit: route '%s': %w", route.Route, err)
+		}
+	}
+
+	return nil
+}
@@ -228,6 +228,9 @@ func TestLoadAPIServer(t *testing.T) {
 					AllowedRanges:       nil,
 					AllowedRangesParsed: nil,
 				},
+				RateLimit: &LocalAPIRateLimitCfg{
+					Enable: new(false),
+				},
 			},
 		},
 		{
@@ -269,6 +272,73 @@ func TestLoadAPIServer(t *testing.T) {
 	}
 }
 
+func TestLoadRateLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       *LocalAPIRateLimitCfg
+		expected    *LocalAPIRateLimitCfg
+		expectedErr string
+	}{
+		{
+			name:     "not configured",
+			input:    nil,
+			expected: &LocalAPIRateLimitCfg{Enable: new(false)},
+		},
+		{
+			name:     "disabled",
+			input:    &LocalAPIRateLimitCfg{Enable: new(false), Default: &RateLimit{Rate: -1}},
+			expected: &LocalAPIRateLimitCfg{Enable: new(false), Default: &RateLimit{Rate: -1}},
+		},
+		{
+			name: "enabled by default, burst from rate",
+			input: &LocalAPIRateLimitCfg{
+				Default: &RateLimit{Rate: 2.5},
+				Routes: []RouteRateLimit{
+					{Route: "/v1/decisions/stream", Method: "get", RateLimit: RateLimit{Rate: 0.1, Burst: 3}},
+				},
+			},
+			expected: &LocalAPIRateLimitCfg{
+				Enable:  new(true),
+				Default: &RateLimit{Rate: 2.5, Burst: 3},
+				Routes: []RouteRateLimit{
+					{Route: "/v1/decisions/stream", Method: "GET", RateLimit: RateLimit{Rate: 0.1, Burst: 3}},
+				},
+			},
+		},
+		{
+			name:        "no limit",
+			input:       &LocalAPIRateLimitCfg{Enable: new(true)},
+			expectedErr: "a default limit or at least one route is required",
+		},
+		{
+			name:        "invalid rate",
+			input:       &LocalAPIRateLimitCfg{Default: &RateLimit{Rate: 0}},
+			expectedErr: "api.server.rate_limit.default: rate must be positive (got 0)",
+		},
+		{
+			name: "invalid route",
+			input: &LocalAPIRateLimitCfg{
+				Routes: []RouteRateLimit{{Route: "v1/alerts", RateLimit: RateLimit{Rate: 1}}},
+			},
+			expectedErr: "route 'v1/alerts' must start with '/'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &LocalApiServerCfg{RateLimit: tc.input}
+			err := c.LoadRateLimit()
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			assert.Equal(t, tc.expected, c.RateLimit)
+		})
+	}
+}
+
 func TestParseCapiWhitelists(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -41,7 +41,7 @@ type Config struct {
 	PluginConfig *PluginCfg          `yaml:"plugin_config,omitempty"`
 	DisableAPI   bool                `yaml:"-"`
 	DisableAgent bool                `yaml:"-"`
-	Hub          *LocalHubCfg        `yaml:"-"`
+	Hub          *LocalHubCfg        `yaml:"hub,omitempty"`
 }
 
 func NewConfig(configFile string, disableAgent bool, disableAPI bool, quiet bool) (*Config, string, error) {
@@ -95,6 +95,7 @@ func NewConfig(configFile string, disableAgent bool, disableAPI bool, quiet bool
 
 	cfg.loadHub()
 	cfg.loadCSCLI()
+	cfg.loadPluginOutbox()
 
 	globalConfig = cfg
 
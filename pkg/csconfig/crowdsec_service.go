@@ -25,9 +25,10 @@ type CrowdsecServiceCfg struct {
 	OutputRoutinesCount       int              `yaml:"output_routines"`
 	SimulationConfig          SimulationConfig `yaml:"-"`
 	BucketStateFile           string           `yaml:"state_input_file,omitempty"` // if we need to unserialize buckets at start
-	BucketStateDumpDir        string           `yaml:"state_output_dir,omitempty"` // if we need to unserialize buckets on shutdown
+	BucketStateDumpDir        string           `yaml:"state_output_dir,omitempty"` // if we need to serialize buckets on shutdown (in crowdsec-buckets-state.json)
 	BucketsGCEnabled          bool             `yaml:"-"`                          // we need to garbage collect buckets when in forensic mode
 	DNSCache                  *DNSCacheCfg     `yaml:"dns_cache,omitempty"`
+	AlertSpool                *AlertSpoolCfg   `yaml:"alert_spool,omitempty"`
 
 	SimulationFilePath string              `yaml:"-"`
 	ContextToSend      map[string][]string `yaml:"-"`
@@ -119,6 +120,8 @@ func (c *Config) LoadCrowdsec() error {
 		&c.Crowdsec.AcquisitionDirPath,
 		&c.Crowdsec.AcquisitionFilePath,
 		&c.Crowdsec.ConsoleContextPath,
+		&c.Crowdsec.BucketStateFile,
+		&c.Crowdsec.BucketStateDumpDir,
 	}
 
 	for _, p := range cleanup {
@@ -155,6 +158,10 @@ func (c *Config) LoadCrowdsec() error {
 		c.Crowdsec.OutputRoutinesCount = 1
 	}
 
+	if err = c.loadAlertSpool(); err != nil {
+		return fmt.Errorf("load error (alert_spool): %w", err)
+	}
+
 	if err = c.LoadAPIClient(); err != nil {
 		return fmt.Errorf("loading api client: %w", err)
 	}
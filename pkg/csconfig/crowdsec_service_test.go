@@ -224,3 +224,63 @@ dns_cache:
 	require.NoError(t, yaml.Unmarshal([]byte("acquisition_path: ./testdata/acquis.yaml"), &bare))
 	assert.Nil(t, bare.DNSCache)
 }
+
+func TestAlertSpoolCfg(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       *AlertSpoolCfg
+		expected    *AlertSpoolCfg
+		expectedErr string
+	}{
+		{
+			name:     "not configured",
+			input:    nil,
+			expected: nil,
+		},
+		{
+			name:  "defaults",
+			input: &AlertSpoolCfg{},
+			expected: &AlertSpoolCfg{
+				Enable:     new(true),
+				Dir:        "/var/lib/crowdsec/data/alert_spool",
+				MaxAlerts:  100000,
+				MaxSize:    100,
+				MaxAge:     24 * time.Hour,
+				DropPolicy: "oldest",
+			},
+		},
+		{
+			name:     "disabled",
+			input:    &AlertSpoolCfg{Enable: new(false)},
+			expected: &AlertSpoolCfg{Enable: new(false)},
+		},
+		{
+			name:        "bad drop policy",
+			input:       &AlertSpoolCfg{DropPolicy: "random"},
+			expectedErr: `invalid alert_spool.drop_policy "random", must be oldest or newest`,
+		},
+		{
+			name:        "negative limit",
+			input:       &AlertSpoolCfg{MaxAlerts: -1},
+			expectedErr: "alert_spool limits can't be negative",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				ConfigPaths: &ConfigurationPaths{DataDir: "/var/lib/crowdsec/data"},
+				Crowdsec:    &CrowdsecServiceCfg{AlertSpool: tc.input},
+			}
+
+			err := cfg.loadAlertSpool()
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			assert.Equal(t, tc.expected, cfg.Crowdsec.AlertSpool)
+		})
+	}
+}
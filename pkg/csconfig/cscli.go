@@ -11,6 +11,7 @@ type CscliCfg struct {
 	HubBranch        string           `yaml:"hub_branch"`
 	HubURLTemplate   string           `yaml:"__hub_url_template__,omitempty"`
 	HubWithContent   bool             `yaml:"hub_with_content,omitempty"`
+	HubValidate      bool             `yaml:"hub_validate,omitempty"` // load parsers and scenarios after changing the hub, roll back on error
 	SimulationConfig SimulationConfig `yaml:"-"`
 	DbConfig         *DatabaseCfg     `yaml:"-"`
 
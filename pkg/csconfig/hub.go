@@ -2,17 +2,40 @@ package csconfig
 
 // LocalHubCfg holds the configuration for a local hub: where to download etc.
 type LocalHubCfg struct {
-	HubIndexFile   string // Path to the local index file
-	HubDir         string // Where the hub items are downloaded
-	InstallDir     string // Where to install items
-	InstallDataDir string // Where to install data
+	HubIndexFile   string `yaml:"-"` // Path to the local index file
+	HubDir         string `yaml:"-"` // Where the hub items are downloaded
+	InstallDir     string `yaml:"-"` // Where to install items
+	InstallDataDir string `yaml:"-"` // Where to install data
+	// Ed25519 public keys (base64) trusted to sign the index. If set, the index
+	// is rejected when its signature is missing or invalid.
+	PublicKeys []string `yaml:"public_keys,omitempty"`
+	// Also require a signature for each item downloaded from the hub.
+	VerifyItems bool `yaml:"verify_items,omitempty"`
+	// Where to get the items from, by order of precedence. Defaults to the official hub.
+	Sources []HubSourceCfg `yaml:"sources,omitempty"`
+}
+
+// HubSourceCfg is a hub to install items from: the official one, a mirror,
+// or a private hub with the same layout.
+type HubSourceCfg struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // hub, http, dir or git
+	// http: URL template with placeholders for the branch and the file path.
+	// git: repository to clone, if the checkout is not managed outside of cscli.
+	URL    string `yaml:"url,omitempty"`
+	Branch string `yaml:"branch,omitempty"` // http, git
+	Path   string `yaml:"path,omitempty"`   // dir, git: where the index and items are
+	// http: ed25519 public keys (base64) trusted to sign the index of this source.
+	PublicKeys []string `yaml:"public_keys,omitempty"`
 }
 
 func (c *Config) loadHub() {
-	c.Hub = &LocalHubCfg{
-		HubIndexFile:   c.ConfigPaths.HubIndexFile,
-		HubDir:         c.ConfigPaths.HubDir,
-		InstallDir:     c.ConfigPaths.ConfigDir,
-		InstallDataDir: c.ConfigPaths.DataDir,
+	if c.Hub == nil {
+		c.Hub = &LocalHubCfg{}
 	}
+
+	c.Hub.HubIndexFile = c.ConfigPaths.HubIndexFile
+	c.Hub.HubDir = c.ConfigPaths.HubDir
+	c.Hub.InstallDir = c.ConfigPaths.ConfigDir
+	c.Hub.InstallDataDir = c.ConfigPaths.DataDir
 }
@@ -30,6 +30,56 @@ func TestLoadHub(t *testing.T) {
 				InstallDataDir: "./data",
 			},
 		},
+		{
+			name: "with signature verification",
+			input: &Config{
+				ConfigPaths: &ConfigurationPaths{
+					ConfigDir:    "./testdata",
+					DataDir:      "./data",
+					HubDir:       "./hub",
+					HubIndexFile: "./hub/.index.json",
+				},
+				Hub: &LocalHubCfg{
+					PublicKeys:  []string{"key1", "key2"},
+					VerifyItems: true,
+				},
+			},
+			expected: &LocalHubCfg{
+				HubDir:         "./hub",
+				HubIndexFile:   "./hub/.index.json",
+				InstallDir:     "./testdata",
+				InstallDataDir: "./data",
+				PublicKeys:     []string{"key1", "key2"},
+				VerifyItems:    true,
+			},
+		},
+		{
+			name: "with sources",
+			input: &Config{
+				ConfigPaths: &ConfigurationPaths{
+					ConfigDir:    "./testdata",
+					DataDir:      "./data",
+					HubDir:       "./hub",
+					HubIndexFile: "./hub/.index.json",
+				},
+				Hub: &LocalHubCfg{
+					Sources: []HubSourceCfg{
+						{Name: "internal", Type: "dir", Path: "/srv/hub"},
+						{Name: "crowdsec", Type: "hub"},
+					},
+				},
+			},
+			expected: &LocalHubCfg{
+				HubDir:         "./hub",
+				HubIndexFile:   "./hub/.index.json",
+				InstallDir:     "./testdata",
+				InstallDataDir: "./data",
+				Sources: []HubSourceCfg{
+					{Name: "internal", Type: "dir", Path: "/srv/hub"},
+					{Name: "crowdsec", Type: "hub"},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tests {
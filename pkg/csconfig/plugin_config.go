@@ -1,6 +1,49 @@
 package csconfig
 
+import (
+	"path/filepath"
+	"time"
+)
+
+const DefaultOutboxMaxAge = 24 * time.Hour
+
 type PluginCfg struct {
-	User  string
-	Group string
+	User   string
+	Group  string
+	Outbox *PluginOutboxCfg `yaml:"outbox,omitempty"`
+}
+
+// PluginOutboxCfg keeps the notifications on disk until they are delivered, so they
+// can be retried after a restart or a long outage of the notification endpoint.
+type PluginOutboxCfg struct {
+	Enable *bool  `yaml:"enabled"`
+	Dir    string `yaml:"dir,omitempty"`
+	// after this delay, undelivered notifications are not retried anymore
+	// and wait to be replayed with cscli
+	MaxAge time.Duration `yaml:"max_age,omitempty"`
+}
+
+// OutboxEnabled returns true if the notifications must go through the outbox.
+func (c *PluginCfg) OutboxEnabled() bool {
+	return c != nil && c.Outbox != nil && c.Outbox.Enable != nil && *c.Outbox.Enable
+}
+
+func (c *Config) loadPluginOutbox() {
+	if c.PluginConfig == nil || c.PluginConfig.Outbox == nil {
+		return
+	}
+
+	outbox := c.PluginConfig.Outbox
+
+	if outbox.Enable == nil {
+		outbox.Enable = new(true)
+	}
+
+	if outbox.Dir == "" && c.ConfigPaths != nil {
+		outbox.Dir = filepath.Join(c.ConfigPaths.DataDir, "notifications")
+	}
+
+	if outbox.MaxAge == 0 {
+		outbox.MaxAge = DefaultOutboxMaxAge
+	}
 }
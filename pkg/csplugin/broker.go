@@ -2,6 +2,7 @@ package csplugin
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -32,8 +33,10 @@ import (
 var pluginMutex sync.Mutex
 
 const (
-	PluginProtocolVersion uint   = 1
-	CrowdsecPluginKey     string = "CROWDSEC_PLUGIN_KEY"
+	PluginProtocolVersion uint = 1
+	// plugins that support version 2 receive the alerts along with the formatted text
+	PluginProtocolVersionAlerts uint   = 2
+	CrowdsecPluginKey           string = "CROWDSEC_PLUGIN_KEY"
 )
 
 // PluginBroker is responsible for running the plugins and dispatching events
@@ -46,11 +49,14 @@ type PluginBroker struct {
 	pluginConfigByName              map[string]PluginConfig
 	pluginMap                       map[string]plugin.Plugin
 	notificationPluginByName        map[string]protobufs.NotifierServer
+	protocolVersionByName           map[string]int
 	watcher                         PluginWatcher
 	pluginKillMethods               []func()
 	pluginProcConfig                *csconfig.PluginCfg
 	pluginsTypesToDispatch          map[string]struct{}
 	newBackoff                      backoffFactory
+	outbox                          *Outbox
+	outboxInflight                  sync.Map // IDs of the outbox entries being delivered
 }
 
 // holder to determine where to dispatch config and how to format messages
@@ -93,6 +99,7 @@ type PluginConfigList []PluginConfig
 func (pb *PluginBroker) Init(ctx context.Context, pluginCfg *csconfig.PluginCfg, profileConfigs []*csconfig.ProfileCfg, configPaths *csconfig.ConfigurationPaths) error {
 	pb.PluginChannel = make(chan models.ProfileAlert)
 	pb.notificationPluginByName = make(map[string]protobufs.NotifierServer)
+	pb.protocolVersionByName = make(map[string]int)
 	pb.pluginMap = make(map[string]plugin.Plugin)
 	pb.pluginConfigByName = make(map[string]PluginConfig)
 	pb.alertsByPluginName = make(map[string][]*models.Alert)
@@ -108,6 +115,17 @@ func (pb *PluginBroker) Init(ctx context.Context, pluginCfg *csconfig.PluginCfg,
 		return fmt.Errorf("loading plugin: %w", err)
 	}
 
+	if pluginCfg.OutboxEnabled() {
+		outbox, err := NewOutbox(pluginCfg.Outbox)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("notifications are stored in %s until delivered", pluginCfg.Outbox.Dir)
+
+		pb.outbox = outbox
+	}
+
 	pb.watcher = PluginWatcher{}
 	pb.watcher.Init(pb.pluginConfigByName, pb.alertsByPluginName)
 
@@ -133,6 +151,13 @@ func (pb *PluginBroker) Run(pluginTomb *tomb.Tomb) {
 
 	pb.watcher.Start(&tomb.Tomb{})
 
+	if pb.outbox != nil {
+		pluginTomb.Go(func() error {
+			pb.watchOutbox(pluginTomb)
+			return nil
+		})
+	}
+
 	for {
 		select {
 		case profileAlert := <-pb.PluginChannel:
@@ -300,7 +325,7 @@ func (pb *PluginBroker) loadPlugins(ctx context.Context, path string) error {
 			continue
 		}
 
-		pluginClient, err := pb.loadNotificationPlugin(ctx, pSubtype, binaryPath)
+		pluginClient, protocolVersion, err := pb.loadNotificationPlugin(ctx, pSubtype, binaryPath)
 		if err != nil {
 			return err
 		}
@@ -322,26 +347,27 @@ func (pb *PluginBroker) loadPlugins(ctx context.Context, path string) error {
 				return fmt.Errorf("while configuring %s: %w", pc.Name, err)
 			}
 
-			log.Infof("registered plugin %s", pc.Name)
+			log.Infof("registered plugin %s (protocol version %d)", pc.Name, protocolVersion)
 
 			pb.notificationPluginByName[pc.Name] = pluginClient
+			pb.protocolVersionByName[pc.Name] = protocolVersion
 		}
 	}
 
 	return pb.verifyPluginBinaryWithProfile()
 }
 
-func (pb *PluginBroker) loadNotificationPlugin(ctx context.Context, name string, binaryPath string) (protobufs.NotifierServer, error) {
+func (pb *PluginBroker) loadNotificationPlugin(ctx context.Context, name string, binaryPath string) (protobufs.NotifierServer, int, error) {
 	handshake, err := getHandshake()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	log.Debugf("Executing plugin %s", binaryPath)
 
 	cmd, err := pb.CreateCmd(ctx, binaryPath)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	pb.pluginMap[name] = &NotifierPlugin{}
@@ -350,8 +376,12 @@ func (pb *PluginBroker) loadNotificationPlugin(ctx context.Context, name string,
 	// without that, crowdsec log level is controlling plugins level
 	logger := NewHCLogAdapter(l, "")
 	c := plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig:  handshake,
-		Plugins:          pb.pluginMap,
+		HandshakeConfig: handshake,
+		// the plugin picks the highest version it supports
+		VersionedPlugins: map[int]plugin.PluginSet{
+			int(PluginProtocolVersion):       pb.pluginMap,
+			int(PluginProtocolVersionAlerts): pb.pluginMap,
+		},
 		Cmd:              cmd,
 		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
 		Logger:           logger,
@@ -359,20 +389,20 @@ func (pb *PluginBroker) loadNotificationPlugin(ctx context.Context, name string,
 
 	client, err := c.Client()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	raw, err := client.Dispense(name)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	pb.pluginKillMethods = append(pb.pluginKillMethods, c.Kill)
 
-	return raw.(protobufs.NotifierServer), nil
+	return raw.(protobufs.NotifierServer), c.NegotiatedVersion(), nil
 }
 
-func (pb *PluginBroker) tryNotify(ctx context.Context, pluginName, message string) error {
+func (pb *PluginBroker) tryNotify(ctx context.Context, pluginName, message string, alerts []byte) error {
 	// config guard
 	pc, ok := pb.pluginConfigByName[pluginName]
 	if !ok {
@@ -393,14 +423,34 @@ func (pb *PluginBroker) tryNotify(ctx context.Context, pluginName, message strin
 	_, err := plugin.Notify(
 		ctxTimeout,
 		&protobufs.Notification{
-			Text: message,
-			Name: pluginName,
+			Text:   message,
+			Name:   pluginName,
+			Alerts: alerts,
 		},
 	)
 
 	return err
 }
 
+// formatNotification returns the text of the notification, and the alerts if the plugin supports them
+func (pb *PluginBroker) formatNotification(pluginName string, alerts []*models.Alert) (string, []byte, error) {
+	message, err := FormatAlerts(pb.pluginConfigByName[pluginName].Format, alerts)
+	if err != nil {
+		return "", nil, fmt.Errorf("format alerts for notification: %w", err)
+	}
+
+	var alertsData []byte
+
+	if pb.protocolVersionByName[pluginName] >= int(PluginProtocolVersionAlerts) {
+		alertsData, err = json.Marshal(alerts)
+		if err != nil {
+			return "", nil, fmt.Errorf("serialize alerts for notification: %w", err)
+		}
+	}
+
+	return message, alertsData, nil
+}
+
 func (pb *PluginBroker) pushNotificationsToPlugin(ctx context.Context, pluginName string, alerts []*models.Alert) error {
 	logger := log.WithField("plugin", pluginName)
 
@@ -410,18 +460,25 @@ func (pb *PluginBroker) pushNotificationsToPlugin(ctx context.Context, pluginNam
 		return nil
 	}
 
-	pluginCfg := pb.pluginConfigByName[pluginName]
+	entry := pb.addToOutbox(pluginName, alerts, logger)
 
-	message, err := FormatAlerts(pluginCfg.Format, alerts)
+	if entry != nil {
+		defer pb.outboxInflight.Delete(entry.ID)
+	}
+
+	message, alertsData, err := pb.formatNotification(pluginName, alerts)
 	if err != nil {
-		return fmt.Errorf("format alerts for notification: %w", err)
+		pb.updateOutbox(ctx, entry, err, logger)
+		return err
 	}
 
+	pluginCfg := pb.pluginConfigByName[pluginName]
+
 	// make sure we have a default or custom backoff
 	pb.ensureBackoff()
 
 	err = retryWithBackoff(ctx, pluginCfg, logger, func(ctx context.Context) error {
-		return pb.tryNotify(ctx, pluginName, message)
+		return pb.tryNotify(ctx, pluginName, message, alertsData)
 	}, pb.newBackoff)
 	if err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
@@ -431,6 +488,8 @@ func (pb *PluginBroker) pushNotificationsToPlugin(ctx context.Context, pluginNam
 		}
 	}
 
+	pb.updateOutbox(ctx, entry, err, logger)
+
 	return err
 }
 
@@ -5,8 +5,10 @@ package csplugin
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -401,3 +403,102 @@ func (s *PluginSuite) TestBrokerRunSimple() {
 	err = decoder.Decode(&alerts)
 	assert.Equal(t, err, io.EOF)
 }
+
+func (s *PluginSuite) TestBrokerRunStructuredAlerts() {
+	ctx := s.T().Context()
+	DefaultEmptyTicker = 50 * time.Millisecond
+
+	t := s.T()
+
+	alertsFile := filepath.Join(t.TempDir(), "alerts")
+
+	cfg := s.readconfig()
+	cfg.Config["alerts_file"] = alertsFile
+	s.writeconfig(cfg)
+
+	pb, err := s.InitBroker(ctx, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, int(PluginProtocolVersionAlerts), pb.protocolVersionByName["dummy_default"])
+
+	tomb := tomb.Tomb{}
+	go pb.Run(&tomb)
+
+	scenario := "crowdsecurity/ssh-bf"
+	value := "1.2.3.4"
+	scope := "Ip"
+
+	pb.PluginChannel <- models.ProfileAlert{ProfileID: uint(0), Alert: &models.Alert{
+		Scenario: &scenario,
+		Source:   &models.Source{Scope: &scope, Value: &value},
+	}}
+	// make it wait a bit, CI can be slow
+	time.Sleep(time.Second)
+
+	content, err := os.ReadFile(alertsFile)
+	require.NoError(t, err, "Error reading file")
+
+	var alerts []models.Alert
+
+	err = json.Unmarshal(content, &alerts)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, scenario, alerts[0].GetScenario())
+	assert.Equal(t, value, alerts[0].GetValue())
+
+	// the text is still sent
+	assert.FileExists(t, s.outFile)
+}
+
+func (s *PluginSuite) TestBrokerOutbox() {
+	ctx := s.T().Context()
+	DefaultEmptyTicker = 50 * time.Millisecond
+
+	t := s.T()
+
+	outboxDir := filepath.Join(t.TempDir(), "outbox")
+
+	outbox, err := NewOutbox(&csconfig.PluginOutboxCfg{Dir: outboxDir})
+	require.NoError(t, err)
+
+	// left by a previous run
+	entry, err := NewOutboxEntry("dummy_default", []*models.Alert{{}})
+	require.NoError(t, err)
+
+	entry.Fail(errors.New("connection refused"), entry.QueuedAt.Add(-time.Hour), 24*time.Hour)
+	require.NoError(t, outbox.Save(entry))
+
+	pb, err := s.InitBroker(ctx, &csconfig.PluginCfg{
+		Outbox: &csconfig.PluginOutboxCfg{
+			Enable: new(true),
+			Dir:    outboxDir,
+		},
+	})
+	require.NoError(t, err)
+
+	tomb := tomb.Tomb{}
+	go pb.Run(&tomb)
+
+	pb.PluginChannel <- models.ProfileAlert{ProfileID: uint(0), Alert: &models.Alert{}}
+	// make it wait a bit, CI can be slow
+	time.Sleep(time.Second)
+
+	content, err := os.ReadFile(s.outFile)
+	require.NoError(t, err, "Error reading file")
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+
+	var alerts []models.Alert
+
+	// the retried notification and the new one
+	for range 2 {
+		err = decoder.Decode(&alerts)
+		require.NoError(t, err)
+		assert.Len(t, alerts, 1)
+	}
+
+	// delivered, nothing left
+	entries, err := outbox.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
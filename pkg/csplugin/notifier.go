@@ -2,11 +2,14 @@ package csplugin
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	plugin "github.com/hashicorp/go-plugin"
 	"google.golang.org/grpc"
 
+	"github.com/crowdsecurity/crowdsec/pkg/models"
 	"github.com/crowdsecurity/crowdsec/pkg/protobufs"
 )
 
@@ -24,7 +27,7 @@ func (m *GRPCClient) Notify(ctx context.Context, notification *protobufs.Notific
 	done := make(chan error)
 	go func() {
 		_, err := m.client.Notify(
-			ctx, &protobufs.Notification{Text: notification.GetText(), Name: notification.GetName()},
+			ctx, &protobufs.Notification{Text: notification.GetText(), Name: notification.GetName(), Alerts: notification.GetAlerts()},
 		)
 		done <- err
 	}()
@@ -54,3 +57,34 @@ func (p *NotifierPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) e
 func (*NotifierPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (any, error) {
 	return &GRPCClient{client: protobufs.NewNotifierClient(c)}, nil
 }
+
+// VersionedNotifierPlugins returns the plugin sets a notification plugin must serve to
+// support all the protocol versions. The version is negotiated with crowdsec during the handshake,
+// older versions of crowdsec use protocol 1 and only send the formatted text.
+func VersionedNotifierPlugins(name string, impl protobufs.NotifierServer) map[int]plugin.PluginSet {
+	plugins := plugin.PluginSet{
+		name: &NotifierPlugin{Impl: impl},
+	}
+
+	return map[int]plugin.PluginSet{
+		int(PluginProtocolVersion):       plugins,
+		int(PluginProtocolVersionAlerts): plugins,
+	}
+}
+
+// NotificationAlerts returns the alerts carried by a notification, or nil
+// if they have not been sent (protocol version 1).
+func NotificationAlerts(notification *protobufs.Notification) ([]*models.Alert, error) {
+	data := notification.GetAlerts()
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var alerts []*models.Alert
+
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		return nil, fmt.Errorf("invalid alerts in notification: %w", err)
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,364 @@
+package csplugin
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/tomb.v2"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+type DeliveryStatus string
+
+const (
+	// waiting for the first attempt, or being delivered
+	DeliveryPending DeliveryStatus = "pending"
+	// waiting for the next attempt
+	DeliveryFailed DeliveryStatus = "failed"
+	// not retried anymore, can be replayed with cscli
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+const (
+	outboxMinRetryInterval = time.Minute
+	outboxMaxRetryInterval = time.Hour
+)
+
+// OutboxEntry is a notification that has not been delivered yet.
+type OutboxEntry struct {
+	ID           string          `json:"id"`
+	Notification string          `json:"notification"`
+	Alerts       []*models.Alert `json:"alerts"`
+	Status       DeliveryStatus  `json:"status"`
+	Attempts     int             `json:"attempts"`
+	CreatedAt    time.Time       `json:"created_at"`
+	// when the notification was queued or replayed, the max_age is counted from here
+	QueuedAt    time.Time `json:"queued_at"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Fail records a failed attempt and schedules the next one, with an exponential backoff.
+// The entry is dead if it has been retried for longer than maxAge.
+func (e *OutboxEntry) Fail(err error, now time.Time, maxAge time.Duration) {
+	e.Attempts++
+	e.LastError = err.Error()
+
+	if now.Sub(e.QueuedAt) >= maxAge {
+		e.Status = DeliveryDead
+		e.NextAttempt = time.Time{}
+
+		return
+	}
+
+	interval := outboxMaxRetryInterval
+	if e.Attempts <= 6 {
+		interval = min(outboxMinRetryInterval<<(e.Attempts-1), outboxMaxRetryInterval)
+	}
+
+	e.Status = DeliveryFailed
+	e.NextAttempt = now.Add(interval)
+}
+
+// Replay queues the notification again, as if it was new.
+func (e *OutboxEntry) Replay(now time.Time) {
+	e.Status = DeliveryPending
+	e.Attempts = 0
+	e.QueuedAt = now
+	e.NextAttempt = now
+	e.LastError = ""
+}
+
+// Outbox stores the notifications in a directory, one file per notification, until they are delivered.
+// It's shared by the LAPI, which delivers them, and cscli which can list and replay them.
+type Outbox struct {
+	dir    string
+	maxAge time.Duration
+}
+
+// NewOutbox opens the outbox directory, creating it if needed. The default max_age
+// is used if the configuration has not been loaded with it.
+func NewOutbox(cfg *csconfig.PluginOutboxCfg) (*Outbox, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("the notification outbox directory is not set")
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("while creating notification outbox: %w", err)
+	}
+
+	return &Outbox{
+		dir:    cfg.Dir,
+		maxAge: cmp.Or(cfg.MaxAge, csconfig.DefaultOutboxMaxAge),
+	}, nil
+}
+
+func (o *Outbox) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid notification id %q", id)
+	}
+
+	return filepath.Join(o.dir, id+".json"), nil
+}
+
+// NewOutboxEntry returns a notification to be delivered now. It must be saved in the outbox.
+func NewOutboxEntry(notification string, alerts []*models.Alert) (*OutboxEntry, error) {
+	id, err := getUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	return &OutboxEntry{
+		ID:           id,
+		Notification: notification,
+		Alerts:       alerts,
+		Status:       DeliveryPending,
+		CreatedAt:    now,
+		QueuedAt:     now,
+		NextAttempt:  now,
+	}, nil
+}
+
+// Save writes the entry atomically, so that a concurrent reader never sees a partial file.
+func (o *Outbox) Save(entry *OutboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path, err := o.path(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(o.dir, ".tmp-"+entry.ID+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get returns a notification by ID.
+func (o *Outbox) Get(id string) (*OutboxEntry, error) {
+	path, err := o.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotificationNotFound, id)
+		}
+
+		return nil, err
+	}
+
+	entry := &OutboxEntry{}
+
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("while reading notification %s: %w", id, err)
+	}
+
+	return entry, nil
+}
+
+// Delete removes a notification, once it's delivered or discarded.
+func (o *Outbox) Delete(id string) error {
+	path, err := o.path(id)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// List returns all the notifications, oldest first.
+func (o *Outbox) List() ([]*OutboxEntry, error) {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*OutboxEntry, 0, len(files))
+
+	for _, file := range files {
+		name := file.Name()
+
+		if file.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		entry, err := o.Get(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			// delivered in the meantime
+			if errors.Is(err, ErrNotificationNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b *OutboxEntry) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return entries, nil
+}
+
+// how often the outbox is checked for notifications to retry
+var outboxScanInterval = 30 * time.Second
+
+// addToOutbox saves a notification before its first delivery attempt. Errors are logged,
+// the notification is still delivered but won't be retried after a restart.
+func (pb *PluginBroker) addToOutbox(pluginName string, alerts []*models.Alert, logger *log.Entry) *OutboxEntry {
+	if pb.outbox == nil {
+		return nil
+	}
+
+	entry, err := NewOutboxEntry(pluginName, alerts)
+	if err != nil {
+		logger.Errorf("unable to store notification in the outbox: %v", err)
+		return nil
+	}
+
+	// before saving, to not be picked up by the outbox watcher
+	pb.outboxInflight.Store(entry.ID, struct{}{})
+
+	if err := pb.outbox.Save(entry); err != nil {
+		pb.outboxInflight.Delete(entry.ID)
+		logger.Errorf("unable to store notification in the outbox: %v", err)
+
+		return nil
+	}
+
+	return entry
+}
+
+// updateOutbox removes a delivered notification from the outbox, or schedules its next attempt.
+func (pb *PluginBroker) updateOutbox(ctx context.Context, entry *OutboxEntry, deliveryErr error, logger *log.Entry) {
+	if entry == nil {
+		return
+	}
+
+	if deliveryErr == nil {
+		if err := pb.outbox.Delete(entry.ID); err != nil {
+			logger.Errorf("unable to remove notification %s from the outbox: %v", entry.ID, err)
+		}
+
+		return
+	}
+
+	if ctx.Err() != nil {
+		// shutting down, the notification is still pending and will be sent on next start
+		return
+	}
+
+	entry.Fail(deliveryErr, time.Now().UTC(), pb.outbox.maxAge)
+
+	if entry.Status == DeliveryDead {
+		logger.Errorf("giving up on notification %s after %d attempts, it can be replayed with 'cscli notifications outbox replay'", entry.ID, entry.Attempts)
+	} else {
+		logger.Infof("notification %s will be retried at %s", entry.ID, entry.NextAttempt.Format(time.RFC3339))
+	}
+
+	if err := pb.outbox.Save(entry); err != nil {
+		logger.Errorf("unable to update notification %s in the outbox: %v", entry.ID, err)
+	}
+}
+
+// watchOutbox retries the notifications that could not be delivered, including the ones
+// left by a previous run and the ones replayed by cscli.
+func (pb *PluginBroker) watchOutbox(pluginTomb *tomb.Tomb) {
+	ctx := pluginTomb.Context(context.Background())
+
+	ticker := time.NewTicker(outboxScanInterval)
+	defer ticker.Stop()
+
+	for {
+		pb.retryOutbox(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-pluginTomb.Dying():
+			return
+		}
+	}
+}
+
+func (pb *PluginBroker) retryOutbox(ctx context.Context) {
+	entries, err := pb.outbox.List()
+	if err != nil {
+		log.Errorf("unable to read the notification outbox: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now().UTC()
+
+		if entry.Status == DeliveryDead || entry.NextAttempt.After(now) {
+			continue
+		}
+
+		if _, ok := pb.outboxInflight.Load(entry.ID); ok {
+			continue
+		}
+
+		logger := log.WithField("plugin", entry.Notification)
+
+		if _, ok := pb.notificationPluginByName[entry.Notification]; !ok {
+			// not in the profiles anymore, keep it until max_age in case the configuration is fixed
+			pb.updateOutbox(ctx, entry, fmt.Errorf("notification %s is not configured", entry.Notification), logger)
+			continue
+		}
+
+		logger.Debugf("retrying notification %s (%d previous attempts)", entry.ID, entry.Attempts)
+
+		message, alertsData, err := pb.formatNotification(entry.Notification, entry.Alerts)
+		if err == nil {
+			err = pb.tryNotify(ctx, entry.Notification, message, alertsData)
+		}
+
+		pb.updateOutbox(ctx, entry, err, logger)
+	}
+}
@@ -0,0 +1,107 @@
+package csplugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func TestOutbox(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+
+	_, err := NewOutbox(&csconfig.PluginOutboxCfg{})
+	cstest.RequireErrorContains(t, err, "the notification outbox directory is not set")
+
+	// not loaded with the configuration file, the defaults are applied
+	outbox, err := NewOutbox(&csconfig.PluginOutboxCfg{Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, csconfig.DefaultOutboxMaxAge, outbox.maxAge)
+
+	entries, err := outbox.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	first, err := NewOutboxEntry("slack_default", []*models.Alert{{Scenario: new("crowdsecurity/ssh-bf")}})
+	require.NoError(t, err)
+	require.NoError(t, outbox.Save(first))
+
+	second, err := NewOutboxEntry("http_default", nil)
+	require.NoError(t, err)
+
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	require.NoError(t, outbox.Save(second))
+
+	// not an entry
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0o600))
+
+	entries, err = outbox.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, first.ID, entries[0].ID)
+	assert.Equal(t, "crowdsecurity/ssh-bf", entries[0].Alerts[0].GetScenario())
+	assert.Equal(t, second.ID, entries[1].ID)
+
+	got, err := outbox.Get(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeliveryPending, got.Status)
+
+	_, err = outbox.Get("../config")
+	cstest.RequireErrorContains(t, err, `invalid notification id "../config"`)
+
+	require.NoError(t, outbox.Delete(first.ID))
+
+	_, err = outbox.Get(first.ID)
+	require.ErrorIs(t, err, ErrNotificationNotFound)
+
+	entries, err = outbox.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestOutboxEntryFail(t *testing.T) {
+	entry, err := NewOutboxEntry("slack_default", nil)
+	require.NoError(t, err)
+
+	maxAge := 24 * time.Hour
+	now := entry.QueuedAt
+	deliveryErr := errors.New("connection refused")
+
+	entry.Fail(deliveryErr, now, maxAge)
+	assert.Equal(t, DeliveryFailed, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.Equal(t, "connection refused", entry.LastError)
+	assert.Equal(t, now.Add(time.Minute), entry.NextAttempt)
+
+	entry.Fail(deliveryErr, now, maxAge)
+	assert.Equal(t, now.Add(2*time.Minute), entry.NextAttempt)
+
+	for range 10 {
+		entry.Fail(deliveryErr, now, maxAge)
+	}
+
+	assert.Equal(t, now.Add(time.Hour), entry.NextAttempt, "capped")
+
+	entry.Fail(deliveryErr, now.Add(maxAge), maxAge)
+	assert.Equal(t, DeliveryDead, entry.Status)
+	assert.True(t, entry.NextAttempt.IsZero())
+
+	later := now.Add(48 * time.Hour)
+	entry.Replay(later)
+	assert.Equal(t, DeliveryPending, entry.Status)
+	assert.Equal(t, 0, entry.Attempts)
+	assert.Empty(t, entry.LastError)
+	assert.Equal(t, later, entry.QueuedAt)
+
+	entry.Fail(deliveryErr, later, maxAge)
+	assert.Equal(t, DeliveryFailed, entry.Status, "max_age counts from the replay")
+}
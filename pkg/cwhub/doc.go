@@ -95,6 +95,26 @@
 // The URLTemplate is a string that will be used to build the URL of the remote hub. It must contain two
 // placeholders: the branch and the file path (it will be an index or an item).
 //
+// To protect against a compromised mirror, the index can be verified with a detached ed25519 signature,
+// downloaded from the same URL with the ".sig" extension. The index is not written to disk if the signature
+// is missing or doesn't match one of the trusted keys:
+//
+//	verifier, err := cwhub.NewVerifier([]string{"base64-encoded public key"})
+//	if err != nil {
+//		return err
+//	}
+//
+//	indexProvider.Verifier = verifier
+//
+// Private items can be distributed like the official ones, from several sources merged in a single namespace.
+// Each source provides an index with the same format as the official hub. When an item is provided by more
+// than one source, the first one wins:
+//
+//	sources, err := cwhub.NewSources(
+//		cwhub.HubSource{Name: "internal", Provider: &cwhub.GitSource{Dir: "/var/lib/hub-internal", Repo: "https://git.example.com/hub.git"}},
+//		cwhub.HubSource{Name: "crowdsec", Provider: &indexProvider},
+//	)
+//
 // Before calling hub.Load(), you can update the index file by calling the Update() method:
 //
 //	err := hub.Update(context.Background(), indexProvider)
@@ -1,6 +1,7 @@
 package cwhub
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -20,6 +21,12 @@ type NotFoundError = downloader.NotFoundError
 type Downloader struct {
 	Branch      string
 	URLTemplate string
+	// if set, the index must have a valid detached signature
+	Verifier *Verifier
+	// the items must be signed too (requires Verifier)
+	VerifyItems bool
+	// accept an index without a valid signature, with a warning
+	AllowUnsigned bool
 }
 
 // IndexProvider retrieves and writes .index.json
@@ -65,6 +72,10 @@ func (d *Downloader) FetchIndex(ctx context.Context, destPath string, withConten
 		url.RawQuery = q.Encode()
 	}
 
+	if d.Verifier != nil {
+		return d.fetchSignedIndex(ctx, url, destPath, logger)
+	}
+
 	downloaded, err = downloader.
 		New().
 		WithHTTPClient(HubClient).
@@ -83,6 +94,51 @@ func (d *Downloader) FetchIndex(ctx context.Context, destPath string, withConten
 	return downloaded, nil
 }
 
+// fetchSignedIndex downloads the index next to the destination file, and only replaces it
+// once the signature has been verified.
+func (d *Downloader) fetchSignedIndex(ctx context.Context, url *url.URL, destPath string, logger *logrus.Logger) (bool, error) {
+	stagingPath := destPath + ".unverified"
+
+	defer os.Remove(stagingPath)
+
+	_, err := downloader.
+		New().
+		WithHTTPClient(HubClient).
+		ToFile(stagingPath).
+		WithMode(0o644).
+		WithLogger(logger.WithField("url", url)).
+		BeforeRequest(func(_ *http.Request) {
+			fmt.Fprintln(os.Stdout, "Downloading "+destPath)
+		}).
+		Download(ctx, url.String())
+	if err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(stagingPath)
+	if err != nil {
+		return false, err
+	}
+
+	if err := d.verifyFile(ctx, url, data); err != nil {
+		if !d.AllowUnsigned {
+			return false, fmt.Errorf("refusing hub index: %w", err)
+		}
+
+		logger.Warnf("accepting hub index without a valid signature: %s", err)
+	}
+
+	if current, err := os.ReadFile(destPath); err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+
+	if err := os.Rename(stagingPath, destPath); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // FetchContent downloads the content to the specified path, through a temporary file
 // to avoid partial downloads.
 // If the hash does not match, it will not overwrite and log a warning.
@@ -92,6 +148,15 @@ func (d *Downloader) FetchContent(ctx context.Context, remotePath, destPath, wan
 		return false, "", fmt.Errorf("failed to build request: %w", err)
 	}
 
+	if d.Verifier != nil && d.VerifyItems {
+		downloaded, err = d.fetchSignedContent(ctx, u, destPath, wantHash, logger)
+		if err != nil {
+			return false, "", err
+		}
+
+		return downloaded, u.String(), nil
+	}
+
 	downloaded, err = downloader.
 		New().
 		WithHTTPClient(HubClient).
@@ -114,3 +179,50 @@ func (d *Downloader) FetchContent(ctx context.Context, remotePath, destPath, wan
 
 	return downloaded, u.String(), nil
 }
+
+// fetchSignedContent downloads an item next to the destination file, and only replaces it
+// once the hash and the signature have been verified.
+func (d *Downloader) fetchSignedContent(ctx context.Context, url *url.URL, destPath, wantHash string, logger *logrus.Logger) (bool, error) {
+	stagingPath := destPath + ".unverified"
+
+	defer os.Remove(stagingPath)
+
+	_, err := downloader.
+		New().
+		WithHTTPClient(HubClient).
+		ToFile(stagingPath).
+		WithMode(0o644).
+		WithMakeDirs(true).
+		WithLogger(logger.WithField("url", url)).
+		VerifyHash("sha256", wantHash).
+		Download(ctx, url.String())
+
+	var hasherr downloader.HashMismatchError
+
+	switch {
+	case errors.As(err, &hasherr):
+		logger.Warnf("%s. The index file is outdated, please run 'cscli hub update' and try again", err.Error())
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	data, err := os.ReadFile(stagingPath)
+	if err != nil {
+		return false, err
+	}
+
+	if err := d.verifyFile(ctx, url, data); err != nil {
+		return false, fmt.Errorf("refusing hub item %s: %w", url.Path, err)
+	}
+
+	if current, err := os.ReadFile(destPath); err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+
+	if err := os.Rename(stagingPath, destPath); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
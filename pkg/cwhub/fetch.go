@@ -80,6 +80,15 @@ func (i *Item) FetchContentTo(ctx context.Context, contentProvider ContentProvid
 		return true, fmt.Sprintf("(embedded in %s)", i.hub.local.HubIndexFile), nil
 	}
 
+	if sources, ok := contentProvider.(*Sources); ok {
+		provider, err := sources.contentProvider(i.Source)
+		if err != nil {
+			return false, "", fmt.Errorf("%s: %w", i.FQName(), err)
+		}
+
+		contentProvider = provider
+	}
+
 	downloaded, _, err := contentProvider.FetchContent(ctx, i.RemotePath, destPath, wantHash, i.hub.logger)
 
 	if err == nil && downloaded {
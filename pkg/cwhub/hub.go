@@ -35,6 +35,16 @@ func (h *Hub) GetDataDir() string {
 	return h.local.InstallDataDir
 }
 
+// GetHubDir returns the directory where the items are downloaded.
+// Empty for a hub created without local configuration.
+func (h *Hub) GetHubDir() string {
+	if h.local == nil {
+		return ""
+	}
+
+	return h.local.HubDir
+}
+
 // NewHub returns a new Hub instance with local and (optionally) remote configuration.
 // The hub is not synced automatically. Load() must be called to read the index, sync the local state,
 // and check for unmanaged items.
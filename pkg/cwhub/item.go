@@ -111,6 +111,9 @@ type Item struct {
 	Version    string                 `json:"version,omitempty"  yaml:"version,omitempty"` // the last available version
 	Versions   map[string]ItemVersion `json:"versions,omitempty" yaml:"-"`                 // all the known versions
 
+	// The hub source of the item, when the index is merged from several sources
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
 	// The index contains the dependencies of the "latest" version (collections only)
 	Dependencies
 }
@@ -0,0 +1,108 @@
+package cwhub
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// the detached signature of a file is downloaded from <path>.sig
+const signatureExt = ".sig"
+
+// a base64 ed25519 signature is less than 100 bytes, leave room for comments
+const maxSignatureSize = 4096
+
+var (
+	ErrSignatureMissing = errors.New("signature not found")
+	ErrSignatureInvalid = errors.New("signature verification failed")
+)
+
+// Verifier checks the detached signatures of the files downloaded from the hub,
+// against a set of pinned ed25519 public keys.
+type Verifier struct {
+	keys []ed25519.PublicKey
+}
+
+// NewVerifier parses the public keys, which are base64 encoded.
+func NewVerifier(publicKeys []string) (*Verifier, error) {
+	if len(publicKeys) == 0 {
+		return nil, errors.New("no public key provided")
+	}
+
+	v := &Verifier{}
+
+	for _, encoded := range publicKeys {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hub public key %q: %w", encoded, err)
+		}
+
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid hub public key %q: expected %d bytes, got %d", encoded, ed25519.PublicKeySize, len(raw))
+		}
+
+		v.keys = append(v.keys, ed25519.PublicKey(raw))
+	}
+
+	return v, nil
+}
+
+// Verify returns nil if the signature (base64) of the data matches any of the keys.
+func (v *Verifier) Verify(data []byte, signature []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed signature", ErrSignatureInvalid)
+	}
+
+	for _, key := range v.keys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: no trusted key matches", ErrSignatureInvalid)
+}
+
+// fetchSignature downloads the detached signature of a remote file. It has the same
+// URL, with the .sig extension (and the same query string, if any).
+func fetchSignature(ctx context.Context, fileURL *url.URL) ([]byte, error) {
+	u := *fileURL
+	u.Path += signatureExt
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := HubClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("while downloading %s: %w", u.String(), err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrSignatureMissing, u.String())
+	default:
+		return nil, fmt.Errorf("while downloading %s: bad http code %d", u.String(), resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+}
+
+// verifyFile checks the signature of a downloaded file.
+func (d *Downloader) verifyFile(ctx context.Context, fileURL *url.URL, data []byte) error {
+	sig, err := fetchSignature(ctx, fileURL)
+	if err != nil {
+		return err
+	}
+
+	return d.Verifier.Verify(data, sig)
+}
@@ -0,0 +1,216 @@
+package cwhub
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+func testKey(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(pub), priv
+}
+
+func sign(priv ed25519.PrivateKey, data string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(data))) + "\n"
+}
+
+func TestNewVerifier(t *testing.T) {
+	pub, _ := testKey(t)
+
+	_, err := NewVerifier(nil)
+	cstest.RequireErrorMessage(t, err, "no public key provided")
+
+	_, err = NewVerifier([]string{"not base64"})
+	cstest.RequireErrorContains(t, err, `invalid hub public key "not base64": illegal base64 data`)
+
+	_, err = NewVerifier([]string{"Zm9v"})
+	cstest.RequireErrorMessage(t, err, `invalid hub public key "Zm9v": expected 32 bytes, got 3`)
+
+	v, err := NewVerifier([]string{pub})
+	require.NoError(t, err)
+	assert.Len(t, v.keys, 1)
+}
+
+func TestFetchSignedIndex(t *testing.T) {
+	ctx := t.Context()
+
+	pub, priv := testKey(t)
+	otherPub, otherPriv := testKey(t)
+
+	index := `{"parsers": {}}`
+	files := map[string]string{}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, err := w.Write([]byte(content))
+		assert.NoError(t, err)
+	}))
+	defer mockServer.Close()
+
+	discard := logrus.New()
+	discard.Out = io.Discard
+
+	verifier, err := NewVerifier([]string{otherPub, pub})
+	require.NoError(t, err)
+
+	downloader := &Downloader{
+		Branch:      "main",
+		URLTemplate: mockServer.URL + "/%s/%s",
+		Verifier:    verifier,
+	}
+
+	destPath := filepath.Join(t.TempDir(), ".index.json")
+
+	// unsigned
+
+	files["/main/.index.json"] = index
+
+	downloaded, err := downloader.FetchIndex(ctx, destPath, false, discard)
+	require.ErrorIs(t, err, ErrSignatureMissing)
+	assert.False(t, downloaded)
+	assert.NoFileExists(t, destPath)
+	assert.NoFileExists(t, destPath+".unverified")
+
+	// bad signature
+
+	files["/main/.index.json.sig"] = sign(priv, "something else")
+
+	downloaded, err = downloader.FetchIndex(ctx, destPath, false, discard)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+	assert.False(t, downloaded)
+	assert.NoFileExists(t, destPath)
+
+	files["/main/.index.json.sig"] = "garbage"
+
+	downloaded, err = downloader.FetchIndex(ctx, destPath, false, discard)
+	cstest.RequireErrorMessage(t, err, "refusing hub index: signature verification failed: malformed signature")
+	assert.False(t, downloaded)
+
+	// signed by any trusted key
+
+	files["/main/.index.json.sig"] = sign(otherPriv, index)
+
+	downloaded, err = downloader.FetchIndex(ctx, destPath, false, discard)
+	require.NoError(t, err)
+	assert.True(t, downloaded)
+
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, index, string(content))
+
+	// unchanged
+
+	downloaded, err = downloader.FetchIndex(ctx, destPath, false, discard)
+	require.NoError(t, err)
+	assert.False(t, downloaded)
+
+	// a tampered index does not replace the current one
+
+	files["/main/.index.json"] = `{"parsers": {"evil/parser": {}}}`
+
+	downloaded, err = downloader.FetchIndex(ctx, destPath, false, discard)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+	assert.False(t, downloaded)
+
+	content, err = os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, index, string(content))
+
+	// unless explicitly allowed
+
+	downloader.AllowUnsigned = true
+
+	downloaded, err = downloader.FetchIndex(ctx, destPath, false, discard)
+	require.NoError(t, err)
+	assert.True(t, downloaded)
+}
+
+func TestFetchSignedContent(t *testing.T) {
+	ctx := t.Context()
+
+	pub, priv := testKey(t)
+
+	content := "{}"
+	files := map[string]string{
+		"/main/parsers/s01-parse/pars1.yaml":     content,
+		"/main/parsers/s01-parse/pars2.yaml":     content,
+		"/main/parsers/s01-parse/pars2.yaml.sig": sign(priv, content),
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer mockServer.Close()
+
+	discard := logrus.New()
+	discard.Out = io.Discard
+
+	verifier, err := NewVerifier([]string{pub})
+	require.NoError(t, err)
+
+	downloader := &Downloader{
+		Branch:      "main",
+		URLTemplate: mockServer.URL + "/%s/%s",
+		Verifier:    verifier,
+		VerifyItems: true,
+	}
+
+	hash := "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+	destDir := t.TempDir()
+
+	_, _, err = downloader.FetchContent(ctx, "parsers/s01-parse/pars1.yaml", filepath.Join(destDir, "pars1.yaml"), hash, discard)
+	require.ErrorIs(t, err, ErrSignatureMissing)
+	assert.NoFileExists(t, filepath.Join(destDir, "pars1.yaml"))
+
+	// the installed version is kept if the new one can't be trusted
+
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "pars1.yaml"), []byte("old"), 0o644))
+
+	_, _, err = downloader.FetchContent(ctx, "parsers/s01-parse/pars1.yaml", filepath.Join(destDir, "pars1.yaml"), hash, discard)
+	require.ErrorIs(t, err, ErrSignatureMissing)
+	assert.FileExists(t, filepath.Join(destDir, "pars1.yaml"))
+
+	// or if it doesn't match the index
+
+	downloaded, _, err := downloader.FetchContent(ctx, "parsers/s01-parse/pars2.yaml", filepath.Join(destDir, "pars1.yaml"), "1234", discard)
+	require.NoError(t, err)
+	assert.False(t, downloaded)
+
+	current, err := os.ReadFile(filepath.Join(destDir, "pars1.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(current))
+	assert.NoFileExists(t, filepath.Join(destDir, "pars1.yaml.unverified"))
+
+	downloaded, _, err = downloader.FetchContent(ctx, "parsers/s01-parse/pars2.yaml", filepath.Join(destDir, "pars2.yaml"), hash, discard)
+	require.NoError(t, err)
+	assert.True(t, downloaded)
+	assert.FileExists(t, filepath.Join(destDir, "pars2.yaml"))
+}
@@ -0,0 +1,320 @@
+package cwhub
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/go-cs-lib/downloader"
+)
+
+// Types of hub sources, as set in the configuration.
+const (
+	// the official hub, or a mirror of it, with the URL and branch from the cscli configuration
+	SourceHub = "hub"
+	// any hub served over http(s), with its own URL template
+	SourceHTTP = "http"
+	// a local directory with the same layout as the hub repository
+	SourceDir = "dir"
+	// a git checkout, cloned or pulled by cscli if a repository is configured
+	SourceGit = "git"
+)
+
+// the index of each source is kept here (relative to the hub directory) before being merged
+const sourcesDir = ".sources"
+
+// Provider retrieves the index and the content of the items from a hub source.
+type Provider interface {
+	IndexProvider
+	ContentProvider
+}
+
+// HubSource is a named provider of hub items.
+type HubSource struct {
+	Name     string
+	Provider Provider
+}
+
+// Sources retrieves the items from several hubs, merged in a single namespace.
+// When two sources provide an item with the same type and name, the first one in the list wins.
+// The merged index records the source of each item, so it's downloaded from the right place
+// and gets the same versioning and taint tracking as the official items.
+type Sources struct {
+	list []HubSource
+}
+
+func NewSources(sources ...HubSource) (*Sources, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("no hub source provided")
+	}
+
+	seen := make(map[string]struct{})
+
+	for _, src := range sources {
+		if src.Name == "" {
+			return nil, errors.New("hub source with no name")
+		}
+
+		if _, ok := seen[src.Name]; ok {
+			return nil, fmt.Errorf("hub source '%s' is defined twice", src.Name)
+		}
+
+		seen[src.Name] = struct{}{}
+	}
+
+	return &Sources{list: sources}, nil
+}
+
+// SetAllowUnsigned accepts an index without a valid signature from the http sources.
+func (s *Sources) SetAllowUnsigned(allow bool) {
+	for _, src := range s.list {
+		if d, ok := src.Provider.(*Downloader); ok {
+			d.AllowUnsigned = allow
+		}
+	}
+}
+
+// FetchIndex retrieves the index of each source, and writes the merged index if it changed.
+// With a single source, the index is written as is.
+func (s *Sources) FetchIndex(ctx context.Context, destPath string, withContent bool, logger *logrus.Logger) (bool, error) {
+	if len(s.list) == 1 {
+		return s.list[0].Provider.FetchIndex(ctx, destPath, withContent, logger)
+	}
+
+	dir := filepath.Join(filepath.Dir(destPath), sourcesDir)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, err
+	}
+
+	merged := make(map[string]map[string]map[string]json.RawMessage)
+
+	for _, src := range s.list {
+		indexPath := filepath.Join(dir, src.Name+".json")
+
+		if _, err := src.Provider.FetchIndex(ctx, indexPath, withContent, logger); err != nil {
+			return false, fmt.Errorf("hub source '%s': %w", src.Name, err)
+		}
+
+		if err := mergeIndex(merged, indexPath, src.Name); err != nil {
+			return false, fmt.Errorf("hub source '%s': %w", src.Name, err)
+		}
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return false, err
+	}
+
+	return writeIfChanged(destPath, data, 0o644)
+}
+
+// mergeIndex adds the items of an index that are not already provided by another source.
+func mergeIndex(merged map[string]map[string]map[string]json.RawMessage, indexPath string, sourceName string) error {
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]map[string]map[string]json.RawMessage)
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return fmt.Errorf("failed to parse index: %w", err)
+	}
+
+	source, err := json.Marshal(sourceName)
+	if err != nil {
+		return err
+	}
+
+	for itemType, items := range index {
+		if merged[itemType] == nil {
+			merged[itemType] = make(map[string]map[string]json.RawMessage)
+		}
+
+		for name, item := range items {
+			if _, ok := merged[itemType][name]; ok {
+				continue
+			}
+
+			if item == nil {
+				return fmt.Errorf("%s:%s has no index metadata", itemType, name)
+			}
+
+			item["source"] = source
+			merged[itemType][name] = item
+		}
+	}
+
+	return nil
+}
+
+// FetchContent downloads an item from the first source. Use FetchContentTo
+// to download from the source of the item.
+func (s *Sources) FetchContent(ctx context.Context, remotePath, destPath, wantHash string, logger *logrus.Logger) (bool, string, error) {
+	return s.list[0].Provider.FetchContent(ctx, remotePath, destPath, wantHash, logger)
+}
+
+// contentProvider returns the source of an item. The items of an index downloaded
+// from a single source have no source name.
+func (s *Sources) contentProvider(name string) (ContentProvider, error) {
+	if name == "" {
+		return s.list[0].Provider, nil
+	}
+
+	for _, src := range s.list {
+		if src.Name == name {
+			return src.Provider, nil
+		}
+	}
+
+	return nil, fmt.Errorf("hub source '%s' is not configured anymore. Run 'cscli hub update' and try again", name)
+}
+
+// DirSource provides the index and the items from a local directory, with the same layout as the hub repository.
+type DirSource struct {
+	Dir string
+}
+
+func (d *DirSource) FetchIndex(_ context.Context, destPath string, _ bool, _ *logrus.Logger) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(d.Dir, ".index.json"))
+	if err != nil {
+		return false, err
+	}
+
+	return writeIfChanged(destPath, data, 0o644)
+}
+
+// FetchContent copies an item to destPath. Like with a remote hub, the file is not written
+// if its hash does not match the index.
+func (d *DirSource) FetchContent(_ context.Context, remotePath, destPath, wantHash string, logger *logrus.Logger) (bool, string, error) {
+	srcPath, err := SafePath(d.Dir, remotePath)
+	if err != nil {
+		return false, "", err
+	}
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return false, "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	if gotHash := hex.EncodeToString(sum[:]); gotHash != wantHash {
+		err := downloader.HashMismatchError{Expected: wantHash, Got: gotHash}
+		logger.Warnf("%s. The index file is outdated, please run 'cscli hub update' and try again", err.Error())
+
+		return false, srcPath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return false, "", err
+	}
+
+	downloaded, err := writeIfChanged(destPath, data, 0o644)
+	if err != nil {
+		return false, "", err
+	}
+
+	return downloaded, srcPath, nil
+}
+
+// GitSource provides the items from a git checkout. If Repo is set, the checkout is created or
+// updated when the index is fetched; otherwise, it's managed outside of cscli.
+type GitSource struct {
+	Dir    string
+	Repo   string
+	Branch string
+}
+
+func (g *GitSource) git(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func (g *GitSource) sync(ctx context.Context) error {
+	if g.Repo == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(g.Dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if g.Branch != "" {
+			args = append(args, "--branch", g.Branch)
+		}
+
+		// "--": the repository can't be taken for an option
+		return g.git(ctx, append(args, "--", g.Repo, g.Dir)...)
+	}
+
+	ref := g.Branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	if err := g.git(ctx, "-C", g.Dir, "fetch", "--quiet", "--depth", "1", "--", g.Repo, ref); err != nil {
+		return err
+	}
+
+	// the checkout is a copy of the repository, local changes are discarded
+	return g.git(ctx, "-C", g.Dir, "reset", "--quiet", "--hard", "FETCH_HEAD")
+}
+
+func (g *GitSource) FetchIndex(ctx context.Context, destPath string, withContent bool, logger *logrus.Logger) (bool, error) {
+	if err := g.sync(ctx); err != nil {
+		return false, err
+	}
+
+	return (&DirSource{Dir: g.Dir}).FetchIndex(ctx, destPath, withContent, logger)
+}
+
+func (g *GitSource) FetchContent(ctx context.Context, remotePath, destPath, wantHash string, logger *logrus.Logger) (bool, string, error) {
+	return (&DirSource{Dir: g.Dir}).FetchContent(ctx, remotePath, destPath, wantHash, logger)
+}
+
+// writeIfChanged replaces a file through a temporary file, unless it already has the same content.
+func writeIfChanged(path string, data []byte, mode os.FileMode) (bool, error) {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return false, err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return false, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,196 @@
+package cwhub
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSourceDir writes a hub with the given parsers (name -> content) to a directory.
+func testSourceDir(t *testing.T, dir string, parsers map[string]string) {
+	t.Helper()
+
+	index := map[string]map[string]any{PARSERS: {}}
+
+	for name, content := range parsers {
+		remotePath := "parsers/s01-parse/" + name + ".yaml"
+		digest := sha256.Sum256([]byte(content))
+
+		index[PARSERS][name] = map[string]any{
+			"path":     remotePath,
+			"stage":    "s01-parse",
+			"version":  "0.1",
+			"versions": map[string]any{"0.1": map[string]any{"digest": hex.EncodeToString(digest[:])}},
+		}
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, remotePath)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, remotePath), []byte(content), 0o644))
+	}
+
+	data, err := json.Marshal(index)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".index.json"), data, 0o644))
+}
+
+func TestSourcesMerge(t *testing.T) {
+	ctx := t.Context()
+
+	privateDir := t.TempDir()
+	publicDir := t.TempDir()
+
+	testSourceDir(t, privateDir, map[string]string{
+		"acme/internal": "name: acme/internal\n",
+		"author/shared": "name: author/shared\n# patched\n",
+	})
+
+	testSourceDir(t, publicDir, map[string]string{
+		"author/shared": "name: author/shared\n",
+		"author/public": "name: author/public\n",
+	})
+
+	sources, err := NewSources(
+		HubSource{Name: "private", Provider: &DirSource{Dir: privateDir}},
+		HubSource{Name: "public", Provider: &DirSource{Dir: publicDir}},
+	)
+	require.NoError(t, err)
+
+	local := testHubCfg(t)
+
+	hub, err := NewHub(local, nil)
+	require.NoError(t, err)
+
+	updated, err := hub.Update(ctx, sources, false)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	require.NoError(t, hub.Load())
+
+	// the first source wins
+	for name, source := range map[string]string{
+		"acme/internal": "private",
+		"author/shared": "private",
+		"author/public": "public",
+	} {
+		item := hub.GetItem(PARSERS, name)
+		require.NotNil(t, item, name)
+		assert.Equal(t, source, item.Source, name)
+
+		destPath, err := item.PathForDownload()
+		require.NoError(t, err)
+
+		downloaded, _, err := item.FetchContentTo(ctx, sources, destPath)
+		require.NoError(t, err)
+		assert.True(t, downloaded)
+	}
+
+	assert.Equal(t, "name: author/shared\n# patched\n",
+		fileToStringX(t, filepath.Join(local.HubDir, "parsers/s01-parse/author/shared.yaml")))
+	assert.Equal(t, "name: author/public\n",
+		fileToStringX(t, filepath.Join(local.HubDir, "parsers/s01-parse/author/public.yaml")))
+
+	// nothing changed
+	hub, err = NewHub(local, nil)
+	require.NoError(t, err)
+
+	updated, err = hub.Update(ctx, sources, false)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	// the private source has been removed from the configuration
+	sources, err = NewSources(HubSource{Name: "public", Provider: &DirSource{Dir: publicDir}})
+	require.NoError(t, err)
+
+	require.NoError(t, hub.Load())
+
+	item := hub.GetItem(PARSERS, "acme/internal")
+	destPath, err := item.PathForDownload()
+	require.NoError(t, err)
+
+	_, _, err = item.FetchContentTo(ctx, sources, destPath)
+	require.EqualError(t, err, "parsers:acme/internal: hub source 'private' is not configured anymore. Run 'cscli hub update' and try again")
+}
+
+func TestNewSources(t *testing.T) {
+	_, err := NewSources()
+	require.EqualError(t, err, "no hub source provided")
+
+	_, err = NewSources(HubSource{Name: "a", Provider: &DirSource{}}, HubSource{Name: "a", Provider: &DirSource{}})
+	require.EqualError(t, err, "hub source 'a' is defined twice")
+}
+
+func TestDirSourceOutdated(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	testSourceDir(t, dir, map[string]string{"author/parser": "name: author/parser\n"})
+
+	discard := logrus.New()
+	discard.Out = io.Discard
+
+	source := &DirSource{Dir: dir}
+	destPath := filepath.Join(t.TempDir(), "parser.yaml")
+
+	// the file has changed since the index was read
+	downloaded, _, err := source.FetchContent(ctx, "parsers/s01-parse/author/parser.yaml", destPath, "0123", discard)
+	require.NoError(t, err)
+	assert.False(t, downloaded)
+	assert.NoFileExists(t, destPath)
+
+	// no way out of the source directory
+	_, _, err = source.FetchContent(ctx, "../../etc/passwd", destPath, "0123", discard)
+	require.Error(t, err)
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	ctx := t.Context()
+	repoDir := t.TempDir()
+
+	git := func(args ...string) {
+		t.Helper()
+
+		cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoDir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	git("init", "--quiet", "--initial-branch", "main")
+	testSourceDir(t, repoDir, map[string]string{"author/parser": "name: author/parser\n"})
+	git("add", ".")
+	git("commit", "--quiet", "-m", "first")
+
+	discard := logrus.New()
+	discard.Out = io.Discard
+
+	source := &GitSource{Dir: filepath.Join(t.TempDir(), "checkout"), Repo: repoDir, Branch: "main"}
+	indexPath := filepath.Join(t.TempDir(), ".index.json")
+
+	updated, err := source.FetchIndex(ctx, indexPath, false, discard)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	updated, err = source.FetchIndex(ctx, indexPath, false, discard)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	testSourceDir(t, repoDir, map[string]string{"author/parser": "name: author/parser\n", "author/other": "name: author/other\n"})
+	git("add", ".")
+	git("commit", "--quiet", "-m", "second")
+
+	updated, err = source.FetchIndex(ctx, indexPath, false, discard)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.Contains(t, fileToStringX(t, indexPath), "author/other")
+}
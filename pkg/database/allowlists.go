@@ -30,7 +30,7 @@ func (c *Client) CreateAllowList(ctx context.Context, name string, description s
 		Save(ctx)
 	if err != nil {
 		if sqlgraph.IsUniqueConstraintError(err) {
-			return nil, fmt.Errorf("allowlist '%s' already exists", name)
+			return nil, fmt.Errorf("allowlist '%s' %w", name, AllowlistExists)
 		}
 
 		return nil, fmt.Errorf("unable to create allowlist: %w", err)
@@ -56,7 +56,7 @@ func (c *Client) DeleteAllowList(ctx context.Context, name string, fromConsole b
 	}
 
 	if nbDeleted == 0 {
-		return fmt.Errorf("allowlist %s not found", name)
+		return fmt.Errorf("allowlist %s %w", name, AllowlistNotFound)
 	}
 
 	return nil
@@ -79,7 +79,7 @@ func (c *Client) DeleteAllowListByID(ctx context.Context, name string, allowlist
 	}
 
 	if nbDeleted == 0 {
-		return fmt.Errorf("allowlist %s not found", name)
+		return fmt.Errorf("allowlist %s %w", name, AllowlistNotFound)
 	}
 
 	return nil
@@ -108,7 +108,7 @@ func (c *Client) GetAllowList(ctx context.Context, name string, withContent bool
 	result, err := q.First(ctx)
 	if err != nil {
 		if ent.IsNotFound(err) {
-			return nil, fmt.Errorf("allowlist '%s' not found", name)
+			return nil, fmt.Errorf("allowlist '%s' %w", name, AllowlistNotFound)
 		}
 
 		return nil, err
@@ -215,6 +215,17 @@ func (c *Client) UpdateAllowlistMeta(ctx context.Context, allowlistID string, na
 	return nil
 }
 
+func (c *Client) UpdateAllowlistDescription(ctx context.Context, list *ent.AllowList, description string) error {
+	c.Log.Debugf("updating allowlist %s description", list.Name)
+
+	err := c.Ent.AllowList.UpdateOneID(list.ID).SetDescription(description).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to update allowlist: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Client) ReplaceAllowlist(ctx context.Context, list *ent.AllowList, items []*models.AllowlistItem, fromConsole bool) (int, error) {
 	c.Log.Debugf("replacing values in allowlist %s", list.Name)
 	c.Log.Tracef("items: %+v", items)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/bouncer"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
 )
 
@@ -112,6 +113,8 @@ func (c *Client) DeleteBouncer(ctx context.Context, name string) error {
 		return &BouncerNotFoundError{BouncerName: name}
 	}
 
+	metrics.DeleteBouncerUsage(name)
+
 	return nil
 }
 
@@ -126,6 +129,10 @@ func (c *Client) BulkDeleteBouncers(ctx context.Context, bouncers []*ent.Bouncer
 		return nbDeleted, fmt.Errorf("unable to delete bouncers: %w", err)
 	}
 
+	for _, b := range bouncers {
+		metrics.DeleteBouncerUsage(b.Name)
+	}
+
 	return nbDeleted, nil
 }
 
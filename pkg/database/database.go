@@ -24,6 +24,12 @@ type Client struct {
 	// imports hold the read lock; the flush job takes it exclusively.
 	flushGuard       sync.RWMutex
 	decisionBulkSize int
+	decisionFeed     *DecisionFeed
+}
+
+// DecisionFeed returns the feed of the decision changes made through this client.
+func (c *Client) DecisionFeed() *DecisionFeed {
+	return c.decisionFeed
 }
 
 // PauseFlush pauses the alert flush job until the returned function is called.
@@ -117,12 +123,16 @@ func NewClient(ctx context.Context, config *csconfig.DatabaseCfg, logger *log.En
 		return nil, fmt.Errorf("failed creating schema resources: %w", err)
 	}
 
+	feed := newDecisionFeed()
+	client.Decision.Use(feed.hook)
+
 	return &Client{
 		Ent:              client,
 		Log:              logger,
 		Type:             config.Type,
 		WalMode:          config.UseWal,
 		decisionBulkSize: config.DecisionBulkSize,
+		decisionFeed:     feed,
 	}, nil
 }
 
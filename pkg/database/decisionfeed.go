@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/hook"
+)
+
+// DecisionFeed signals the changes made to the decisions table by this process,
+// so that the decision stream can push them to the bouncers instead of waiting for a poll.
+//
+// The feed carries no data: the subscribers are woken up and query the database
+// with their own filters. Changes made by other processes (cscli, another LAPI instance)
+// or decisions that simply expire are not signaled.
+type DecisionFeed struct {
+	mu         sync.Mutex
+	changed    chan struct{}
+	lastChange time.Time
+	closed     chan struct{}
+	closeOnce  sync.Once
+	pendingTx  sync.Map // *ent.Tx with a registered commit hook
+}
+
+func newDecisionFeed() *DecisionFeed {
+	return &DecisionFeed{
+		changed:    make(chan struct{}),
+		lastChange: time.Now().UTC(),
+		closed:     make(chan struct{}),
+	}
+}
+
+// Changed returns a channel that is closed on the next change, and the time of the last one.
+// Subscribers must call it before querying the database, to not miss a change that happens
+// while they are reading.
+func (f *DecisionFeed) Changed() (<-chan struct{}, time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.changed, f.lastChange
+}
+
+// Closed returns a channel that is closed when the subscribers must stop (on server shutdown).
+func (f *DecisionFeed) Closed() <-chan struct{} {
+	return f.closed
+}
+
+// Close wakes up and stops all the subscribers.
+func (f *DecisionFeed) Close() {
+	f.closeOnce.Do(func() {
+		close(f.closed)
+	})
+}
+
+func (f *DecisionFeed) publish() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lastChange = time.Now().UTC()
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// hook is an ent hook on the decision mutations. Changes done in a transaction are
+// published when it's committed, so that the subscribers can see them.
+func (f *DecisionFeed) hook(next ent.Mutator) ent.Mutator {
+	return hook.DecisionFunc(func(ctx context.Context, m *ent.DecisionMutation) (ent.Value, error) {
+		v, err := next.Mutate(ctx, m)
+		if err != nil {
+			return v, err
+		}
+
+		tx, err := m.Tx()
+		if err != nil {
+			// not in a transaction
+			f.publish()
+			return v, nil
+		}
+
+		if _, loaded := f.pendingTx.LoadOrStore(tx, struct{}{}); loaded {
+			return v, nil
+		}
+
+		tx.OnCommit(func(next ent.Committer) ent.Committer {
+			return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+				f.pendingTx.Delete(tx)
+
+				err := next.Commit(ctx, tx)
+				if err == nil {
+					f.publish()
+				}
+
+				return err
+			})
+		})
+
+		tx.OnRollback(func(next ent.Rollbacker) ent.Rollbacker {
+			return ent.RollbackFunc(func(ctx context.Context, tx *ent.Tx) error {
+				f.pendingTx.Delete(tx)
+				return next.Rollback(ctx, tx)
+			})
+		})
+
+		return v, nil
+	})
+}
@@ -18,4 +18,6 @@ var (
 	ParseType         = errors.New("unable to parse type")
 	InvalidIPOrRange  = errors.New("invalid ip address / range")
 	InvalidFilter     = errors.New("invalid filter")
+	AllowlistNotFound = errors.New("not found")
+	AllowlistExists   = errors.New("already exists")
 )
@@ -190,11 +190,22 @@ func (c *Client) flushBouncers(ctx context.Context, authType string, duration *t
 		return
 	}
 
-	count, err := c.Ent.Bouncer.Delete().Where(
+	expired, err := c.Ent.Bouncer.Query().Where(
 		bouncer.LastPullLTE(time.Now().UTC().Add(-*duration)),
 	).Where(
 		bouncer.AuthTypeEQ(authType),
-	).Exec(ctx)
+	).All(ctx)
+	if err != nil {
+		c.Log.Errorf("while listing expired bouncers (%s): %s", authType, err)
+		return
+	}
+
+	if len(expired) == 0 {
+		return
+	}
+
+	// also removes their usage metrics
+	count, err := c.BulkDeleteBouncers(ctx, expired)
 	if err != nil {
 		c.Log.Errorf("while auto-deleting expired bouncers (%s): %s", authType, err)
 		return
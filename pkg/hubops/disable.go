@@ -59,6 +59,10 @@ func (c *DisableCommand) Prepare(plan *ActionPlan) (bool, error) {
 		return false, nil
 	}
 
+	if _, ok := plan.keep[i]; ok {
+		return false, nil
+	}
+
 	if i.State.Tainted && !c.Force {
 		return false, fmt.Errorf("%s is tainted, use '--force' to remove", i.Name)
 	}
@@ -90,6 +94,10 @@ func (c *DisableCommand) Run(_ context.Context, plan *ActionPlan) error {
 
 	fmt.Fprintln(os.Stdout, "disabling " + colorizeItemName(i.FQName()))
 
+	if err := plan.saveFile(i.State.LocalPath); err != nil {
+		return err
+	}
+
 	if err := RemoveInstallLink(i); err != nil {
 		return fmt.Errorf("while disabling %s: %w", i.FQName(), err)
 	}
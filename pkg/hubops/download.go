@@ -171,6 +171,10 @@ func (c *DownloadCommand) Run(ctx context.Context, plan *ActionPlan) error {
 		return err
 	}
 
+	if err := plan.saveFile(finalPath); err != nil {
+		return err
+	}
+
 	downloaded, _, err := i.FetchContentTo(ctx, c.contentProvider, finalPath)
 	if err != nil {
 		return fmt.Errorf("%s: %w", i.FQName(), err)
@@ -87,6 +87,15 @@ func (c *EnableCommand) Run(_ context.Context, plan *ActionPlan) error {
 		return fmt.Errorf("can't enable %s: not downloaded", i.FQName())
 	}
 
+	dest, err := i.PathForInstall()
+	if err != nil {
+		return err
+	}
+
+	if err := plan.saveFile(dest); err != nil {
+		return err
+	}
+
 	if err := CreateInstallLink(i); err != nil {
 		return fmt.Errorf("while enabling %s: %w", i.FQName(), err)
 	}
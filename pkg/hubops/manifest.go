@@ -0,0 +1,317 @@
+package hubops
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+)
+
+// ManifestItem is an item that must be installed, as declared in a hub manifest.
+type ManifestItem struct {
+	Name string `json:"name" yaml:"name"`
+	// Only the latest version of an item can be downloaded, so a pinned version
+	// prevents the upgrade of an installed item, and can't be used to downgrade.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// The item is a local file, or a hub item with local changes: it must be installed
+	// but is not managed by cscli.
+	Local bool `json:"local,omitempty" yaml:"local,omitempty"`
+}
+
+// Manifest is the desired state of the hub: the items that must be installed, per type.
+// The dependencies of the collections are installed too, everything else is removed.
+type Manifest map[string][]ManifestItem
+
+// Types of changes between the current state of the hub and a manifest.
+const (
+	ChangeInstall = "install"
+	ChangeUpgrade = "upgrade"
+	ChangeRemove  = "remove"
+	// a hub item that has been modified locally. It is overwritten or removed only with --force
+	ChangeTaint = "taint"
+)
+
+// Change describes what happens to an item when a manifest is applied.
+type Change struct {
+	Op   string      `json:"op"`
+	Item *cwhub.Item `json:"-"`
+	// installed version, if any
+	From string `json:"from,omitempty"`
+	// version after the change, if any
+	To string `json:"to,omitempty"`
+}
+
+// LoadManifest reads a manifest file and checks its structure. Items are checked
+// against the hub when the manifest is applied.
+func LoadManifest(path string) (Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseManifest(data)
+}
+
+func ParseManifest(data []byte) (Manifest, error) {
+	m := Manifest{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid hub manifest: %w", err)
+	}
+
+	for itemType, items := range m {
+		if !slices.Contains(cwhub.ItemTypes, itemType) {
+			return nil, fmt.Errorf("invalid hub manifest: unknown item type '%s'", itemType)
+		}
+
+		seen := make(map[string]struct{})
+
+		for _, item := range items {
+			if item.Name == "" {
+				return nil, fmt.Errorf("invalid hub manifest: %s: missing item name", itemType)
+			}
+
+			if _, ok := seen[item.Name]; ok {
+				return nil, fmt.Errorf("invalid hub manifest: %s:%s is declared twice", itemType, item.Name)
+			}
+
+			seen[item.Name] = struct{}{}
+		}
+	}
+
+	return m, nil
+}
+
+// ExportManifest returns the manifest of the items currently installed. Sub-items are not
+// listed unless they have been modified, since they are installed with their collections.
+// With pinVersions, the manifest keeps the current version of the hub items.
+func ExportManifest(hub *cwhub.Hub, pinVersions bool) Manifest {
+	m := Manifest{}
+
+	for _, itemType := range cwhub.ItemTypes {
+		for _, item := range hub.GetInstalledByType(itemType, true) {
+			local := item.State.IsLocal() || item.State.Tainted
+
+			if len(item.InstalledParents()) > 0 && !local {
+				continue
+			}
+
+			entry := ManifestItem{Name: item.Name, Local: local}
+
+			if pinVersions && !item.State.IsLocal() {
+				entry.Version = item.State.LocalVersion
+			}
+
+			m[itemType] = append(m[itemType], entry)
+		}
+	}
+
+	return m
+}
+
+// manifestPlanner keeps track of the items required by a manifest.
+type manifestPlanner struct {
+	hub     *cwhub.Hub
+	plan    *ActionPlan
+	keep    map[*cwhub.Item]struct{}
+	changes map[*cwhub.Item]Change
+}
+
+// require marks an item and its dependencies (current and latest) as wanted.
+func (mp *manifestPlanner) require(item *cwhub.Item) {
+	if _, ok := mp.keep[item]; ok {
+		return
+	}
+
+	mp.keep[item] = struct{}{}
+
+	for sub := range item.CurrentDependencies().SubItems(mp.hub) {
+		mp.require(sub)
+	}
+
+	for sub := range item.LatestDependencies().SubItems(mp.hub) {
+		mp.require(sub)
+	}
+}
+
+func (mp *manifestPlanner) addChange(op string, item *cwhub.Item, from string, to string) {
+	if _, ok := mp.changes[item]; ok {
+		return
+	}
+
+	mp.changes[item] = Change{Op: op, Item: item, From: from, To: to}
+}
+
+// addItem adds the commands to install or upgrade a manifest item.
+func (mp *manifestPlanner) addItem(entry ManifestItem, item *cwhub.Item, contentProvider cwhub.ContentProvider, force bool) error {
+	installed := item.State.IsInstalled()
+
+	if item.State.IsLocal() || (entry.Local && installed) {
+		// not ours to change
+		return nil
+	}
+
+	if entry.Version != "" {
+		if installed && item.State.LocalVersion == entry.Version && !item.State.Tainted {
+			return nil
+		}
+
+		if entry.Version != item.Version {
+			return fmt.Errorf("%s: version %s is not available, the hub only provides the latest version (%s)",
+				item.FQName(), entry.Version, item.Version)
+		}
+	}
+
+	if item.State.Tainted && !force {
+		// reported by diff(), the download command would be skipped anyway
+		return nil
+	}
+
+	if err := mp.plan.AddCommand(NewDownloadCommand(item, contentProvider, force)); err != nil {
+		return err
+	}
+
+	return mp.plan.AddCommand(NewEnableCommand(item, force))
+}
+
+// removeItems adds the commands to remove the installed items that are not required by the manifest.
+func (mp *manifestPlanner) removeItems(force bool) error {
+	var toRemove []*cwhub.Item
+
+	for _, itemType := range cwhub.ItemTypes {
+		for _, item := range mp.hub.GetInstalledByType(itemType, true) {
+			if _, ok := mp.keep[item]; ok {
+				continue
+			}
+
+			switch {
+			case item.State.IsLocal():
+				log.Warnf("%s is a local item and is not in the manifest, please delete manually", item.FQName())
+			case item.State.Tainted && !force:
+				// left in place, even if its collection is removed
+				mp.addChange(ChangeTaint, item, item.State.LocalVersion, "")
+				mp.keep[item] = struct{}{}
+			default:
+				toRemove = append(toRemove, item)
+			}
+		}
+	}
+
+	for _, item := range toRemove {
+		if err := mp.plan.AddCommand(NewDisableCommand(item, force)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diff returns the changes from the plan, and the tainted items that are left untouched.
+func (mp *manifestPlanner) diff(localEntries map[*cwhub.Item]struct{}) []Change {
+	for _, c := range mp.plan.commands {
+		switch c := c.(type) {
+		case *DownloadCommand:
+			i := c.Item
+
+			switch {
+			case !i.State.IsDownloaded():
+				mp.addChange(ChangeInstall, i, "", i.Version)
+			case i.State.Tainted:
+				mp.addChange(ChangeTaint, i, i.State.LocalVersion, i.Version)
+			case !i.State.IsInstalled():
+				mp.addChange(ChangeInstall, i, "", i.Version)
+			default:
+				mp.addChange(ChangeUpgrade, i, i.State.LocalVersion, i.Version)
+			}
+		case *EnableCommand:
+			mp.addChange(ChangeInstall, c.Item, "", c.Item.State.LocalVersion)
+		case *DisableCommand:
+			mp.addChange(ChangeRemove, c.Item, c.Item.State.LocalVersion, "")
+		}
+	}
+
+	for item := range mp.keep {
+		if _, ok := localEntries[item]; ok {
+			continue
+		}
+
+		if item.State.Tainted && item.State.IsInstalled() {
+			mp.addChange(ChangeTaint, item, item.State.LocalVersion, item.Version)
+		}
+	}
+
+	ret := make([]Change, 0, len(mp.changes))
+
+	for _, c := range mp.changes {
+		ret = append(ret, c)
+	}
+
+	slices.SortFunc(ret, func(a, b Change) int {
+		return cmp.Or(
+			cmp.Compare(a.Op, b.Op),
+			cmp.Compare(a.Item.Type, b.Item.Type),
+			cmp.Compare(a.Item.Name, b.Item.Name),
+		)
+	})
+
+	return ret
+}
+
+// PlanManifest returns the action plan to bring the hub to the state described by the manifest,
+// and a summary of the changes. Tainted items are overwritten or removed only with force.
+func PlanManifest(hub *cwhub.Hub, m Manifest, contentProvider cwhub.ContentProvider, force bool) (*ActionPlan, []Change, error) {
+	mp := &manifestPlanner{
+		hub:     hub,
+		plan:    NewActionPlan(hub),
+		keep:    make(map[*cwhub.Item]struct{}),
+		changes: make(map[*cwhub.Item]Change),
+	}
+
+	// the items that are removed with their collections must not be disabled if they are required
+	mp.plan.keep = mp.keep
+
+	localEntries := make(map[*cwhub.Item]struct{})
+
+	// ranging over ItemTypes to have a predictable order
+	for _, itemType := range cwhub.ItemTypes {
+		for _, entry := range m[itemType] {
+			item := hub.GetItem(itemType, entry.Name)
+			if item == nil {
+				return nil, nil, fmt.Errorf("can't find '%s' in %s", entry.Name, itemType)
+			}
+
+			if entry.Local {
+				localEntries[item] = struct{}{}
+			}
+
+			mp.require(item)
+		}
+	}
+
+	for _, itemType := range cwhub.ItemTypes {
+		for _, entry := range m[itemType] {
+			item := hub.GetItem(itemType, entry.Name)
+
+			if err := mp.addItem(entry, item, contentProvider, force); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if err := mp.removeItems(force); err != nil {
+		return nil, nil, err
+	}
+
+	return mp.plan, mp.diff(localEntries), nil
+}
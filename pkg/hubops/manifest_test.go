@@ -0,0 +1,232 @@
+package hubops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    Manifest
+		expectedErr string
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: Manifest{},
+		},
+		{
+			name: "valid",
+			input: `
+collections:
+  - name: crowdsecurity/linux
+  - name: crowdsecurity/nginx
+    version: "0.2"
+parsers:
+  - name: me/custom-logs
+    local: true
+`,
+			expected: Manifest{
+				"collections": {
+					{Name: "crowdsecurity/linux"},
+					{Name: "crowdsecurity/nginx", Version: "0.2"},
+				},
+				"parsers": {
+					{Name: "me/custom-logs", Local: true},
+				},
+			},
+		},
+		{
+			name:        "unknown type",
+			input:       "widgets:\n  - name: foo/bar\n",
+			expectedErr: "invalid hub manifest: unknown item type 'widgets'",
+		},
+		{
+			name:        "unknown field",
+			input:       "parsers:\n  - name: foo/bar\n    pinned: true\n",
+			expectedErr: "invalid hub manifest: yaml: unmarshal errors:\n  line 3: field pinned not found in type hubops.ManifestItem",
+		},
+		{
+			name:        "missing name",
+			input:       "parsers:\n  - version: \"1.0\"\n",
+			expectedErr: "invalid hub manifest: parsers: missing item name",
+		},
+		{
+			name:        "duplicate",
+			input:       "parsers:\n  - name: foo/bar\n  - name: foo/bar\n",
+			expectedErr: "invalid hub manifest: parsers:foo/bar is declared twice",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := ParseManifest([]byte(tc.input))
+			cstest.RequireErrorMessage(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, m)
+		})
+	}
+}
+
+// testHub creates a hub with a collection (one parser, one scenario) and a standalone scenario.
+// The content is embedded in the index, nothing is downloaded.
+func testHub(t *testing.T, dir string) *cwhub.Hub {
+	t.Helper()
+
+	hub, err := cwhub.NewHub(testHubConfig(t, dir), nil)
+	require.NoError(t, err)
+	require.NoError(t, hub.Load())
+
+	return hub
+}
+
+// testHubConfig creates a hub index with a few items.
+func testHubConfig(t *testing.T, dir string) *csconfig.LocalHubCfg {
+	t.Helper()
+
+	local := &csconfig.LocalHubCfg{
+		HubDir:         filepath.Join(dir, "hub"),
+		HubIndexFile:   filepath.Join(dir, "hub", ".index.json"),
+		InstallDir:     filepath.Join(dir, "config"),
+		InstallDataDir: filepath.Join(dir, "data"),
+	}
+
+	for _, d := range []string{local.HubDir, local.InstallDir, local.InstallDataDir} {
+		require.NoError(t, os.MkdirAll(d, 0o755))
+	}
+
+	item := func(path string, content string, extra map[string]any) map[string]any {
+		digest := sha256.Sum256([]byte(content))
+
+		ret := map[string]any{
+			"path":     path,
+			"version":  "0.1",
+			"versions": map[string]any{"0.1": map[string]any{"digest": hex.EncodeToString(digest[:])}},
+			"content":  content,
+		}
+
+		maps.Copy(ret, extra)
+
+		return ret
+	}
+
+	index := map[string]any{
+		"collections": map[string]any{
+			"author/coll": item("collections/author/coll.yaml", "parsers:\n  - author/pars\nscenarios:\n  - author/scen1\n",
+				map[string]any{"parsers": []string{"author/pars"}, "scenarios": []string{"author/scen1"}}),
+		},
+		"parsers": map[string]any{
+			"author/pars": item("parsers/s01-parse/author/pars.yaml", "name: author/pars\n", map[string]any{"stage": "s01-parse"}),
+		},
+		"scenarios": map[string]any{
+			"author/scen1": item("scenarios/author/scen1.yaml", "name: author/scen1\n", nil),
+			"author/scen2": item("scenarios/author/scen2.yaml", "name: author/scen2\n", nil),
+		},
+	}
+
+	data, err := json.Marshal(index)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(local.HubIndexFile, data, 0o644))
+
+	return local
+}
+
+func applyManifest(t *testing.T, hub *cwhub.Hub, manifest string) []string {
+	t.Helper()
+
+	m, err := ParseManifest([]byte(manifest))
+	require.NoError(t, err)
+
+	plan, changes, err := PlanManifest(hub, m, nil, false)
+	require.NoError(t, err)
+
+	require.NoError(t, plan.Execute(t.Context(), false, false, false, false))
+
+	ret := []string{}
+	for _, c := range changes {
+		ret = append(ret, c.Op+" "+c.Item.FQName())
+	}
+
+	return ret
+}
+
+func TestPlanManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	hub := testHub(t, dir)
+
+	changes := applyManifest(t, hub, "collections:\n  - name: author/coll\n")
+	assert.Equal(t, []string{
+		"install collections:author/coll",
+		"install parsers:author/pars",
+		"install scenarios:author/scen1",
+	}, changes)
+
+	hub = testHub(t, dir)
+	assert.Equal(t, Manifest{"collections": {{Name: "author/coll"}}}, ExportManifest(hub, false))
+	assert.Equal(t, Manifest{"collections": {{Name: "author/coll", Version: "0.1"}}}, ExportManifest(hub, true))
+
+	// nothing to do
+	changes = applyManifest(t, hub, "collections:\n  - name: author/coll\n")
+	assert.Empty(t, changes)
+
+	// the scenario is kept, even if its collection is removed
+	changes = applyManifest(t, hub, "scenarios:\n  - name: author/scen1\n  - name: author/scen2\n")
+	assert.Equal(t, []string{
+		"install scenarios:author/scen2",
+		"remove collections:author/coll",
+		"remove parsers:author/pars",
+	}, changes)
+
+	hub = testHub(t, dir)
+	assert.Equal(t, Manifest{"scenarios": {{Name: "author/scen1"}, {Name: "author/scen2"}}}, ExportManifest(hub, false))
+
+	// local changes are not overwritten without force
+	scen1 := hub.GetItem(cwhub.SCENARIOS, "author/scen1")
+	require.NoError(t, os.WriteFile(scen1.State.DownloadPath, []byte("name: author/scen1\n# changed\n"), 0o644))
+
+	hub = testHub(t, dir)
+	assert.Equal(t, Manifest{"scenarios": {{Name: "author/scen1", Local: true}, {Name: "author/scen2"}}}, ExportManifest(hub, false))
+
+	changes = applyManifest(t, hub, "scenarios:\n  - name: author/scen2\n")
+	assert.Equal(t, []string{"taint scenarios:author/scen1"}, changes)
+
+	hub = testHub(t, dir)
+	assert.True(t, hub.GetItem(cwhub.SCENARIOS, "author/scen1").State.IsInstalled())
+
+	// pinned to the installed version
+	m, err := ParseManifest([]byte("scenarios:\n  - name: author/scen1\n    local: true\n  - name: author/scen2\n    version: \"0.1\"\n"))
+	require.NoError(t, err)
+
+	_, planned, err := PlanManifest(hub, m, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, planned)
+
+	// only the latest version can be downloaded
+
+	m, err = ParseManifest([]byte("parsers:\n  - name: author/pars\n    version: \"0.2\"\n"))
+	require.NoError(t, err)
+
+	_, _, err = PlanManifest(hub, m, nil, false)
+	cstest.RequireErrorMessage(t, err, "parsers:author/pars: version 0.2 is not available, the hub only provides the latest version (0.1)")
+}
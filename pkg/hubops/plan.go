@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/crowdsecurity/go-cs-lib/slicetools"
 
@@ -69,8 +71,19 @@ type ActionPlan struct {
 	// A reference to the Hub instance, required for dependency lookup.
 	hub *cwhub.Hub
 
+	// Items that must not be disabled as a side effect of removing a collection
+	// (they are required by a manifest).
+	keep map[*cwhub.Item]struct{}
+
 	// Indicates whether a reload of the CrowdSec service is required after executing the action plan.
 	ReloadNeeded bool
+
+	// Optional, called after the commands have been executed to check the resulting configuration.
+	// If it fails, the changes are rolled back.
+	Validate func(ctx context.Context) error
+
+	// The files modified during the execution, to roll back on failure.
+	snapshot *Snapshot
 }
 
 func NewActionPlan(hub *cwhub.Hub) *ActionPlan {
@@ -242,11 +255,81 @@ func (p *ActionPlan) Execute(ctx context.Context, interactive bool, dryRun bool,
 		}
 	}
 
+	return p.run(ctx)
+}
+
+// run executes the commands, and restores the previous state of the hub if one of them fails,
+// or if the resulting configuration is not valid.
+func (p *ActionPlan) run(ctx context.Context) error {
+	hubDir := p.hub.GetHubDir()
+
+	if hubDir == "" {
+		// don't write the snapshot in the current directory
+		log.Warning("the hub directory is not set, the changes can't be rolled back")
+	} else {
+		snapshot, err := newSnapshot(filepath.Join(hubDir, pendingSnapshotDir))
+		if err != nil {
+			return err
+		}
+
+		p.snapshot = snapshot
+
+		defer func() {
+			p.snapshot = nil
+		}()
+	}
+
 	for _, c := range p.commands {
 		if err := c.Run(ctx, p); err != nil {
-			return err
+			return p.rollback(err)
 		}
 	}
 
-	return nil
+	if p.Validate != nil {
+		fmt.Fprintln(os.Stdout, "validating configuration")
+
+		if err := p.Validate(ctx); err != nil {
+			return p.rollback(fmt.Errorf("invalid configuration: %w", err))
+		}
+	}
+
+	if p.snapshot == nil {
+		return nil
+	}
+
+	if len(p.snapshot.entries) == 0 {
+		// nothing changed, keep the snapshot of the previous plan
+		p.snapshot.discard()
+		return nil
+	}
+
+	return p.snapshot.commit(filepath.Join(hubDir, snapshotDir))
+}
+
+func (p *ActionPlan) rollback(cause error) error {
+	if p.snapshot == nil {
+		return cause
+	}
+
+	fmt.Fprintln(os.Stdout, "rolling back")
+
+	if _, err := p.snapshot.restore(); err != nil {
+		return fmt.Errorf("%w. Rollback failed, the snapshot is in %s: %w", cause, p.snapshot.dir, err)
+	}
+
+	p.snapshot.discard()
+
+	// back to the previous state
+	p.ReloadNeeded = false
+
+	return fmt.Errorf("%w. The changes have been rolled back", cause)
+}
+
+// saveFile records the state of a file before a command modifies it.
+func (p *ActionPlan) saveFile(path string) error {
+	if p.snapshot == nil {
+		return nil
+	}
+
+	return p.snapshot.save(path)
 }
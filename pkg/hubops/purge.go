@@ -50,11 +50,15 @@ func (c *PurgeCommand) Prepare(plan *ActionPlan) (bool, error) {
 	return true, nil
 }
 
-func (c *PurgeCommand) Run(_ context.Context, _ *ActionPlan) error {
+func (c *PurgeCommand) Run(_ context.Context, plan *ActionPlan) error {
 	i := c.Item
 
 	fmt.Fprintln(os.Stdout, "purging " + colorizeItemName(i.FQName()))
 
+	if err := plan.saveFile(i.State.DownloadPath); err != nil {
+		return err
+	}
+
 	if err := os.Remove(i.State.DownloadPath); err != nil {
 		if os.IsNotExist(err) {
 			i.State.DownloadPath = ""
@@ -0,0 +1,238 @@
+package hubops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+)
+
+var ErrNoSnapshot = errors.New("no hub operation to roll back")
+
+const (
+	// the snapshot of the last plan that has been applied
+	snapshotDir = ".rollback"
+	// the snapshot of the plan being executed
+	pendingSnapshotDir = ".rollback.new"
+	snapshotIndex      = "snapshot.json"
+)
+
+const (
+	entryAbsent  = "absent"
+	entrySymlink = "symlink"
+	entryFile    = "file"
+)
+
+// snapshotEntry is the state of a file before the plan was executed.
+type snapshotEntry struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Target string      `json:"target,omitempty"`
+	Mode   fs.FileMode `json:"mode,omitempty"`
+	// name of the copy in the snapshot directory
+	Stored string `json:"stored,omitempty"`
+}
+
+// Snapshot keeps a copy of the files (items and install links) modified by an action plan,
+// to restore them if the plan fails, or later with "cscli hub rollback".
+// Data files are not part of the snapshot: they are not versioned, and can be shared by several items.
+type Snapshot struct {
+	dir     string
+	entries []snapshotEntry
+	seen    map[string]struct{}
+}
+
+func newSnapshot(dir string) (*Snapshot, error) {
+	// left by an interrupted plan
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("while removing old snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("while creating snapshot: %w", err)
+	}
+
+	s := &Snapshot{
+		dir:  dir,
+		seen: make(map[string]struct{}),
+	}
+
+	return s, s.writeIndex()
+}
+
+func loadSnapshot(dir string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotIndex))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{dir: dir}
+
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", dir, err)
+	}
+
+	return s, nil
+}
+
+// writeIndex is called after each change, so the snapshot can be used even if cscli is interrupted.
+func (s *Snapshot) writeIndex() error {
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(s.dir, snapshotIndex), data, 0o600)
+}
+
+// save records the current state of a file, before it's modified for the first time.
+func (s *Snapshot) save(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if _, ok := s.seen[path]; ok {
+		return nil
+	}
+
+	entry := snapshotEntry{Path: path}
+
+	info, err := os.Lstat(path)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		entry.Kind = entryAbsent
+	case err != nil:
+		return fmt.Errorf("while saving %s: %w", path, err)
+	case info.Mode()&fs.ModeSymlink != 0:
+		entry.Kind = entrySymlink
+
+		if entry.Target, err = os.Readlink(path); err != nil {
+			return fmt.Errorf("while saving %s: %w", path, err)
+		}
+	case info.Mode().IsRegular():
+		entry.Kind = entryFile
+		entry.Mode = info.Mode().Perm()
+		entry.Stored = strconv.Itoa(len(s.entries))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("while saving %s: %w", path, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(s.dir, entry.Stored), data, 0o600); err != nil {
+			return fmt.Errorf("while saving %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("while saving %s: not a file", path)
+	}
+
+	s.entries = append(s.entries, entry)
+	s.seen[path] = struct{}{}
+
+	return s.writeIndex()
+}
+
+func (s *Snapshot) restoreEntry(entry snapshotEntry) error {
+	if err := os.Remove(entry.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	switch entry.Kind {
+	case entryAbsent:
+		return nil
+	case entrySymlink:
+		if err := os.MkdirAll(filepath.Dir(entry.Path), 0o755); err != nil {
+			return err
+		}
+
+		return os.Symlink(entry.Target, entry.Path)
+	case entryFile:
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Stored))
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(entry.Path), 0o755); err != nil {
+			return err
+		}
+
+		return os.WriteFile(entry.Path, data, entry.Mode)
+	default:
+		return fmt.Errorf("unknown kind '%s'", entry.Kind)
+	}
+}
+
+// restore puts back the files as they were when the snapshot was taken. It returns the
+// list of restored paths.
+func (s *Snapshot) restore() ([]string, error) {
+	var (
+		errs     []error
+		restored []string
+	)
+
+	for _, entry := range slices.Backward(s.entries) {
+		if err := s.restoreEntry(entry); err != nil {
+			errs = append(errs, fmt.Errorf("while restoring %s: %w", entry.Path, err))
+			continue
+		}
+
+		restored = append(restored, entry.Path)
+	}
+
+	return restored, errors.Join(errs...)
+}
+
+// commit replaces the snapshot of the previous plan.
+func (s *Snapshot) commit(dest string) error {
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("while removing old snapshot: %w", err)
+	}
+
+	if err := os.Rename(s.dir, dest); err != nil {
+		return fmt.Errorf("while saving snapshot: %w", err)
+	}
+
+	s.dir = dest
+
+	return nil
+}
+
+func (s *Snapshot) discard() {
+	_ = os.RemoveAll(s.dir)
+}
+
+// Rollback restores the files modified by the last plan that has been applied.
+// The snapshot is removed, so it can be done only once.
+func Rollback(hub *cwhub.Hub) ([]string, error) {
+	if hub.GetHubDir() == "" {
+		// no snapshot has been taken
+		return nil, ErrNoSnapshot
+	}
+
+	dir := filepath.Join(hub.GetHubDir(), snapshotDir)
+
+	s, err := loadSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := s.restore()
+	if err != nil {
+		return restored, err
+	}
+
+	s.discard()
+
+	return restored, nil
+}
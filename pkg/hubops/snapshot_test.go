@@ -0,0 +1,101 @@
+package hubops
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+)
+
+func installed(hub *cwhub.Hub) []string {
+	ret := []string{}
+
+	for _, itemType := range cwhub.ItemTypes {
+		for _, item := range hub.GetInstalledByType(itemType, true) {
+			ret = append(ret, item.FQName())
+		}
+	}
+
+	return ret
+}
+
+func TestRollback(t *testing.T) {
+	dir := t.TempDir()
+	hub := testHub(t, dir)
+
+	_, err := Rollback(hub)
+	require.ErrorIs(t, err, ErrNoSnapshot)
+
+	applyManifest(t, hub, "collections:\n  - name: author/coll\n")
+
+	hub = testHub(t, dir)
+	assert.Equal(t, []string{"parsers:author/pars", "scenarios:author/scen1", "collections:author/coll"}, installed(hub))
+
+	// a plan that fails validation leaves the hub as it was
+	plan := NewActionPlan(hub)
+	scen2 := hub.GetItem(cwhub.SCENARIOS, "author/scen2")
+	require.NoError(t, plan.AddCommand(NewDownloadCommand(scen2, nil, false)))
+	require.NoError(t, plan.AddCommand(NewEnableCommand(scen2, false)))
+
+	plan.Validate = func(_ context.Context) error {
+		return errors.New("bad scenario")
+	}
+
+	err = plan.Execute(t.Context(), false, false, false, false)
+	require.EqualError(t, err, "invalid configuration: bad scenario. The changes have been rolled back")
+	assert.False(t, plan.ReloadNeeded)
+
+	hub = testHub(t, dir)
+	assert.Equal(t, []string{"parsers:author/pars", "scenarios:author/scen1", "collections:author/coll"}, installed(hub))
+	assert.False(t, hub.GetItem(cwhub.SCENARIOS, "author/scen2").State.IsDownloaded())
+
+	// the last successful plan can still be undone
+	restored, err := Rollback(hub)
+	require.NoError(t, err)
+	assert.NotEmpty(t, restored)
+
+	hub = testHub(t, dir)
+	assert.Empty(t, installed(hub))
+
+	// only once
+	_, err = Rollback(hub)
+	require.ErrorIs(t, err, ErrNoSnapshot)
+}
+
+func TestRunWithoutHubDir(t *testing.T) {
+	local := testHubConfig(t, t.TempDir())
+
+	hub, err := cwhub.NewHub(local, nil)
+	require.NoError(t, err)
+	require.NoError(t, hub.Load())
+
+	local.HubDir = ""
+
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+
+	plan := NewActionPlan(hub)
+	scen1 := hub.GetItem(cwhub.SCENARIOS, "author/scen1")
+	require.NoError(t, plan.AddCommand(NewDownloadCommand(scen1, nil, false)))
+	require.NoError(t, plan.AddCommand(NewEnableCommand(scen1, false)))
+
+	plan.Validate = func(_ context.Context) error {
+		return errors.New("bad scenario")
+	}
+
+	// the changes are kept, there is no snapshot to restore
+	err = plan.Execute(t.Context(), false, false, false, false)
+	require.EqualError(t, err, "invalid configuration: bad scenario")
+
+	assert.NoDirExists(t, filepath.Join(cwd, pendingSnapshotDir))
+	assert.NoDirExists(t, filepath.Join(cwd, snapshotDir))
+	assert.True(t, scen1.State.IsInstalled())
+
+	_, err = Rollback(hub)
+	require.ErrorIs(t, err, ErrNoSnapshot)
+}
@@ -0,0 +1,231 @@
+package leakybucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+const (
+	defaultBaselineAlpha  = 0.1
+	defaultBaselineWarmup = 10
+	// after this many empty intervals, the baseline is close enough to zero.
+	// It's also the default retention, in intervals.
+	maxBaselineEmptyIntervals = 100
+)
+
+// baseline is the learned event rate of a groupby key: the moving average and variance
+// of the number of events per interval.
+type baseline struct {
+	mu        sync.Mutex
+	Mean      float64   `json:"mean"`
+	Variance  float64   `json:"variance"`
+	Intervals int       `json:"intervals"` // how many intervals have been learned
+	Start     time.Time `json:"start"`     // start of the current interval
+	Count     int       `json:"count"`     // events in the current interval
+	Anomalous bool      `json:"anomalous"` // the current interval has overflowed, and won't be learned
+	Last      time.Time `json:"last"`      // time of the last event
+}
+
+// fold adds the number of events of an interval to the moving average and variance.
+func (b *baseline) fold(count float64, alpha float64) {
+	if b.Intervals == 0 {
+		b.Mean = count
+		b.Variance = 0
+	} else {
+		diff := count - b.Mean
+		incr := alpha * diff
+		b.Mean += incr
+		b.Variance = (1 - alpha) * (b.Variance + diff*incr)
+	}
+
+	b.Intervals++
+}
+
+// stddev is never below 1, so a perfectly steady rate doesn't overflow on the first extra event.
+func (b *baseline) stddev() float64 {
+	return max(math.Sqrt(b.Variance), 1)
+}
+
+// baselineStore holds the baselines of a scenario. They outlive the buckets, so an
+// overflow doesn't make the bucket forget what it learned.
+type baselineStore struct {
+	mu        sync.Mutex
+	baselines map[string]*baseline
+	lastSweep time.Time
+}
+
+func newBaselineStore() *baselineStore {
+	return &baselineStore{baselines: make(map[string]*baseline)}
+}
+
+// get returns the baseline of a key, creating it if the key is unknown.
+// The baselines that haven't seen events for longer than retention are forgotten.
+func (s *baselineStore) get(key string, now time.Time, retention time.Duration) *baseline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > retention {
+		for k, b := range s.baselines {
+			b.mu.Lock()
+			stale := now.Sub(b.Last) > retention
+			b.mu.Unlock()
+
+			if stale {
+				delete(s.baselines, k)
+			}
+		}
+
+		s.lastSweep = now
+	}
+
+	if b, ok := s.baselines[key]; ok {
+		return b
+	}
+
+	b := &baseline{}
+	s.baselines[key] = b
+
+	return b
+}
+
+// dump serializes the baselines of all the keys, including the ones without a live bucket.
+func (s *baselineStore) dump() (json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := make(map[string]json.RawMessage, len(s.baselines))
+
+	for key, b := range s.baselines {
+		b.mu.Lock()
+		raw, err := json.Marshal(b)
+		b.mu.Unlock()
+
+		if err != nil {
+			return nil, fmt.Errorf("baseline %s: %w", key, err)
+		}
+
+		saved[key] = raw
+	}
+
+	return json.Marshal(saved)
+}
+
+// load adds the baselines saved by dump to the store.
+func (s *baselineStore) load(state json.RawMessage) error {
+	var saved map[string]*baseline
+
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range saved {
+		s.baselines[key] = b
+	}
+
+	return nil
+}
+
+// BaselineProcessor overflows when the number of events in the current interval deviates from
+// the learned rate of the bucket key by more than baseline_deviations standard deviations.
+type BaselineProcessor struct {
+	store      *baselineStore
+	interval   time.Duration
+	alpha      float64
+	deviations float64
+	warmup     int
+	retention  time.Duration
+	baseline   *baseline // set on the first pour, when the key is known
+	DumbProcessor
+}
+
+func (p *BaselineProcessor) OnBucketInit(f *BucketFactory) error {
+	p.store = f.baselines
+	p.interval = f.baselineInterval
+	p.deviations = f.Spec.BaselineDeviations
+
+	p.alpha = f.Spec.BaselineAlpha
+	if p.alpha == 0 {
+		p.alpha = defaultBaselineAlpha
+	}
+
+	p.warmup = f.Spec.BaselineWarmup
+	if p.warmup == 0 {
+		p.warmup = defaultBaselineWarmup
+	}
+
+	p.retention = f.baselineRetention
+	if p.retention == 0 {
+		p.retention = maxBaselineEmptyIntervals * p.interval
+	}
+
+	return nil
+}
+
+func (p *BaselineProcessor) AfterBucketPour(_ *BucketFactory, msg pipeline.Event, l *Leaky) *pipeline.Event {
+	now := l.Last_ts
+
+	if p.baseline == nil {
+		p.baseline = p.store.get(l.Mapkey, now, p.retention)
+	}
+
+	b := p.baseline
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.Last = now
+
+	if b.Start.IsZero() {
+		b.Start = now.Truncate(p.interval)
+	}
+
+	if elapsed := now.Sub(b.Start); elapsed >= p.interval {
+		if !b.Anomalous {
+			b.fold(float64(b.Count), p.alpha)
+		}
+
+		for range min(int(elapsed/p.interval)-1, maxBaselineEmptyIntervals) {
+			b.fold(0, p.alpha)
+		}
+
+		l.logger.Tracef("baseline: %d intervals learned, mean %.2f, stddev %.2f", b.Intervals, b.Mean, b.stddev())
+
+		b.Start = now.Truncate(p.interval)
+		b.Count = 0
+		b.Anomalous = false
+
+		// the overflow only carries the events of the current interval
+		if n := len(l.Queue.Queue); n > 1 {
+			l.Queue.Queue = slices.Delete(l.Queue.Queue, 0, n-1)
+		}
+	}
+
+	b.Count++
+
+	if b.Intervals < p.warmup || b.Anomalous {
+		return &msg
+	}
+
+	limit := b.Mean + p.deviations*b.stddev()
+
+	if float64(b.Count) <= limit {
+		return &msg
+	}
+
+	l.logger.Debugf("Baseline bucket overflow: %d events in the interval, expected at most %.2f", b.Count, limit)
+
+	b.Anomalous = true
+	l.Ovflw_ts = l.Last_ts
+	l.pendingOverflow = l.Queue
+
+	return nil
+}
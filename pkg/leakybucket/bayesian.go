@@ -1,6 +1,7 @@
 package leakybucket
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/expr-lang/expr/vm"
@@ -122,6 +123,35 @@ func (e *BayesianEvent) bayesianUpdate(p *BayesianProcessor, msg pipeline.Event,
 	return nil
 }
 
+// DumpState returns the guillotine state of each condition. The posterior is
+// computed again from the prior on each pour, so it doesn't need to be saved.
+func (p *BayesianProcessor) DumpState() (json.RawMessage, error) {
+	guillotines := make([]bool, len(p.bayesianEventArray))
+	for idx, bevent := range p.bayesianEventArray {
+		guillotines[idx] = bevent.guillotineState
+	}
+
+	return json.Marshal(guillotines)
+}
+
+func (p *BayesianProcessor) LoadState(state json.RawMessage) error {
+	var guillotines []bool
+
+	if err := json.Unmarshal(state, &guillotines); err != nil {
+		return err
+	}
+
+	if len(guillotines) != len(p.bayesianEventArray) {
+		return fmt.Errorf("saved state has %d conditions, bucket has %d", len(guillotines), len(p.bayesianEventArray))
+	}
+
+	for idx, bevent := range p.bayesianEventArray {
+		bevent.guillotineState = guillotines[idx]
+	}
+
+	return nil
+}
+
 func (e *BayesianEvent) getGuillotineState() bool {
 	if e.rawCondition.Guillotine {
 		return e.guillotineState
@@ -2,6 +2,7 @@ package leakybucket
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 	"time"
 
@@ -56,6 +57,9 @@ type Leaky struct {
 	logger              *log.Entry
 	mutex               *sync.Mutex // used only for TIMEMACHINE mode to allow garbage collection without races
 	cancel              context.CancelFunc
+	processors          []Processor       // bucket-specific copy of the factory processors
+	restored            bool              // the bucket has been loaded from a state file
+	restoredProcessors  []json.RawMessage // processor states to apply once they are initialized
 }
 
 // NewLeakyFromFactory creates a new leaky bucket from a BucketFactory
@@ -111,7 +115,7 @@ func NewLeakyFromFactory(f *BucketFactory) *Leaky {
 		l.Duration = f.leakspeed
 	}
 
-	if f.Spec.Type == "bayesian" {
+	if leakspeedIsDuration[f.Spec.Type] {
 		l.Duration = f.leakspeed
 	}
 	return l
@@ -145,6 +149,7 @@ func (l *Leaky) LeakRoutine(ctx context.Context, gate pourGate) {
 	// This can lead to creating buckets that will discard their first events, preventing the underflow ticker from being initialized
 	// and preventing them from being destroyed
 	processors := deepcopy.Copy(l.Factory.processors).([]Processor)
+	l.processors = processors
 
 	l.markReady()
 
@@ -156,6 +161,19 @@ func (l *Leaky) LeakRoutine(ctx context.Context, gate pourGate) {
 		}
 	}
 
+	if l.restored {
+		if err := l.restoreProcessors(); err != nil {
+			l.logger.Warningf("unable to restore processors state: %s", err)
+		}
+
+		l.restoredProcessors = nil
+
+		// the bucket already received events before the restart, resume its timer
+		durationTicker = time.NewTicker(l.remainingDuration(time.Now().UTC()))
+		durationTickerChan = durationTicker.C
+		firstEvent = false
+	}
+
 	l.logger.Debugf("Leaky routine starting, lifetime : %s", l.Duration)
 	for {
 		select {
@@ -233,6 +251,8 @@ func (l *Leaky) LeakRoutine(ctx context.Context, gate pourGate) {
 			l.logger.Tracef("Returning from leaky routine.")
 			return
 		case <-ctx.Done():
+			// don't wait defer to close the channel, the state of the bucket can be dumped now
+			l.markDone()
 			l.logger.Debugf("Bucket externally killed, return")
 			l.AllOut <- pipeline.Event{Type: pipeline.OVFLW, Overflow: pipeline.RuntimeAlert{Mapkey: l.Mapkey}}
 			return
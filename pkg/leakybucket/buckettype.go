@@ -16,6 +16,35 @@ var bucketTypes = map[string]BucketType{
 	"counter":     CounterType{},
 	"conditional": ConditionalType{},
 	"bayesian":    BayesianType{},
+	"sequence":    SequenceType{},
+	"ratio":       RatioType{},
+	"baseline":    BaselineType{},
+}
+
+// the types whose buckets don't leak: they expire after leakspeed without events.
+var leakspeedIsDuration = map[string]bool{
+	"bayesian": true,
+	"sequence": true,
+	"ratio":    true,
+	"baseline": true,
+}
+
+// validateWindowedBucket checks the settings shared by the types that don't leak, and
+// look at the events of the last leakspeed instead.
+func validateWindowedBucket(f *BucketFactory) error {
+	if f.Spec.Capacity != -1 {
+		return fmt.Errorf("invalid capacity '%d': must be -1", f.Spec.Capacity)
+	}
+
+	if f.Spec.LeakSpeed == "" {
+		return errors.New("leakspeed is required")
+	}
+
+	if f.leakspeed <= 0 {
+		return fmt.Errorf("invalid leakspeed '%s': must be > 0", f.Spec.LeakSpeed)
+	}
+
+	return nil
 }
 
 type LeakyType struct{}
@@ -127,3 +156,103 @@ func (BayesianType) Validate(f *BucketFactory) error {
 func (BayesianType) BuildProcessors(_ *BucketFactory) []Processor {
 	return []Processor{&DumbProcessor{}}
 }
+
+type SequenceType struct{}
+
+func (SequenceType) Validate(f *BucketFactory) error {
+	if err := validateWindowedBucket(f); err != nil {
+		return err
+	}
+
+	if len(f.Spec.SequenceSteps) < 2 {
+		return errors.New("at least two steps are required")
+	}
+
+	for idx, step := range f.Spec.SequenceSteps {
+		if step.Filter == "" {
+			return fmt.Errorf("step %d: filter is required", idx+1)
+		}
+
+		if step.Count < 0 {
+			return fmt.Errorf("step %d: invalid count '%d': must be >= 0", idx+1, step.Count)
+		}
+
+		if idx == 0 && step.MaxGap != "" {
+			return errors.New("step 1: max_gap can't be set on the first step")
+		}
+
+		if _, err := parseMaxGap(step); err != nil {
+			return fmt.Errorf("step %d: %w", idx+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (SequenceType) BuildProcessors(_ *BucketFactory) []Processor {
+	return []Processor{&SequenceProcessor{}}
+}
+
+type RatioType struct{}
+
+func (RatioType) Validate(f *BucketFactory) error {
+	if err := validateWindowedBucket(f); err != nil {
+		return err
+	}
+
+	if f.Spec.RatioNumerator == "" {
+		return errors.New("ratio_numerator is required")
+	}
+
+	if f.Spec.RatioThreshold <= 0 {
+		return fmt.Errorf("invalid ratio_threshold '%g': must be > 0", f.Spec.RatioThreshold)
+	}
+
+	if f.Spec.RatioMinSamples < 0 {
+		return fmt.Errorf("invalid ratio_min_samples '%d': must be >= 0", f.Spec.RatioMinSamples)
+	}
+
+	return nil
+}
+
+func (RatioType) BuildProcessors(_ *BucketFactory) []Processor {
+	return []Processor{&RatioProcessor{}}
+}
+
+type BaselineType struct{}
+
+func (BaselineType) Validate(f *BucketFactory) error {
+	if err := validateWindowedBucket(f); err != nil {
+		return err
+	}
+
+	if f.Spec.BaselineInterval == "" {
+		return errors.New("baseline_interval is required")
+	}
+
+	if f.baselineInterval <= 0 {
+		return fmt.Errorf("invalid baseline_interval '%s': must be > 0", f.Spec.BaselineInterval)
+	}
+
+	if f.Spec.BaselineDeviations <= 0 {
+		return fmt.Errorf("invalid baseline_deviations '%g': must be > 0", f.Spec.BaselineDeviations)
+	}
+
+	if f.Spec.BaselineAlpha < 0 || f.Spec.BaselineAlpha > 1 {
+		return fmt.Errorf("invalid baseline_alpha '%g': must be > 0 and <= 1", f.Spec.BaselineAlpha)
+	}
+
+	if f.Spec.BaselineWarmup < 0 {
+		return fmt.Errorf("invalid baseline_warmup '%d': must be >= 0", f.Spec.BaselineWarmup)
+	}
+
+	if f.Spec.BaselineRetention != "" && f.baselineRetention <= 0 {
+		return fmt.Errorf("invalid baseline_retention '%s': must be > 0", f.Spec.BaselineRetention)
+	}
+
+	return nil
+}
+
+func (BaselineType) BuildProcessors(_ *BucketFactory) []Processor {
+	return []Processor{&BaselineProcessor{}}
+}
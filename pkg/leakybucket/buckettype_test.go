@@ -214,6 +214,217 @@ func TestBucketTypes_Validate(t *testing.T) {
 			},
 			wantErr: "capacity must be -1",
 		},
+
+		// --- Sequence ---
+		{
+			name: "sequence/ok",
+			typ:  SequenceType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "10m",
+					SequenceSteps: []RawSequenceStep{{Filter: "true", Count: 5}, {Filter: "true", MaxGap: "30s"}},
+				},
+				leakspeed: 10 * time.Minute,
+			},
+		},
+		{
+			name: "sequence/capacity must be -1",
+			typ:  SequenceType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: 0, LeakSpeed: "10m",
+					SequenceSteps: []RawSequenceStep{{Filter: "true"}, {Filter: "true"}},
+				},
+				leakspeed: 10 * time.Minute,
+			},
+			wantErr: "invalid capacity '0': must be -1",
+		},
+		{
+			name: "sequence/missing leakspeed",
+			typ:  SequenceType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity:      -1,
+					SequenceSteps: []RawSequenceStep{{Filter: "true"}, {Filter: "true"}},
+				},
+			},
+			wantErr: "leakspeed is required",
+		},
+		{
+			name: "sequence/single step",
+			typ:  SequenceType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "10m",
+					SequenceSteps: []RawSequenceStep{{Filter: "true"}},
+				},
+				leakspeed: 10 * time.Minute,
+			},
+			wantErr: "at least two steps are required",
+		},
+		{
+			name: "sequence/missing step filter",
+			typ:  SequenceType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "10m",
+					SequenceSteps: []RawSequenceStep{{Filter: "true"}, {MaxGap: "30s"}},
+				},
+				leakspeed: 10 * time.Minute,
+			},
+			wantErr: "step 2: filter is required",
+		},
+		{
+			name: "sequence/max_gap on the first step",
+			typ:  SequenceType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "10m",
+					SequenceSteps: []RawSequenceStep{{Filter: "true", MaxGap: "30s"}, {Filter: "true"}},
+				},
+				leakspeed: 10 * time.Minute,
+			},
+			wantErr: "step 1: max_gap can't be set on the first step",
+		},
+		{
+			name: "sequence/invalid max_gap",
+			typ:  SequenceType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "10m",
+					SequenceSteps: []RawSequenceStep{{Filter: "true"}, {Filter: "true", MaxGap: "-1s"}},
+				},
+				leakspeed: 10 * time.Minute,
+			},
+			wantErr: "step 2: invalid max_gap '-1s': must be > 0",
+		},
+
+		// --- Ratio ---
+		{
+			name: "ratio/ok",
+			typ:  RatioType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "5m",
+					RatioNumerator: "true", RatioThreshold: 0.5, RatioMinSamples: 20,
+				},
+				leakspeed: 5 * time.Minute,
+			},
+		},
+		{
+			name: "ratio/capacity must be -1",
+			typ:  RatioType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: 10, LeakSpeed: "5m",
+					RatioNumerator: "true", RatioThreshold: 0.5,
+				},
+				leakspeed: 5 * time.Minute,
+			},
+			wantErr: "invalid capacity '10': must be -1",
+		},
+		{
+			name: "ratio/missing numerator",
+			typ:  RatioType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "5m",
+					RatioDenominator: "true", RatioThreshold: 0.5,
+				},
+				leakspeed: 5 * time.Minute,
+			},
+			wantErr: "ratio_numerator is required",
+		},
+		{
+			name: "ratio/missing threshold",
+			typ:  RatioType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "5m",
+					RatioNumerator: "true",
+				},
+				leakspeed: 5 * time.Minute,
+			},
+			wantErr: "invalid ratio_threshold '0': must be > 0",
+		},
+		{
+			name: "ratio/negative min samples",
+			typ:  RatioType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "5m",
+					RatioNumerator: "true", RatioThreshold: 0.5, RatioMinSamples: -1,
+				},
+				leakspeed: 5 * time.Minute,
+			},
+			wantErr: "invalid ratio_min_samples '-1': must be >= 0",
+		},
+
+		// --- Baseline ---
+		{
+			name: "baseline/ok",
+			typ:  BaselineType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "1h",
+					BaselineInterval: "1m", BaselineDeviations: 3,
+				},
+				leakspeed:        time.Hour,
+				baselineInterval: time.Minute,
+			},
+		},
+		{
+			name: "baseline/missing interval",
+			typ:  BaselineType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "1h",
+					BaselineDeviations: 3,
+				},
+				leakspeed: time.Hour,
+			},
+			wantErr: "baseline_interval is required",
+		},
+		{
+			name: "baseline/missing deviations",
+			typ:  BaselineType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "1h",
+					BaselineInterval: "1m",
+				},
+				leakspeed:        time.Hour,
+				baselineInterval: time.Minute,
+			},
+			wantErr: "invalid baseline_deviations '0': must be > 0",
+		},
+		{
+			name: "baseline/invalid alpha",
+			typ:  BaselineType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "1h",
+					BaselineInterval: "1m", BaselineDeviations: 3, BaselineAlpha: 2,
+				},
+				leakspeed:        time.Hour,
+				baselineInterval: time.Minute,
+			},
+			wantErr: "invalid baseline_alpha '2': must be > 0 and <= 1",
+		},
+		{
+			name: "baseline/invalid retention",
+			typ:  BaselineType{},
+			f: BucketFactory{
+				Spec: BucketSpec{
+					Capacity: -1, LeakSpeed: "1h",
+					BaselineInterval: "1m", BaselineDeviations: 3, BaselineRetention: "-1h",
+				},
+				leakspeed:         time.Hour,
+				baselineInterval:  time.Minute,
+				baselineRetention: -time.Hour,
+			},
+			wantErr: "invalid baseline_retention '-1h': must be > 0",
+		},
 	}
 
 	for _, tc := range tests {
@@ -77,3 +77,43 @@ func (p *ConditionalProcessor) AfterBucketPour(f *BucketFactory, msg pipeline.Ev
 
 	return &msg
 }
+
+// compileQueueExpr compiles an expression that can use the queue and the bucket,
+// or returns it from the cache shared with the conditional buckets.
+func compileQueueExpr(ex string) (*vm.Program, error) {
+	conditionalExprCacheLock.Lock()
+	prog, ok := conditionalExprCache[ex]
+	conditionalExprCacheLock.Unlock()
+
+	if ok {
+		return prog, nil
+	}
+
+	// don't hold lock during compile
+	compiled, err := compile(ex, map[string]any{"queue": &pipeline.Queue{}, "leaky": &Leaky{}})
+	if err != nil {
+		return nil, err
+	}
+
+	conditionalExprCacheLock.Lock()
+	defer conditionalExprCacheLock.Unlock()
+
+	conditionalExprCache[ex] = compiled
+
+	return compiled, nil
+}
+
+// runQueueFilter runs an expression compiled by compileQueueExpr against an event.
+func runQueueFilter(prog *vm.Program, msg pipeline.Event, l *Leaky) (bool, error) {
+	ret, err := exprhelpers.Run(prog, map[string]any{"evt": &msg, "queue": l.Queue, "leaky": l}, l.logger, l.Factory.Spec.Debug)
+	if err != nil {
+		return false, err
+	}
+
+	match, ok := ret.(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected non-bool return: %T", ret)
+	}
+
+	return match, nil
+}
@@ -0,0 +1,99 @@
+package leakybucket
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Bounds of distinct_precision. The sketch uses 2^precision bytes, with a standard error of about 1.04/sqrt(2^precision).
+const (
+	minHLLPrecision = 4
+	maxHLLPrecision = 18
+)
+
+// hllSketch is a HyperLogLog estimator of the number of distinct strings it has seen.
+// Its size doesn't depend on the number of values.
+type hllSketch struct {
+	precision uint8
+	registers []uint8
+	alpha     float64
+	// sum of 2^-register, and number of empty registers, kept up to date so the estimate doesn't scan the registers
+	sum   float64
+	zeros int
+}
+
+func newHLLSketch(precision uint8) *hllSketch {
+	m := 1 << precision
+
+	var alpha float64
+
+	switch m {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/float64(m))
+	}
+
+	return &hllSketch{
+		precision: precision,
+		registers: make([]uint8, m),
+		alpha:     alpha,
+		sum:       float64(m),
+		zeros:     m,
+	}
+}
+
+func (s *hllSketch) add(value string) {
+	hash := xxhash.Sum64String(value)
+
+	// the first bits select a register, the position of the first 1 in the others is recorded
+	idx := hash >> (64 - s.precision)
+	rank := uint8(bits.LeadingZeros64(hash<<s.precision|1<<(s.precision-1)) + 1)
+
+	reg := s.registers[idx]
+	if rank <= reg {
+		return
+	}
+
+	if reg == 0 {
+		s.zeros--
+	}
+
+	s.sum += math.Ldexp(1, -int(rank)) - math.Ldexp(1, -int(reg))
+	s.registers[idx] = rank
+}
+
+// load replaces the registers with saved ones, and recomputes the sum and the empty registers.
+func (s *hllSketch) load(registers []uint8) {
+	copy(s.registers, registers)
+
+	s.sum = 0
+	s.zeros = 0
+
+	for _, reg := range s.registers {
+		if reg == 0 {
+			s.zeros++
+		}
+
+		s.sum += math.Ldexp(1, -int(reg))
+	}
+}
+
+func (s *hllSketch) estimate() uint64 {
+	m := float64(len(s.registers))
+
+	est := s.alpha * m * m / s.sum
+
+	// small cardinalities: linear counting is more accurate
+	if est <= 2.5*m && s.zeros > 0 {
+		est = m * math.Log(m/float64(s.zeros))
+	}
+
+	return uint64(est + 0.5)
+}
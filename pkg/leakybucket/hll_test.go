@@ -0,0 +1,80 @@
+package leakybucket
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHLLSketch(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		s := newHLLSketch(12)
+
+		for i := range n {
+			s.add("/uri/" + strconv.Itoa(i))
+		}
+
+		est := s.estimate()
+		assert.InDelta(t, n, est, float64(n)*0.05, "n=%d", n)
+
+		// the values that were already seen don't change the estimate
+		for i := range n {
+			s.add("/uri/" + strconv.Itoa(i))
+		}
+
+		assert.Equal(t, est, s.estimate(), "n=%d", n)
+	}
+
+	assert.Len(t, newHLLSketch(12).registers, 4096)
+}
+
+func TestHLLSketchRunningSum(t *testing.T) {
+	s := newHLLSketch(10)
+
+	for i := range 50000 {
+		s.add("/uri/" + strconv.Itoa(i))
+	}
+
+	// the running values match a scan of the registers
+	sum := 0.0
+	zeros := 0
+
+	for _, reg := range s.registers {
+		sum += math.Ldexp(1, -int(reg))
+
+		if reg == 0 {
+			zeros++
+		}
+	}
+
+	assert.InDelta(t, sum, s.sum, 1e-9)
+	assert.Equal(t, zeros, s.zeros)
+}
+
+func TestUniqSketchState(t *testing.T) {
+	f := &BucketFactory{Spec: BucketSpec{Distinct: "evt.Meta.uri", DistinctPrecision: 12}}
+
+	p := &UniqProcessor{}
+	require.NoError(t, p.OnBucketInit(f))
+
+	for i := range 1000 {
+		p.sketch.add("/uri/" + strconv.Itoa(i))
+	}
+
+	p.passed = p.sketch.estimate()
+
+	state, err := p.DumpState()
+	require.NoError(t, err)
+
+	restored := &UniqProcessor{}
+	require.NoError(t, restored.OnBucketInit(f))
+	require.NoError(t, restored.LoadState(state))
+
+	assert.Equal(t, p.sketch.estimate(), restored.sketch.estimate())
+	assert.Equal(t, p.passed, restored.passed)
+	assert.InDelta(t, p.sketch.sum, restored.sketch.sum, 1e-9)
+	assert.Equal(t, p.sketch.zeros, restored.sketch.zeros)
+}
@@ -37,6 +37,7 @@ type BucketSpec struct {
 	Filter              string                     `yaml:"filter"`              // Filter is an expr that determines if an event is elligible for said bucket. Filter is evaluated against the Event struct
 	GroupBy             string                     `yaml:"groupby,omitempty"`   // groupy is an expr that allows to determine the partitions of the bucket. A common example is the source_ip
 	Distinct            string                     `yaml:"distinct"`            // Distinct, when present, adds a `Pour()` processor that will only pour uniq items (based on distinct expr result)
+	DistinctPrecision   int                        `yaml:"distinct_precision,omitempty"` // DistinctPrecision, when present, counts the distinct items with a sketch of 2^precision bytes instead of storing them
 	Debug               bool                       `yaml:"debug"`               // Debug, when set to true, will enable debugging for _this_ scenario specifically
 	Labels              map[string]any             `yaml:"labels"`              // Labels is K:V list aiming at providing context the overflow
 	Blackhole           string                     `yaml:"blackhole,omitempty"` // Blackhole is a duration that, if present, will prevent same bucket partition to overflow more often than $duration
@@ -49,6 +50,16 @@ type BucketSpec struct {
 	BayesianPrior       float32                    `yaml:"bayesian_prior"`
 	BayesianThreshold   float32                    `yaml:"bayesian_threshold"`
 	BayesianConditions  []RawBayesianCondition     `yaml:"bayesian_conditions"` // conditions for the bayesian bucket
+	SequenceSteps       []RawSequenceStep          `yaml:"steps,omitempty"`     // ordered steps of the sequence bucket
+	RatioNumerator      string                     `yaml:"ratio_numerator,omitempty"`   // the events counted above the line, for the ratio bucket
+	RatioDenominator    string                     `yaml:"ratio_denominator,omitempty"` // the events counted below the line, all of them if empty
+	RatioThreshold      float64                    `yaml:"ratio_threshold,omitempty"`   // the ratio bucket overflows above this value
+	RatioMinSamples     int                        `yaml:"ratio_min_samples,omitempty"` // the ratio is ignored until the denominator has this many events
+	BaselineInterval    string                     `yaml:"baseline_interval,omitempty"`   // the baseline bucket counts the events per interval
+	BaselineAlpha       float64                    `yaml:"baseline_alpha,omitempty"`      // weight of the last interval in the moving average, defaults to 0.1
+	BaselineDeviations  float64                    `yaml:"baseline_deviations,omitempty"` // the baseline bucket overflows this many standard deviations above the average
+	BaselineWarmup      int                        `yaml:"baseline_warmup,omitempty"`     // how many intervals are learned before overflowing, defaults to 10
+	BaselineRetention   string                     `yaml:"baseline_retention,omitempty"`  // how long the baseline of a key is kept without events, defaults to 100 intervals
 	OverflowFilter      string                     `yaml:"overflow_filter"` // OverflowFilter if present, is a filter that must return true for the overflow to go through
 	Duration            string                     `yaml:"duration"`            // Duration allows 'counter' buckets to have a fixed life-time
 	ScenarioVersion     string                     `yaml:"version,omitempty"`
@@ -66,6 +77,9 @@ type BucketFactory struct {
 	DataDir             string
 	leakspeed           time.Duration       // internal representation of `Leakspeed`
 	duration            time.Duration       // internal representation of `Duration`
+	baselineInterval    time.Duration       // internal representation of `BaselineInterval`
+	baselineRetention   time.Duration       // internal representation of `BaselineRetention`
+	baselines           *baselineStore      // the learned rates of a baseline bucket, shared by its instances
 	ret                 chan pipeline.Event // the bucket-specific output chan for overflows
 	processors          []Processor         // processors is the list of hooks for pour/overflow/create (cf. uniq, blackhole etc.)
 	scenarioHash        string
@@ -85,6 +99,16 @@ func (f *BucketFactory) Validate() error {
 		return errors.New("description is mandatory")
 	}
 
+	if f.Spec.DistinctPrecision != 0 {
+		if f.Spec.Distinct == "" {
+			return errors.New("distinct_precision requires distinct")
+		}
+
+		if f.Spec.DistinctPrecision < minHLLPrecision || f.Spec.DistinctPrecision > maxHLLPrecision {
+			return fmt.Errorf("invalid distinct_precision '%d': must be between %d and %d", f.Spec.DistinctPrecision, minHLLPrecision, maxHLLPrecision)
+		}
+	}
+
 	impl, ok := bucketTypes[f.Spec.Type]
 	if !ok {
 		return fmt.Errorf("unknown bucket type '%s'", f.Spec.Type)
@@ -238,6 +262,22 @@ func (f *BucketFactory) parseDurations() error {
 		f.duration = duration
 	}
 
+	if f.Spec.BaselineInterval != "" {
+		interval, err := time.ParseDuration(f.Spec.BaselineInterval)
+		if err != nil {
+			return fmt.Errorf("invalid baseline_interval '%s' in %s: %w", f.Spec.BaselineInterval, f.Filename, err)
+		}
+		f.baselineInterval = interval
+	}
+
+	if f.Spec.BaselineRetention != "" {
+		retention, err := time.ParseDuration(f.Spec.BaselineRetention)
+		if err != nil {
+			return fmt.Errorf("invalid baseline_retention '%s' in %s: %w", f.Spec.BaselineRetention, f.Filename, err)
+		}
+		f.baselineRetention = retention
+	}
+
 	return nil
 }
 
@@ -324,6 +364,24 @@ func (f *BucketFactory) buildOptionalProcessors() ([]Processor, error) {
 		}
 	}
 
+	for idx, step := range f.Spec.SequenceSteps {
+		if err := check(fmt.Sprintf("filter (step %d)", idx+1), step.Filter, map[string]any{"queue": &pipeline.Queue{}, "leaky": &Leaky{}}); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Spec.RatioNumerator != "" {
+		if err := check("ratio_numerator", f.Spec.RatioNumerator, map[string]any{"queue": &pipeline.Queue{}, "leaky": &Leaky{}}); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Spec.RatioDenominator != "" {
+		if err := check("ratio_denominator", f.Spec.RatioDenominator, map[string]any{"queue": &pipeline.Queue{}, "leaky": &Leaky{}}); err != nil {
+			return nil, err
+		}
+	}
+
 	if f.Spec.BayesianThreshold != 0 {
 		f.logger.Tracef("Adding bayesian processor")
 		procs = append(procs, &BayesianProcessor{})
@@ -369,6 +427,11 @@ func (f *BucketFactory) LoadBucket() error {
 		return fmt.Errorf("invalid type '%s' in %s", f.Spec.Type, f.Filename)
 	}
 
+	if f.Spec.Type == "baseline" {
+		// the learned rates are shared by all the instances of the bucket
+		f.baselines = newBaselineStore()
+	}
+
 	procs := impl.BuildProcessors(f)
 
 	optProcs, err := f.buildOptionalProcessors()
@@ -3,6 +3,10 @@ package leakybucket
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type cfgTest struct {
@@ -145,3 +149,72 @@ func TestBayesianBucketsConfig(t *testing.T) {
 		t.Fatalf("%s", err)
 	}
 }
+
+func TestSequenceBucketsConfig(t *testing.T) {
+	steps := []RawSequenceStep{{Filter: "true", Count: 3}, {Filter: "true", MaxGap: "30s"}}
+
+	CfgTests := []cfgTest{
+		// basic valid sequence
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "sequence", Capacity: -1, LeakSpeed: "10m", Filter: "true", SequenceSteps: steps}}, true, true},
+		// bad capacity
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "sequence", Capacity: 1, LeakSpeed: "10m", Filter: "true", SequenceSteps: steps}}, false, false},
+		// missing steps
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "sequence", Capacity: -1, LeakSpeed: "10m", Filter: "true"}}, false, false},
+		// bad step filter
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "sequence", Capacity: -1, LeakSpeed: "10m", Filter: "true", SequenceSteps: []RawSequenceStep{{Filter: "true"}, {Filter: "evt.Foo =="}}}}, false, true},
+	}
+	if err := runTest(CfgTests); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+func TestRatioBucketsConfig(t *testing.T) {
+	CfgTests := []cfgTest{
+		// basic valid ratio
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "ratio", Capacity: -1, LeakSpeed: "5m", Filter: "true", RatioNumerator: "true", RatioThreshold: 0.5}}, true, true},
+		// with a denominator
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "ratio", Capacity: -1, LeakSpeed: "5m", Filter: "true", RatioNumerator: "true", RatioDenominator: "true", RatioThreshold: 0.5, RatioMinSamples: 10}}, true, true},
+		// missing threshold
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "ratio", Capacity: -1, LeakSpeed: "5m", Filter: "true", RatioNumerator: "true"}}, false, false},
+		// bad numerator
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "ratio", Capacity: -1, LeakSpeed: "5m", Filter: "true", RatioNumerator: "evt.Foo ==", RatioThreshold: 0.5}}, false, true},
+	}
+	if err := runTest(CfgTests); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+func TestDistinctPrecisionConfig(t *testing.T) {
+	CfgTests := []cfgTest{
+		// approximate distinct
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "leaky", Capacity: 1, LeakSpeed: "1s", Filter: "true", Distinct: "evt.Meta.uri", DistinctPrecision: 12}}, true, true},
+		// precision without distinct
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "leaky", Capacity: 1, LeakSpeed: "1s", Filter: "true", DistinctPrecision: 12}}, false, false},
+		// precision out of range
+		{BucketFactory{Spec: BucketSpec{Name: "test", Description: "test1", Type: "leaky", Capacity: 1, LeakSpeed: "1s", Filter: "true", Distinct: "evt.Meta.uri", DistinctPrecision: 20}}, false, false},
+	}
+	if err := runTest(CfgTests); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+func TestBaselineRetention(t *testing.T) {
+	load := func(retention string) *BaselineProcessor {
+		f := BucketFactory{Spec: BucketSpec{
+			Name: "test", Description: "test1", Type: "baseline", Capacity: -1, LeakSpeed: "10m", Filter: "true",
+			BaselineInterval: "1m", BaselineDeviations: 3, BaselineRetention: retention,
+		}}
+		require.NoError(t, f.LoadBucket())
+		require.NotNil(t, f.baselines)
+
+		p := &BaselineProcessor{}
+		require.NoError(t, p.OnBucketInit(&f))
+		assert.Same(t, f.baselines, p.store)
+
+		return p
+	}
+
+	// the retention doesn't depend on the leakspeed of the bucket
+	assert.Equal(t, 100*time.Minute, load("").retention)
+	assert.Equal(t, 24*time.Hour, load("24h").retention)
+}
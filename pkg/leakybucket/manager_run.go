@@ -87,6 +87,11 @@ func PourItemToBucket(
 				buckey, sigclosed, failed_sent, attempts)
 		}
 
+		// we are shutting down, don't replace the buckets that were stopped
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
 		/* check if leak routine is up */
 		select {
 		case <-bucket.done:
@@ -181,18 +186,8 @@ func LoadOrStoreBucketFromHolder(
 	fresh_bucket.done = make(chan struct{})
 	actual, stored := buckets.LoadOrStore(partitionKey, fresh_bucket)
 	if !stored {
-		go func() {
-			defer trace.ReportPanic()
-			ctx, cancel := context.WithCancel(ctx)
-			fresh_bucket.cancel = cancel
-			fresh_bucket.LeakRoutine(ctx, buckets)
-			// Always call cancel to avoid leaks
-			// In case of replay, cancel() may be called by the GC func (eg, for an underflow), but cancel is safe to call multiple times
-			cancel()
-		}()
+		startLeakRoutine(ctx, fresh_bucket, buckets)
 		leaky = fresh_bucket
-		// once the created goroutine is ready to process event, we can return it
-		<-fresh_bucket.ready
 	} else {
 		holder.logger.Debugf("Unexpectedly found exisint bucket for %s", partitionKey)
 		leaky = actual
@@ -201,6 +196,21 @@ func LoadOrStoreBucketFromHolder(
 	return leaky, nil
 }
 
+// startLeakRoutine runs the bucket in its own goroutine, and returns once it is ready to process events.
+func startLeakRoutine(ctx context.Context, bucket *Leaky, gate pourGate) {
+	go func() {
+		defer trace.ReportPanic()
+		ctx, cancel := context.WithCancel(ctx)
+		bucket.cancel = cancel
+		bucket.LeakRoutine(ctx, gate)
+		// Always call cancel to avoid leaks
+		// In case of replay, cancel() may be called by the GC func (eg, for an underflow), but cancel is safe to call multiple times
+		cancel()
+	}()
+	// once the created goroutine is ready to process event, we can return it
+	<-bucket.ready
+}
+
 var orderEvent map[string]*sync.WaitGroup
 
 func PourItemToHolders(
@@ -0,0 +1,134 @@
+package leakybucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// RatioProcessor overflows when, among the events of the last leakspeed, the ones matching
+// the numerator are too many compared to the ones matching the denominator.
+type RatioProcessor struct {
+	numerator   *vm.Program
+	denominator *vm.Program // nil: every event of the bucket
+	threshold   float64
+	minSamples  int
+	window      time.Duration
+	// when the events matching each side were poured, oldest first
+	numerators   []time.Time
+	denominators []time.Time
+	DumbProcessor
+}
+
+// ratioState is the serialized content of the window, saved with the bucket state.
+type ratioState struct {
+	Numerators   []time.Time `json:"numerators"`
+	Denominators []time.Time `json:"denominators"`
+}
+
+func (p *RatioProcessor) OnBucketInit(f *BucketFactory) error {
+	var err error
+
+	p.numerator, err = compileQueueExpr(f.Spec.RatioNumerator)
+	if err != nil {
+		return fmt.Errorf("ratio_numerator compile error: %w", err)
+	}
+
+	if f.Spec.RatioDenominator != "" {
+		p.denominator, err = compileQueueExpr(f.Spec.RatioDenominator)
+		if err != nil {
+			return fmt.Errorf("ratio_denominator compile error: %w", err)
+		}
+	}
+
+	p.threshold = f.Spec.RatioThreshold
+	p.minSamples = max(f.Spec.RatioMinSamples, 1)
+	p.window = f.leakspeed
+
+	return nil
+}
+
+// slide removes the timestamps that are out of the window.
+func slide(times []time.Time, oldest time.Time) []time.Time {
+	idx := 0
+	for idx < len(times) && times[idx].Before(oldest) {
+		idx++
+	}
+
+	return times[idx:]
+}
+
+func (p *RatioProcessor) AfterBucketPour(_ *BucketFactory, msg pipeline.Event, l *Leaky) *pipeline.Event {
+	now := l.Last_ts
+
+	inDenominator := true
+
+	if p.denominator != nil {
+		match, err := runQueueFilter(p.denominator, msg, l)
+		if err != nil {
+			l.logger.Errorf("ratio: unable to run the denominator: %s", err)
+			return &msg
+		}
+
+		inDenominator = match
+	}
+
+	inNumerator, err := runQueueFilter(p.numerator, msg, l)
+	if err != nil {
+		l.logger.Errorf("ratio: unable to run the numerator: %s", err)
+		return &msg
+	}
+
+	oldest := now.Add(-p.window)
+	p.numerators = slide(p.numerators, oldest)
+	p.denominators = slide(p.denominators, oldest)
+
+	if inNumerator {
+		p.numerators = append(p.numerators, now)
+	}
+
+	if inDenominator {
+		p.denominators = append(p.denominators, now)
+	}
+
+	if len(p.denominators) < p.minSamples {
+		l.logger.Tracef("ratio: %d/%d samples", len(p.denominators), p.minSamples)
+		return &msg
+	}
+
+	ratio := float64(len(p.numerators)) / float64(len(p.denominators))
+
+	l.logger.Debugf("ratio: %d/%d = %.3f (threshold %.3f)", len(p.numerators), len(p.denominators), ratio, p.threshold)
+
+	if ratio <= p.threshold {
+		return &msg
+	}
+
+	l.logger.Debugf("Ratio bucket overflow")
+	l.Ovflw_ts = l.Last_ts
+	l.pendingOverflow = l.Queue
+
+	return nil
+}
+
+// DumpState returns the events in the window.
+func (p *RatioProcessor) DumpState() (json.RawMessage, error) {
+	return json.Marshal(ratioState{Numerators: p.numerators, Denominators: p.denominators})
+}
+
+func (p *RatioProcessor) LoadState(state json.RawMessage) error {
+	var s ratioState
+
+	if err := json.Unmarshal(state, &s); err != nil {
+		return err
+	}
+
+	p.numerators = s.Numerators
+	p.denominators = s.Denominators
+
+	return nil
+}
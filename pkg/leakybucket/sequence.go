@@ -0,0 +1,156 @@
+package leakybucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// RawSequenceStep is a step of a sequence bucket, as written in the scenario.
+type RawSequenceStep struct {
+	Filter string `yaml:"filter"`            // the events that make the sequence progress
+	Count  int    `yaml:"count,omitempty"`   // how many matching events complete the step, defaults to 1
+	MaxGap string `yaml:"max_gap,omitempty"` // the step must be completed within this delay after the previous one
+}
+
+type sequenceStep struct {
+	filter *vm.Program
+	count  int
+	maxGap time.Duration
+}
+
+// SequenceProcessor overflows when the events of the bucket have matched all the steps, in order.
+// The gaps are checked with the time of the events (l.Last_ts), in live or time machine mode,
+// so a replay behaves like live processing.
+type SequenceProcessor struct {
+	steps []sequenceStep
+	// the step waiting for events
+	current int
+	// how many events have matched the current step
+	matched int
+	// when the previous step was completed
+	last time.Time
+	DumbProcessor
+}
+
+// sequenceState is the serialized progress of a sequence, saved with the bucket state.
+type sequenceState struct {
+	Current int       `json:"current"`
+	Matched int       `json:"matched"`
+	Last    time.Time `json:"last"`
+}
+
+func parseMaxGap(step RawSequenceStep) (time.Duration, error) {
+	if step.MaxGap == "" {
+		return 0, nil
+	}
+
+	maxGap, err := time.ParseDuration(step.MaxGap)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_gap '%s': %w", step.MaxGap, err)
+	}
+
+	if maxGap <= 0 {
+		return 0, fmt.Errorf("invalid max_gap '%s': must be > 0", step.MaxGap)
+	}
+
+	return maxGap, nil
+}
+
+func (p *SequenceProcessor) OnBucketInit(f *BucketFactory) error {
+	p.steps = make([]sequenceStep, len(f.Spec.SequenceSteps))
+
+	for idx, raw := range f.Spec.SequenceSteps {
+		prog, err := compileQueueExpr(raw.Filter)
+		if err != nil {
+			return fmt.Errorf("step %d: filter compile error: %w", idx+1, err)
+		}
+
+		maxGap, err := parseMaxGap(raw)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", idx+1, err)
+		}
+
+		p.steps[idx] = sequenceStep{
+			filter: prog,
+			count:  max(raw.Count, 1),
+			maxGap: maxGap,
+		}
+	}
+
+	return nil
+}
+
+func (p *SequenceProcessor) AfterBucketPour(_ *BucketFactory, msg pipeline.Event, l *Leaky) *pipeline.Event {
+	now := l.Last_ts
+
+	step := &p.steps[p.current]
+
+	if p.current > 0 && step.maxGap > 0 && now.Sub(p.last) > step.maxGap {
+		l.logger.Debugf("sequence: step %d not completed within %s, back to the first step", p.current+1, step.maxGap)
+
+		p.current = 0
+		p.matched = 0
+		step = &p.steps[0]
+	}
+
+	match, err := runQueueFilter(step.filter, msg, l)
+	if err != nil {
+		l.logger.Errorf("sequence: unable to run the filter of step %d: %s", p.current+1, err)
+		return &msg
+	}
+
+	if !match {
+		return &msg
+	}
+
+	p.matched++
+
+	if p.matched < step.count {
+		l.logger.Tracef("sequence: step %d matched %d/%d events", p.current+1, p.matched, step.count)
+		return &msg
+	}
+
+	l.logger.Debugf("sequence: step %d/%d completed", p.current+1, len(p.steps))
+
+	p.current++
+	p.matched = 0
+	p.last = now
+
+	if p.current < len(p.steps) {
+		return &msg
+	}
+
+	l.logger.Debugf("Sequence bucket overflow")
+	l.Ovflw_ts = l.Last_ts
+	l.pendingOverflow = l.Queue
+
+	return nil
+}
+
+// DumpState returns the progress of the sequence.
+func (p *SequenceProcessor) DumpState() (json.RawMessage, error) {
+	return json.Marshal(sequenceState{Current: p.current, Matched: p.matched, Last: p.last})
+}
+
+func (p *SequenceProcessor) LoadState(state json.RawMessage) error {
+	var s sequenceState
+
+	if err := json.Unmarshal(state, &s); err != nil {
+		return err
+	}
+
+	if s.Current < 0 || s.Current >= len(p.steps) {
+		return fmt.Errorf("saved state is at step %d, bucket has %d steps", s.Current+1, len(p.steps))
+	}
+
+	p.current = s.Current
+	p.matched = s.Matched
+	p.last = s.Last
+
+	return nil
+}
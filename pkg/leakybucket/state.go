@@ -0,0 +1,340 @@
+package leakybucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// BucketStateFileName is the name of the file written in state_output_dir on shutdown.
+const BucketStateFileName = "crowdsec-buckets-state.json"
+
+// how long to wait for a bucket routine to stop before dumping what we have
+const bucketStopTimeout = 2 * time.Second
+
+// bucketState is the serialized form of a live bucket.
+type bucketState struct {
+	Name       string            `json:"name"`
+	Mode       int               `json:"mode"`
+	Uuid       string            `json:"uuid"`
+	Limiter    rate.Lstate       `json:"limiter"`
+	Queue      []pipeline.Event  `json:"queue"`
+	FirstTs    time.Time         `json:"first_ts"`
+	LastTs     time.Time         `json:"last_ts"`
+	OvflwTs    time.Time         `json:"ovflw_ts"`
+	TotalCount int               `json:"total_count"`
+	Processors []json.RawMessage `json:"processors,omitempty"`
+}
+
+// stateFile is the content of the state file: the live buckets by key, and the
+// baselines learned by each scenario, which outlive the buckets.
+type stateFile struct {
+	Buckets   map[string]bucketState     `json:"buckets"`
+	Baselines map[string]json.RawMessage `json:"baselines,omitempty"`
+}
+
+// StatefulProcessor is implemented by the processors that hold per-bucket state
+// (uniq cache, bayesian guillotines...) that must survive a restart.
+type StatefulProcessor interface {
+	DumpState() (json.RawMessage, error)
+	LoadState(state json.RawMessage) error
+}
+
+// expiredAt returns true if the bucket routine would have been destroyed by its
+// underflow timer at time t. Counters are never expired: they must overflow.
+func (l *Leaky) expiredAt(t time.Time) bool {
+	if l.timedOverflow || l.Last_ts.IsZero() || l.Duration <= 0 {
+		return false
+	}
+
+	return t.Sub(l.Last_ts) >= l.Duration
+}
+
+// remainingDuration computes the delay before a restored bucket hits its
+// underflow (or timed overflow) deadline.
+func (l *Leaky) remainingDuration(now time.Time) time.Duration {
+	if l.Mode == pipeline.TIMEMACHINE {
+		return l.Duration
+	}
+
+	ref := l.Last_ts
+	if l.timedOverflow {
+		ref = l.First_ts
+	}
+
+	remaining := ref.Add(l.Duration).Sub(now)
+	if remaining <= 0 {
+		// tickers can't be zero, fire as soon as possible
+		remaining = time.Millisecond
+	}
+
+	return remaining
+}
+
+func (l *Leaky) dumpState() (bucketState, error) {
+	l.SerializedState = l.Limiter.Dump()
+
+	state := bucketState{
+		Name:       l.Factory.Spec.Name,
+		Mode:       l.Mode,
+		Uuid:       l.Uuid,
+		Limiter:    l.SerializedState,
+		Queue:      l.Queue.GetQueue(),
+		FirstTs:    l.First_ts,
+		LastTs:     l.Last_ts,
+		OvflwTs:    l.Ovflw_ts,
+		TotalCount: l.Total_count,
+	}
+
+	for _, p := range l.processors {
+		sp, ok := p.(StatefulProcessor)
+		if !ok {
+			state.Processors = append(state.Processors, nil)
+			continue
+		}
+
+		raw, err := sp.DumpState()
+		if err != nil {
+			return state, fmt.Errorf("%T: %w", p, err)
+		}
+
+		state.Processors = append(state.Processors, raw)
+	}
+
+	return state, nil
+}
+
+// restoreProcessors loads the saved processor states into the bucket-specific processors.
+// It must be called after OnBucketInit, which resets them.
+func (l *Leaky) restoreProcessors() error {
+	if len(l.restoredProcessors) == 0 {
+		return nil
+	}
+
+	if len(l.restoredProcessors) != len(l.processors) {
+		return fmt.Errorf("saved state has %d processors, bucket has %d", len(l.restoredProcessors), len(l.processors))
+	}
+
+	for idx, p := range l.processors {
+		raw := l.restoredProcessors[idx]
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+
+		sp, ok := p.(StatefulProcessor)
+		if !ok {
+			return fmt.Errorf("processor %d (%T) can't restore state", idx, p)
+		}
+
+		if err := sp.LoadState(raw); err != nil {
+			return fmt.Errorf("%T: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// DumpBucketsState serializes the live buckets of the store, and the baselines of the
+// scenarios, in outputDir, to be reloaded by LoadBucketsState on the next start.
+// The context the buckets were created with must be canceled before calling it,
+// so that the bucket routines are stopped while their state is read.
+func DumpBucketsState(deadline time.Time, outputDir string, bucketStore *BucketStore, factories []BucketFactory) (string, error) {
+	resume := bucketStore.FreezePours()
+	defer resume()
+
+	serialized := make(map[string]bucketState)
+	baselines := make(map[string]json.RawMessage)
+	discard := 0
+
+	for key, val := range bucketStore.Snapshot() {
+		select {
+		case <-val.done:
+		case <-time.After(bucketStopTimeout):
+			log.Warningf("bucket %s (%s) is still running, state may be inconsistent", key, val.Factory.Spec.Name)
+		}
+
+		if !val.Ovflw_ts.IsZero() {
+			val.logger.Debugf("overflowed at %s, not saving.", val.Ovflw_ts)
+			discard++
+			continue
+		}
+
+		if val.Mode == pipeline.LIVE && val.expiredAt(deadline) {
+			val.logger.Debugf("underflowed at %s, not saving.", deadline)
+			discard++
+			continue
+		}
+
+		state, err := val.dumpState()
+		if err != nil {
+			return "", fmt.Errorf("while serializing bucket %s: %w", key, err)
+		}
+
+		serialized[key] = state
+	}
+
+	for idx := range factories {
+		holder := &factories[idx]
+		if holder.baselines == nil {
+			continue
+		}
+
+		raw, err := holder.baselines.dump()
+		if err != nil {
+			return "", fmt.Errorf("while serializing the baselines of %s: %w", holder.Spec.Name, err)
+		}
+
+		baselines[holder.Spec.Name] = raw
+	}
+
+	body, err := json.Marshal(stateFile{Buckets: serialized, Baselines: baselines})
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize buckets: %w", err)
+	}
+
+	if err = os.MkdirAll(outputDir, 0o700); err != nil {
+		return "", fmt.Errorf("while creating %s: %w", outputDir, err)
+	}
+
+	outFile, err := os.CreateTemp(outputDir, BucketStateFileName+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	// don't leave a partial file behind
+	defer os.Remove(outFile.Name())
+
+	if _, err = outFile.Write(body); err != nil {
+		outFile.Close()
+		return "", fmt.Errorf("failed to write %s: %w", outFile.Name(), err)
+	}
+
+	if err = outFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to close %s: %w", outFile.Name(), err)
+	}
+
+	dest := filepath.Join(outputDir, BucketStateFileName)
+
+	if err = os.Rename(outFile.Name(), dest); err != nil {
+		return "", fmt.Errorf("failed to rename %s: %w", outFile.Name(), err)
+	}
+
+	log.Infof("Serialized %d live buckets (+%d expired) in %s", len(serialized), discard, dest)
+
+	return dest, nil
+}
+
+// LoadBucketsState restores the buckets saved by DumpBucketsState, and starts their routines.
+// Buckets whose scenario is not loaded anymore, or that expired while crowdsec was stopped, are discarded.
+// A missing state file is not an error.
+func LoadBucketsState(ctx context.Context, file string, bucketStore *BucketStore, factories []BucketFactory) error {
+	body, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Infof("No bucket state to restore (%s does not exist)", file)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("can't read bucket state file %s: %w", file, err)
+	}
+
+	var state stateFile
+
+	if err := json.Unmarshal(body, &state); err != nil {
+		return fmt.Errorf("can't parse bucket state file %s: %w", file, err)
+	}
+
+	// the baselines must be there before the buckets receive events
+	for name, saved := range state.Baselines {
+		holder := findFactory(factories, name)
+		if holder == nil || holder.baselines == nil {
+			log.Warningf("scenario %s is not loaded, discarding its saved baselines", name)
+			continue
+		}
+
+		if err := holder.baselines.load(saved); err != nil {
+			return fmt.Errorf("can't restore the baselines of %s: %w", name, err)
+		}
+	}
+
+	now := time.Now().UTC()
+	restored := 0
+
+	for key, saved := range state.Buckets {
+		if _, ok := bucketStore.Load(key); ok {
+			return fmt.Errorf("bucket %s already exists", key)
+		}
+
+		holder := findFactory(factories, saved.Name)
+		if holder == nil {
+			log.Warningf("scenario %s is not loaded, discarding saved bucket %s", saved.Name, key)
+			continue
+		}
+
+		var bucket *Leaky
+
+		switch saved.Mode {
+		case pipeline.TIMEMACHINE:
+			bucket = NewTimeMachine(holder)
+		case pipeline.LIVE:
+			bucket = NewLeakyFromFactory(holder)
+		default:
+			log.Errorf("unknown mode %d for saved bucket %s", saved.Mode, key)
+			continue
+		}
+
+		bucket.Uuid = saved.Uuid
+		bucket.Mapkey = key
+		bucket.Limiter.Load(saved.Limiter)
+		bucket.SerializedState = saved.Limiter
+
+		for _, evt := range saved.Queue {
+			bucket.Queue.Add(evt)
+		}
+
+		bucket.First_ts = saved.FirstTs
+		bucket.Last_ts = saved.LastTs
+		bucket.Ovflw_ts = saved.OvflwTs
+		bucket.Total_count = saved.TotalCount
+		bucket.restoredProcessors = saved.Processors
+		bucket.restored = true
+
+		if bucket.Mode == pipeline.LIVE && bucket.expiredAt(now) {
+			holder.logger.Debugf("saved bucket %s underflowed while we were stopped, discarding", key)
+			continue
+		}
+
+		bucket.In = make(chan *pipeline.Event)
+		bucket.ready = make(chan struct{})
+		bucket.done = make(chan struct{})
+
+		bucketStore.LoadOrStore(key, bucket)
+		startLeakRoutine(ctx, bucket, bucketStore)
+
+		restored++
+	}
+
+	log.Infof("Restored %d buckets (out of %d) from %s", restored, len(state.Buckets), file)
+
+	return nil
+}
+
+func findFactory(factories []BucketFactory, name string) *BucketFactory {
+	for idx := range factories {
+		if factories[idx].Spec.Name == name {
+			return &factories[idx]
+		}
+	}
+
+	return nil
+}
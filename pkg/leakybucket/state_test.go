@@ -0,0 +1,182 @@
+package leakybucket
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func TestDumpAndLoadBucketsState(t *testing.T) {
+	holders := []BucketFactory{
+		{
+			Spec: BucketSpec{
+				Name:        "test_leaky_uniq",
+				Description: "test_leaky_uniq",
+				Type:        "leaky",
+				Capacity:    5,
+				LeakSpeed:   "10m",
+				Filter:      "true",
+				GroupBy:     "evt.Meta.source_ip",
+				Distinct:    "evt.Meta.uri",
+			},
+		},
+		{
+			Spec: BucketSpec{
+				Name:        "test_counter_slow",
+				Description: "test_counter_slow",
+				Type:        "counter",
+				Capacity:    -1,
+				Duration:    "10m",
+				Filter:      "true",
+			},
+		},
+	}
+
+	for idx := range holders {
+		require.NoError(t, holders[idx].LoadBucket())
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	bucketStore := NewBucketStore()
+
+	for _, uri := range []string{"/a", "/b", "/a"} {
+		evt := pipeline.MakeEvent(false, pipeline.LOG, true)
+		evt.Meta["source_ip"] = "1.2.3.4"
+		evt.Meta["uri"] = uri
+
+		ok, err := PourItemToHolders(ctx, evt, holders, bucketStore, nil)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	require.Equal(t, 2, bucketStore.Len())
+
+	cancel()
+
+	dir := t.TempDir()
+
+	dumpFile, err := DumpBucketsState(time.Now().UTC(), dir, bucketStore, holders)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, BucketStateFileName), dumpFile)
+
+	restoredStore := NewBucketStore()
+
+	err = LoadBucketsState(t.Context(), dumpFile, restoredStore, holders)
+	require.NoError(t, err)
+	require.Equal(t, 2, restoredStore.Len())
+
+	leakyKey := holders[0].BucketKey("1.2.3.4")
+
+	restored, ok := restoredStore.Load(leakyKey)
+	require.True(t, ok)
+
+	// the duplicate uri has been discarded before the dump
+	assert.Len(t, restored.Queue.GetQueue(), 2)
+	assert.Equal(t, 2, restored.Total_count)
+	assert.False(t, restored.First_ts.IsZero())
+
+	// the uniq cache has been restored: a known uri is still discarded
+	evt := pipeline.MakeEvent(false, pipeline.LOG, true)
+	evt.Meta["source_ip"] = "1.2.3.4"
+	evt.Meta["uri"] = "/b"
+
+	_, err = PourItemToHolders(t.Context(), evt, holders[:1], restoredStore, nil)
+	require.NoError(t, err)
+
+	time.Sleep(200 * time.Millisecond)
+
+	resume := restoredStore.FreezePours()
+	count := restored.Total_count
+	resume()
+
+	assert.Equal(t, 2, count)
+}
+
+func TestLoadBucketsStateMissingFile(t *testing.T) {
+	err := LoadBucketsState(t.Context(), filepath.Join(t.TempDir(), "nope.json"), NewBucketStore(), nil)
+	require.NoError(t, err)
+}
+
+func TestDumpAndLoadBaselineState(t *testing.T) {
+	newHolders := func() []BucketFactory {
+		holders := []BucketFactory{
+			{
+				Spec: BucketSpec{
+					Name:               "test_baseline",
+					Description:        "test_baseline",
+					Type:               "baseline",
+					Capacity:           -1,
+					LeakSpeed:          "1h",
+					Filter:             "true",
+					GroupBy:            "evt.Meta.source_ip",
+					BaselineInterval:   "1h",
+					BaselineDeviations: 3,
+				},
+			},
+		}
+
+		require.NoError(t, holders[0].LoadBucket())
+
+		return holders
+	}
+
+	holders := newHolders()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	bucketStore := NewBucketStore()
+
+	pour := func(ctx context.Context, holders []BucketFactory, store *BucketStore) {
+		evt := pipeline.MakeEvent(false, pipeline.LOG, true)
+		evt.Meta["source_ip"] = "1.2.3.4"
+
+		ok, err := PourItemToHolders(ctx, evt, holders, store, nil)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	for range 3 {
+		pour(ctx, holders, bucketStore)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	cancel()
+
+	// a key without a live bucket, its baseline must be saved too
+	now := time.Now().UTC()
+	orphanKey := holders[0].BucketKey("5.6.7.8")
+	orphan := holders[0].baselines.get(orphanKey, now, time.Hour)
+	orphan.Mean = 5
+	orphan.Intervals = 12
+	orphan.Last = now
+
+	dumpFile, err := DumpBucketsState(time.Now().UTC(), t.TempDir(), bucketStore, holders)
+	require.NoError(t, err)
+
+	// a new instance of crowdsec starts with an empty baseline store
+	holders = newHolders()
+	restoredStore := NewBucketStore()
+
+	require.NoError(t, LoadBucketsState(t.Context(), dumpFile, restoredStore, holders))
+	require.Equal(t, 1, restoredStore.Len())
+
+	pour(t.Context(), holders, restoredStore)
+
+	time.Sleep(200 * time.Millisecond)
+
+	b := holders[0].baselines.get(holders[0].BucketKey("1.2.3.4"), time.Now().UTC(), time.Hour)
+
+	b.mu.Lock()
+	assert.Equal(t, 4, b.Count)
+	b.mu.Unlock()
+
+	orphan = holders[0].baselines.get(orphanKey, time.Now().UTC(), time.Hour)
+	assert.InDelta(t, 5.0, orphan.Mean, 0)
+	assert.Equal(t, 12, orphan.Intervals)
+}
@@ -1,6 +1,9 @@
 package leakybucket
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/expr-lang/expr"
@@ -24,6 +27,17 @@ type UniqProcessor struct {
 	DistinctCompiled *vm.Program
 	KeyCache         map[string]bool
 	CacheMutex       sync.Mutex
+	// with distinct_precision, the values are counted by a sketch instead of being stored in KeyCache
+	sketch *hllSketch
+	// how many events have been let through by the sketch
+	passed uint64
+}
+
+// uniqSketchState is the serialized state of an approximate uniq processor.
+type uniqSketchState struct {
+	Precision uint8  `json:"precision"`
+	Registers []byte `json:"registers"`
+	Passed    uint64 `json:"passed"`
 }
 
 func (p *UniqProcessor) OnBucketPour(f *BucketFactory, msg pipeline.Event, leaky *Leaky) *pipeline.Event {
@@ -35,6 +49,9 @@ func (p *UniqProcessor) OnBucketPour(f *BucketFactory, msg pipeline.Event, leaky
 	leaky.logger.Tracef("Uniq '%s' -> '%s'", f.Spec.Distinct, element)
 	p.CacheMutex.Lock()
 	defer p.CacheMutex.Unlock()
+	if p.sketch != nil {
+		return p.pourApproximate(element, msg, leaky)
+	}
 	if _, ok := p.KeyCache[element]; !ok {
 		leaky.logger.Debugf("Uniq(%s) : ok", element)
 		p.KeyCache[element] = true
@@ -44,6 +61,21 @@ func (p *UniqProcessor) OnBucketPour(f *BucketFactory, msg pipeline.Event, leaky
 	return nil
 }
 
+// pourApproximate lets an event through when its value makes the estimated number of
+// distinct values go above the number of events already let through. A value that was
+// already seen doesn't change the estimate, so the event is discarded.
+func (p *UniqProcessor) pourApproximate(element string, msg pipeline.Event, leaky *Leaky) *pipeline.Event {
+	p.sketch.add(element)
+
+	if p.sketch.estimate() > p.passed {
+		leaky.logger.Debugf("Uniq(%s) : ok (approximate)", element)
+		p.passed++
+		return &msg
+	}
+	leaky.logger.Debugf("Uniq(%s) : ko (approximate), discard event", element)
+	return nil
+}
+
 func (*UniqProcessor) OnBucketOverflow(_ *BucketFactory, _ *Leaky, alert pipeline.RuntimeAlert, queue *pipeline.Queue) (pipeline.RuntimeAlert, *pipeline.Queue) {
 	return alert, queue
 }
@@ -74,6 +106,69 @@ func (p *UniqProcessor) OnBucketInit(f *BucketFactory) error {
 		uniqExprCacheLock.Unlock()
 	}
 	p.KeyCache = make(map[string]bool)
+	if f.Spec.DistinctPrecision != 0 {
+		p.sketch = newHLLSketch(uint8(f.Spec.DistinctPrecision))
+	}
+	return nil
+}
+
+// DumpState returns the distinct values already seen by the bucket, or its sketch.
+func (p *UniqProcessor) DumpState() (json.RawMessage, error) {
+	p.CacheMutex.Lock()
+	defer p.CacheMutex.Unlock()
+
+	if p.sketch != nil {
+		return json.Marshal(uniqSketchState{Precision: p.sketch.precision, Registers: p.sketch.registers, Passed: p.passed})
+	}
+
+	keys := make([]string, 0, len(p.KeyCache))
+	for k := range p.KeyCache {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return json.Marshal(keys)
+}
+
+func (p *UniqProcessor) LoadState(state json.RawMessage) error {
+	if p.sketch != nil {
+		return p.loadSketch(state)
+	}
+
+	var keys []string
+
+	if err := json.Unmarshal(state, &keys); err != nil {
+		return err
+	}
+
+	p.CacheMutex.Lock()
+	defer p.CacheMutex.Unlock()
+
+	for _, k := range keys {
+		p.KeyCache[k] = true
+	}
+
+	return nil
+}
+
+func (p *UniqProcessor) loadSketch(state json.RawMessage) error {
+	var s uniqSketchState
+
+	if err := json.Unmarshal(state, &s); err != nil {
+		return err
+	}
+
+	if s.Precision != p.sketch.precision || len(s.Registers) != len(p.sketch.registers) {
+		return fmt.Errorf("saved sketch has precision %d, bucket has %d", s.Precision, p.sketch.precision)
+	}
+
+	p.CacheMutex.Lock()
+	defer p.CacheMutex.Unlock()
+
+	p.sketch.load(s.Registers)
+	p.passed = s.Passed
+
 	return nil
 }
 
@@ -0,0 +1,21 @@
+//go:build !no_datasource_fluentforward
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const FluentForwardDataSourceLinesReadMetricName = "cs_fluentforwardsource_hits_total"
+
+var FluentForwardDataSourceLinesRead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: FluentForwardDataSourceLinesReadMetricName,
+		Help: "Total events received by fluentforward source",
+	},
+	[]string{"source", "datasource_type", "acquis_type"})
+
+//nolint:gochecknoinits
+func init() {
+	RegisterAcquisitionMetric(FluentForwardDataSourceLinesReadMetricName)
+}
@@ -0,0 +1,21 @@
+//go:build !no_datasource_jetstream
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const JetStreamDataSourceLinesReadMetricName = "cs_jetstreamsource_hits_total"
+
+var JetStreamDataSourceLinesRead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: JetStreamDataSourceLinesReadMetricName,
+		Help: "Total messages that were read from jetstream source",
+	},
+	[]string{"stream", "datasource_type", "acquis_type"})
+
+//nolint:gochecknoinits
+func init() {
+	RegisterAcquisitionMetric(JetStreamDataSourceLinesReadMetricName)
+}
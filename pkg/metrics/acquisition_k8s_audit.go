@@ -24,6 +24,15 @@ var K8SAuditDataSourceRequestCount = prometheus.NewCounterVec(
 	},
 	[]string{"source"})
 
+const K8SAuditDataSourceRejectedRequestCountMetricName = "cs_k8sauditsource_rejected_requests_total"
+
+var K8SAuditDataSourceRejectedRequestCount = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: K8SAuditDataSourceRejectedRequestCountMetricName,
+		Help: "Total number of requests rejected, by reason",
+	},
+	[]string{"source", "reason"})
+
 //nolint:gochecknoinits
 func init() {
 	RegisterAcquisitionMetric(K8SAuditDataSourceEventCountMetricName)
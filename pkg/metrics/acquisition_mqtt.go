@@ -0,0 +1,21 @@
+//go:build !no_datasource_mqtt
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const MQTTDataSourceLinesReadMetricName = "cs_mqttsource_hits_total"
+
+var MQTTDataSourceLinesRead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: MQTTDataSourceLinesReadMetricName,
+		Help: "Total messages that were read from mqtt source",
+	},
+	[]string{"topic", "datasource_type", "acquis_type"})
+
+//nolint:gochecknoinits
+func init() {
+	RegisterAcquisitionMetric(MQTTDataSourceLinesReadMetricName)
+}
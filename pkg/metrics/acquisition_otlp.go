@@ -0,0 +1,21 @@
+//go:build !no_datasource_otlp
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const OTLPDataSourceLinesReadMetricName = "cs_otlpsource_hits_total"
+
+var OTLPDataSourceLinesRead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: OTLPDataSourceLinesReadMetricName,
+		Help: "Total log records received by otlp source",
+	},
+	[]string{"source", "datasource_type", "acquis_type"})
+
+//nolint:gochecknoinits
+func init() {
+	RegisterAcquisitionMetric(OTLPDataSourceLinesReadMetricName)
+}
@@ -24,6 +24,33 @@ var SyslogDataSourceLinesParsed = prometheus.NewCounterVec(
 	},
 	[]string{"source", "type", "datasource_type", "acquis_type"})
 
+const SyslogDataSourceConnectionsMetricName = "cs_syslogsource_connections_total"
+
+var SyslogDataSourceConnections = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: SyslogDataSourceConnectionsMetricName,
+		Help: "Total tcp connections that were accepted.",
+	},
+	[]string{"source", "protocol", "datasource_type", "acquis_type"})
+
+const SyslogDataSourceActiveConnectionsMetricName = "cs_syslogsource_active_connections"
+
+var SyslogDataSourceActiveConnections = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: SyslogDataSourceActiveConnectionsMetricName,
+		Help: "Number of open tcp connections.",
+	},
+	[]string{"protocol", "datasource_type", "acquis_type"})
+
+const SyslogDataSourceConnectionErrorsMetricName = "cs_syslogsource_connection_errors_total"
+
+var SyslogDataSourceConnectionErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: SyslogDataSourceConnectionErrorsMetricName,
+		Help: "Total tcp connections that were closed because of an error.",
+	},
+	[]string{"source", "reason", "protocol", "datasource_type", "acquis_type"})
+
 //nolint:gochecknoinits
 func init() {
 	RegisterAcquisitionMetric(SyslogDataSourceLinesParsedMetricName)
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const AlertSpoolAlertsMetricName = "cs_alert_spool_alerts"
+
+var AlertSpoolAlerts = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: AlertSpoolAlertsMetricName,
+		Help: "Number of alerts waiting in the spool to be sent to the LAPI.",
+	},
+)
+
+const AlertSpoolBytesMetricName = "cs_alert_spool_bytes"
+
+var AlertSpoolBytes = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: AlertSpoolBytesMetricName,
+		Help: "Size of the alert spool on disk.",
+	},
+)
+
+const AlertSpoolDroppedMetricName = "cs_alert_spool_dropped_total"
+
+var AlertSpoolDropped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: AlertSpoolDroppedMetricName,
+		Help: "Total alerts dropped from the spool without being sent.",
+	},
+	[]string{"reason"},
+)
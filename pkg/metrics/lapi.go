@@ -34,6 +34,17 @@ var LapiBouncerHits = prometheus.NewCounterVec(
 	[]string{"bouncer", "route", "method"},
 )
 
+/*requests rejected by the rate limiter*/
+const LapiThrottledRequestsMetricName = "cs_lapi_throttled_requests_total"
+
+var LapiThrottledRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: LapiThrottledRequestsMetricName,
+		Help: "Number of requests rejected by the rate limiter per route, method and type of client.",
+	},
+	[]string{"route", "method", "client_type"},
+)
+
 /*
 	keep track of the number of calls (per bouncer) that lead to nil/non-nil responses.
 
@@ -70,3 +81,32 @@ var LapiResponseTime = prometheus.NewHistogramVec(
 	},
 	[]string{"endpoint", "method"},
 )
+
+/*usage metrics reported by the bouncers, the counters are sent as deltas since the previous report*/
+const LapiBouncerUsageMetricName = "cs_lapi_bouncer_usage_total"
+
+var LapiBouncerUsage = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: LapiBouncerUsageMetricName,
+		Help: "Usage counters reported by the bouncers (dropped packets, bytes...).",
+	},
+	[]string{"bouncer", "name", "unit", "origin", "remediation", "ip_type"},
+)
+
+const LapiBouncerUsageGaugeMetricName = "cs_lapi_bouncer_usage"
+
+var LapiBouncerUsageGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: LapiBouncerUsageGaugeMetricName,
+		Help: "Latest value of the gauges reported by the bouncers (active decisions...).",
+	},
+	[]string{"bouncer", "name", "unit", "origin", "remediation", "ip_type"},
+)
+
+// DeleteBouncerUsage removes the usage series of a bouncer that has been deleted.
+func DeleteBouncerUsage(bouncerName string) {
+	labels := prometheus.Labels{"bouncer": bouncerName}
+
+	LapiBouncerUsage.DeletePartialMatch(labels)
+	LapiBouncerUsageGauge.DeletePartialMatch(labels)
+}
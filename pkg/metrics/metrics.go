@@ -36,19 +36,22 @@ func RegisterMetrics(metricsLevel MetricsLevelConfig) error {
 		prometheus.MustRegister(GlobalParserHits, GlobalParserHitsOk, GlobalParserHitsKo,
 			GlobalCsInfo, GlobalParsingHistogram, GlobalPourHistogram,
 			BucketsUnderflow, BucketsCanceled, BucketsInstantiation, BucketsOverflow,
-			LapiRouteHits,
+			LapiRouteHits, LapiThrottledRequests,
 			BucketsCurrentCount,
 			CacheMetrics, RegexpCacheMetrics, NodesWlHitsOk, NodesWlHits,
-			PapiOrdersReceived, PapiInvalidOrdersReceived, PapiLastPullTimestamp, PapiPollErrors)
+			PapiOrdersReceived, PapiInvalidOrdersReceived, PapiLastPullTimestamp, PapiPollErrors,
+			AlertSpoolAlerts, AlertSpoolBytes, AlertSpoolDropped)
 	case MetricsLevelFull:
 		prometheus.MustRegister(GlobalParserHits, GlobalParserHitsOk, GlobalParserHitsKo,
 			NodesHits, NodesHitsOk, NodesHitsKo,
 			GlobalCsInfo, GlobalParsingHistogram, GlobalPourHistogram,
-			LapiRouteHits, LapiMachineHits, LapiBouncerHits, LapiNilDecisions, LapiNonNilDecisions, LapiResponseTime,
+			LapiRouteHits, LapiMachineHits, LapiBouncerHits, LapiNilDecisions, LapiNonNilDecisions, LapiResponseTime, LapiThrottledRequests,
+			LapiBouncerUsage, LapiBouncerUsageGauge,
 			BucketsPour, BucketsUnderflow, BucketsCanceled, BucketsInstantiation, BucketsOverflow, BucketsCurrentCount,
 			GlobalActiveDecisions, GlobalAlerts, GlobalMachinesLastHeartbeatTimestamp, NodesWlHitsOk, NodesWlHits,
 			CacheMetrics, RegexpCacheMetrics,
-			PapiOrdersReceived, PapiInvalidOrdersReceived, PapiLastPullTimestamp, PapiPollErrors)
+			PapiOrdersReceived, PapiInvalidOrdersReceived, PapiLastPullTimestamp, PapiPollErrors,
+			AlertSpoolAlerts, AlertSpoolBytes, AlertSpoolDropped)
 	default:
 		return fmt.Errorf("%w: %s", ErrInvalidMetricsLevel, metricsLevel)
 	}
@@ -25,6 +25,7 @@ type Notification struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Alerts        []byte                 `protobuf:"bytes,3,opt,name=alerts,proto3" json:"alerts,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,6 +74,13 @@ func (x *Notification) GetName() string {
 	return ""
 }
 
+func (x *Notification) GetAlerts() []byte {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
 type Config struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Config        []byte                 `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
@@ -157,10 +165,11 @@ var File_notifier_proto protoreflect.FileDescriptor
 
 const file_notifier_proto_rawDesc = "" +
 	"\n" +
-	"\x0enotifier.proto\x12\x05proto\"6\n" +
+	"\x0enotifier.proto\x12\x05proto\"N\n" +
 	"\fNotification\x12\x12\n" +
 	"\x04text\x18\x01 \x01(\tR\x04text\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\" \n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06alerts\x18\x03 \x01(\fR\x06alerts\" \n" +
 	"\x06Config\x12\x16\n" +
 	"\x06config\x18\x02 \x01(\fR\x06config\"\a\n" +
 	"\x05Empty2a\n" +